@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutunak/jcli/internal/alias"
+	"github.com/tutunak/jcli/internal/config"
+)
+
+// expandAliases replaces an alias at the start of args by its definition.
+// Built-in commands take precedence over aliases, which take precedence
+// over plugins.
+func expandAliases(root *cobra.Command, args []string) (alias.Expansion, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") || strings.HasPrefix(args[0], "__") || isBuiltin(root, args[0]) {
+		return alias.Expansion{Args: args}, nil
+	}
+	aliases, err := config.Aliases()
+	if err != nil {
+		return alias.Expansion{}, &config.Error{Err: fmt.Errorf("failed to load aliases: %w", err)}
+	}
+	exp, err := alias.Expand(aliases, args, func(name string) bool { return isBuiltin(root, name) })
+	if err != nil {
+		return alias.Expansion{}, &UsageError{Err: err}
+	}
+	return exp, nil
+}
+
+// runShellAlias runs the command of a "!" alias with sh, passing args as
+// the positional parameters, and returns an *ExitError when it fails.
+func runShellAlias(name, command string, args []string) error {
+	return runExternal("alias "+name, exec.Command("sh", append([]string{"-c", command, name}, args...)...))
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutunak/jcli/internal/api"
+	"github.com/tutunak/jcli/internal/jira"
+	"github.com/tutunak/jcli/internal/jq"
+)
+
+var apiMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+type apiOptions struct {
+	rawFields []string
+	fields    []string
+	input     string
+	paginate  bool
+	filter    string
+}
+
+func newAPICmd() *cobra.Command {
+	var opts apiOptions
+	cmd := &cobra.Command{
+		Use:   "api <method> <path>",
+		Short: "Make an authenticated request to the Jira REST API",
+		Long: `Send a request to any Jira REST endpoint with the authentication, proxy and
+TLS settings of the active profile, and print the response.
+
+A path starting with / is relative to the Jira site, e.g.
+/rest/agile/1.0/board; other paths are relative to the REST API of the
+deployment (/rest/api/3 on Cloud, /rest/api/2 on Server), e.g. "myself".
+
+Fields given with -f are strings; -F converts true, false, null and numbers
+and reads @file, or stdin for @-. A key like fields[summary] sets a nested
+field and labels[] appends to an array. Fields become the JSON body of POST,
+PUT and PATCH requests and query parameters otherwise. --input sends a JSON
+body from a file or stdin ("-"), and then fields go into the query.
+
+--paginate follows both pagination styles of Jira (nextPageToken and isLast,
+or startAt with total) and merges the items of all pages into one response.
+
+--filter applies a jq expression to the response, e.g. '.issues[].key';
+strings are printed without quotes. A response with a non-2xx status is
+printed unfiltered and makes jcli exit with an error.`,
+		Example: `  jcli api GET myself
+  jcli api GET search/jql -f jql='assignee = currentUser()' --paginate --filter '.issues[].key'
+  jcli api POST issue/PROJ-1/comment --input comment.json
+  jcli api PUT issue/PROJ-1 -f fields[summary]='New summary'
+  echo '{"transition":{"id":"31"}}' | jcli api POST issue/PROJ-1/transitions --input -`,
+		Args: exactArgs(2, "method and path required, e.g. 'jcli api GET myself'"),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return apiMethods, cobra.ShellCompDirectiveNoFileComp
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeAPI(args[0], args[1], opts)
+		},
+	}
+	cmd.Flags().StringArrayVarP(&opts.rawFields, "raw-field", "f", nil, "add a string field key=value")
+	cmd.Flags().StringArrayVarP(&opts.fields, "field", "F", nil, "add a typed field key=value (true, false, null, numbers, @file)")
+	cmd.Flags().StringVar(&opts.input, "input", "", `read the JSON request body from a file ("-" for stdin)`)
+	cmd.Flags().BoolVar(&opts.paginate, "paginate", false, "fetch all pages of a GET request")
+	cmd.Flags().StringVar(&opts.filter, "filter", "", "filter the response with a jq expression")
+	return cmd
+}
+
+func executeAPI(method, path string, opts apiOptions) error {
+	method = strings.ToUpper(method)
+	if !slices.Contains(apiMethods, method) {
+		return fmt.Errorf("unsupported method %q (use %s)", method, strings.Join(apiMethods, ", "))
+	}
+	if opts.paginate && method != http.MethodGet {
+		return fmt.Errorf("--paginate only works with GET requests")
+	}
+
+	var filter *jq.Query
+	if opts.filter != "" {
+		var err error
+		if filter, err = jq.Compile(opts.filter); err != nil {
+			return err
+		}
+	}
+
+	var fields []api.Field
+	for _, arg := range opts.rawFields {
+		f, err := api.ParseField(arg, false, os.Stdin)
+		if err != nil {
+			return err
+		}
+		fields = append(fields, f)
+	}
+	for _, arg := range opts.fields {
+		f, err := api.ParseField(arg, true, os.Stdin)
+		if err != nil {
+			return err
+		}
+		fields = append(fields, f)
+	}
+
+	var body []byte
+	switch {
+	case opts.input != "":
+		var err error
+		if opts.input == "-" {
+			body, err = io.ReadAll(os.Stdin)
+		} else {
+			body, err = os.ReadFile(opts.input)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		if !json.Valid(body) {
+			return fmt.Errorf("input is not valid JSON")
+		}
+	case len(fields) > 0 && method != http.MethodGet && method != http.MethodDelete:
+		var err error
+		if body, err = api.Body(fields); err != nil {
+			return err
+		}
+		fields = nil
+	}
+	path, err := api.AddQuery(path, fields)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	client, err := newHTTPClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	var resp *jira.Response
+	if opts.paginate {
+		resp, err = client.Paginate(path)
+	} else {
+		resp, err = client.Do(method, path, body)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !resp.OK() {
+		printResponse(resp.Body)
+		return &jira.APIError{StatusCode: resp.StatusCode, Body: http.StatusText(resp.StatusCode)}
+	}
+	if filter == nil || len(bytes.TrimSpace(resp.Body)) == 0 {
+		printResponse(resp.Body)
+		return nil
+	}
+
+	results, err := filter.RunJSON(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to filter response: %w", err)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	for _, result := range results {
+		if s, ok := result.(string); ok {
+			fmt.Println(s)
+			continue
+		}
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printResponse prints a JSON body indented and other bodies as they are.
+func printResponse(body []byte) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err == nil {
+		fmt.Println(strings.TrimSpace(indented.String()))
+		return
+	}
+	os.Stdout.Write(body)
+	if !bytes.HasSuffix(body, []byte("\n")) {
+		fmt.Println()
+	}
+}
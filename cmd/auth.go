@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutunak/jcli/internal/config"
+	"github.com/tutunak/jcli/internal/jira"
+	"github.com/tutunak/jcli/internal/oauth"
+	"github.com/tutunak/jcli/internal/output"
+)
+
+// loginTimeout bounds how long "auth login" waits for the browser callback.
+const loginTimeout = 5 * time.Minute
+
+func newAuthCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Log in to Jira Cloud with OAuth 2.0 and print tokens",
+		Long: `Log in to Jira Cloud with OAuth 2.0, or print the token of the active
+profile with 'jcli auth token'.
+
+Login needs an OAuth 2.0 (3LO) app from https://developer.atlassian.com/console/myapps/
+with the callback URL http://127.0.0.1:8976/callback:
+
+  jira:
+    url: https://company.atlassian.net
+    oauth:
+      client_id: <client id>
+      client_secret: <secret>
+
+Access tokens are refreshed automatically using the stored refresh token.`,
+		Example: `  jcli auth login
+  jcli --profile client auth login --no-browser`,
+		Args: cobra.ArbitraryArgs,
+		RunE: groupRunE,
+	}
+
+	var noBrowser bool
+	login := &cobra.Command{
+		Use:   "login",
+		Short: "Authorize jcli in the browser and store OAuth tokens",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeAuthLogin(noBrowser)
+		},
+	}
+	login.Flags().BoolVar(&noBrowser, "no-browser", false, "print the authorization URL without opening a browser")
+
+	cmd.AddCommand(
+		login,
+		&cobra.Command{
+			Use:   "status",
+			Short: "Show the OAuth login of the active profile",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return executeAuthStatus()
+			},
+		},
+		&cobra.Command{
+			Use:   "token",
+			Short: "Print the API or OAuth access token of the active profile",
+			Long: `Print the token jcli authenticates with: the API token or Personal Access
+Token, or for OAuth profiles a fresh access token. Scripts and plugins can
+use it instead of reading config.yaml. With basic authentication (Jira Cloud
+API tokens) it is the password for jira.email.`,
+			Args: cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return executeAuthToken()
+			},
+		},
+		&cobra.Command{
+			Use:   "logout",
+			Short: "Remove the stored OAuth tokens",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return executeAuthLogout()
+			},
+		},
+	)
+	return cmd
+}
+
+func executeAuthLogin(noBrowser bool) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.IsServer() {
+		return fmt.Errorf("OAuth login is only supported for Jira Cloud; use a Personal Access Token for Server")
+	}
+	if cfg.Jira.OAuth.ClientID == "" {
+		return fmt.Errorf("jira.oauth.client_id is not configured (see 'jcli auth --help')")
+	}
+
+	open := func(authURL string) error {
+		fmt.Println("Open this URL in your browser to authorize jcli:")
+		fmt.Printf("  %s\n", authURL)
+		if !noBrowser {
+			openBrowser(authURL)
+		}
+		return nil
+	}
+
+	oauthCfg := oauthConfig(cfg)
+	if oauthCfg.Transport, err = newTransport(cfg); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), loginTimeout)
+	defer cancel()
+
+	token, err := oauth.Login(ctx, oauthCfg, cfg.Jira.URL, open)
+	if err != nil {
+		return err
+	}
+	if cfg.Jira.URL == "" {
+		cfg.Jira.URL = token.SiteURL
+	}
+
+	store, err := openOAuthStore()
+	if err != nil {
+		return err
+	}
+	store.Set(cfg.Jira.URL, token)
+	if err := store.Save(); err != nil {
+		return err
+	}
+
+	if _, err := config.Update(func(c *config.Config) error {
+		c.Jira.URL = cfg.Jira.URL
+		c.Jira.Auth = config.AuthOAuth
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	infof("Logged in to %s (cloud ID %s).\n", token.SiteURL, token.CloudID)
+	return nil
+}
+
+func executeAuthToken() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if cfg.Jira.Auth == config.AuthOAuth {
+		rt, err := newTransport(cfg)
+		if err != nil {
+			return err
+		}
+		source, err := newOAuthSource(cfg, rt)
+		if err != nil {
+			return err
+		}
+		token, err := source.Token()
+		if err != nil {
+			return &jira.AuthError{Err: err}
+		}
+		fmt.Println(token)
+		return nil
+	}
+
+	if err := cfg.ResolveAPIToken(); err != nil {
+		return err
+	}
+	if cfg.Jira.APIToken == "" {
+		return fmt.Errorf("no API token configured; run 'jcli config credentials'")
+	}
+	fmt.Println(cfg.Jira.APIToken)
+	return nil
+}
+
+func executeAuthStatus() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, err := openOAuthStore()
+	if err != nil {
+		return err
+	}
+	token, ok := store.Get(cfg.Jira.URL)
+	if printer.Structured() {
+		status := &output.AuthStatus{URL: cfg.Jira.URL, LoggedIn: ok}
+		if ok {
+			status.URL = token.SiteURL
+			status.Active = cfg.Jira.Auth == config.AuthOAuth
+			status.CloudID = token.CloudID
+			if !token.Expiry.IsZero() {
+				status.ExpiresAt = &token.Expiry
+			}
+		}
+		return printer.Print(status)
+	}
+	if !ok {
+		fmt.Printf("Not logged in to %s with OAuth.\n", maskEmpty(cfg.Jira.URL))
+		return nil
+	}
+
+	fmt.Printf("Logged in to %s with OAuth.\n", token.SiteURL)
+	fmt.Printf("  Cloud ID: %s\n", token.CloudID)
+	if !token.Expiry.IsZero() {
+		fmt.Printf("  Access token expires: %s\n", token.Expiry.Local().Format(time.RFC1123))
+	}
+	if cfg.Jira.Auth != config.AuthOAuth {
+		fmt.Println("Note: jira.auth is not \"oauth\", so the API token is used instead.")
+	}
+	return nil
+}
+
+func executeAuthLogout() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	store, err := openOAuthStore()
+	if err != nil {
+		return err
+	}
+	if _, ok := store.Get(cfg.Jira.URL); !ok {
+		fmt.Printf("Not logged in to %s with OAuth.\n", maskEmpty(cfg.Jira.URL))
+		return nil
+	}
+	store.Delete(cfg.Jira.URL)
+	if err := store.Save(); err != nil {
+		return err
+	}
+
+	infof("Removed OAuth tokens for %s.\n", cfg.Jira.URL)
+	return nil
+}
+
+func openOAuthStore() (*oauth.Store, error) {
+	path, err := config.OAuthTokensPath()
+	if err != nil {
+		return nil, err
+	}
+	return oauth.OpenStore(path)
+}
+
+// oauthConfig maps the OAuth settings of the active profile. An unset
+// callback_port reads as 0 and means oauth.DefaultCallbackPort.
+func oauthConfig(cfg *config.Config) oauth.Config {
+	port := cfg.Jira.OAuth.CallbackPort
+	if port == 0 {
+		port = oauth.DefaultCallbackPort
+	}
+	return oauth.Config{
+		ClientID:     cfg.Jira.OAuth.ClientID,
+		ClientSecret: cfg.Jira.OAuth.ClientSecret,
+		AuthURL:      cfg.Jira.OAuth.AuthURL,
+		APIURL:       cfg.Jira.OAuth.APIURL,
+		Scopes:       cfg.Jira.OAuth.Scopes,
+		CallbackPort: port,
+	}
+}
+
+// openBrowser launches $BROWSER or the platform's URL opener. Failures are
+// ignored; the URL has been printed for the user to open manually.
+func openBrowser(url string) {
+	var cmd *exec.Cmd
+	switch {
+	case os.Getenv("BROWSER") != "":
+		cmd = exec.Command(os.Getenv("BROWSER"), url)
+	case runtime.GOOS == "darwin":
+		cmd = exec.Command("open", url)
+	case runtime.GOOS == "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return
+	}
+	go cmd.Wait()
+}
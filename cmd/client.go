@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/tutunak/jcli/internal/config"
+	"github.com/tutunak/jcli/internal/jira"
+	"github.com/tutunak/jcli/internal/oauth"
+	"github.com/tutunak/jcli/internal/transport"
+)
+
+// newJiraClient builds a client for the active profile, fetching the API
+// token from the configured command or credential helper if needed. OAuth
+// profiles use the tokens stored by "jcli auth login".
+func newJiraClient(cfg *config.Config) (jira.Client, error) {
+	client, err := newHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// newHTTPClient is newJiraClient for callers that need the raw requests of
+// *jira.HTTPClient.
+func newHTTPClient(cfg *config.Config) (*jira.HTTPClient, error) {
+	rt, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var client *jira.HTTPClient
+	switch {
+	case cfg.Jira.Auth == config.AuthOAuth:
+		source, err := newOAuthSource(cfg, rt)
+		if err != nil {
+			return nil, err
+		}
+		client = jira.NewOAuthClient(source.BaseURL(), source)
+	default:
+		if err := cfg.ResolveAPIToken(); err != nil {
+			return nil, err
+		}
+		if cfg.IsServer() {
+			client = jira.NewServerClient(cfg.Jira.URL, cfg.Jira.APIToken)
+		} else {
+			client = jira.NewClient(cfg.Jira.URL, cfg.Jira.Email, cfg.Jira.APIToken)
+		}
+	}
+
+	client.SetTransport(rt)
+	return client, nil
+}
+
+// newOAuthSource returns the refreshing token source of an OAuth profile.
+func newOAuthSource(cfg *config.Config, rt http.RoundTripper) (*oauth.Source, error) {
+	path, err := config.OAuthTokensPath()
+	if err != nil {
+		return nil, err
+	}
+	oauthCfg := oauthConfig(cfg)
+	oauthCfg.Transport = rt
+	source, err := oauth.NewSource(oauthCfg, path, cfg.Jira.URL)
+	if errors.Is(err, oauth.ErrNotLoggedIn) {
+		return nil, &jira.AuthError{Err: err}
+	}
+	return source, err
+}
+
+// newTransport applies the proxy and TLS settings of the active profile and
+// the debug trace. Invalid settings are configuration errors.
+func newTransport(cfg *config.Config) (http.RoundTripper, error) {
+	rt, err := transport.New(transport.Options{
+		Proxy:      cfg.Jira.Proxy,
+		CAFiles:    cfg.Jira.TLS.CAFiles,
+		ClientCert: cfg.Jira.TLS.ClientCert,
+		ClientKey:  cfg.Jira.TLS.ClientKey,
+		MinVersion: cfg.Jira.TLS.MinVersion,
+	})
+	if err != nil {
+		return nil, &config.Error{Err: err}
+	}
+	return withTrace(rt), nil
+}
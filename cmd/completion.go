@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutunak/jcli/internal/cache"
+	"github.com/tutunak/jcli/internal/config"
+	"github.com/tutunak/jcli/internal/jira"
+	"github.com/tutunak/jcli/internal/state"
+)
+
+// Completion functions run without applyGlobals, so they apply the config
+// flags themselves. They never fail: without a config or a network they
+// suggest nothing.
+
+// rememberIssues adds search results to the cache used by completion. The
+// cache is best effort; failing to write it doesn't fail the command.
+func rememberIssues(issues []jira.Issue) {
+	entries := make([]cache.Issue, len(issues))
+	for i, issue := range issues {
+		entries[i] = cache.Issue{
+			Key:     issue.Key,
+			Summary: issue.Fields.Summary,
+			Status:  issue.Fields.Status.Name,
+			Type:    issue.Fields.IssueType.Name,
+		}
+	}
+	c := cache.Load()
+	c.AddIssues(entries)
+	_ = c.Save()
+}
+
+// completionConfig loads the configuration for a completion function.
+func completionConfig() (*config.Config, bool) {
+	applyConfigOverrides()
+	cfg, err := loadConfig()
+	return cfg, err == nil
+}
+
+// completeIssueKeys suggests the keys of cached search results and recently
+// selected issues, with their summaries. It doesn't contact Jira.
+func completeIssueKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if _, ok := completionConfig(); !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var issues []cache.Issue
+	if st, err := state.Load(); err == nil {
+		for _, issue := range st.HistoryFor("") {
+			issues = append(issues, cache.Issue{Key: issue.Key, Summary: issue.Summary})
+		}
+	}
+	c := cache.Load()
+	c.AddIssues(issues)
+	return issueCompletions(c.MatchIssues(toComplete)), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRecentIssueKeys suggests the issues "issue switch" accepts: the
+// history and "-".
+func completeRecentIssueKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if _, ok := completionConfig(); !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	st, err := state.Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	c := &cache.Cache{}
+	for _, issue := range st.HistoryFor("") {
+		c.Issues = append(c.Issues, cache.Issue{Key: issue.Key, Summary: issue.Summary})
+	}
+	completions := issueCompletions(c.MatchIssues(toComplete))
+	if toComplete == "" {
+		completions = append([]string{"-\tprevious issue"}, completions...)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func issueCompletions(issues []cache.Issue) []string {
+	completions := make([]string, len(issues))
+	for i, issue := range issues {
+		completions[i] = issue.Key + "\t" + issue.Summary
+	}
+	return completions
+}
+
+// completeStatuses suggests the statuses of the project. They are fetched
+// from Jira once and then cached for cache.StatusTTL.
+func completeStatuses(toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, ok := completionConfig()
+	if !ok || !cfg.HasProject() {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	project := cfg.Defaults.Project
+	now := time.Now()
+	c := cache.Load()
+	names, ok := c.ProjectStatuses(project, now)
+	if !ok {
+		client, err := newJiraClient(cfg)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		statuses, err := client.ProjectStatuses(project)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		names = make([]string, len(statuses))
+		for i, s := range statuses {
+			names[i] = s.Name
+		}
+		c.SetProjectStatuses(project, names, now)
+		_ = c.Save()
+	}
+	return matchPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProfiles suggests the configured profile names.
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	config.SetPathOverride(globals.configPath)
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return matchPrefix(cfg.ProfileNames(), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeQueries suggests the named queries of --query.
+func completeQueries(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, ok := completionConfig()
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for name, jql := range cfg.Queries {
+		if strings.HasPrefix(name, toComplete) {
+			names = append(names, name+"\t"+jql)
+		}
+	}
+	slices.Sort(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// matchPrefix returns the values starting with prefix, ignoring case.
+func matchPrefix(values []string, prefix string) []string {
+	var matches []string
+	for _, v := range values {
+		if strings.HasPrefix(strings.ToLower(v), strings.ToLower(prefix)) {
+			matches = append(matches, v)
+		}
+	}
+	return matches
+}
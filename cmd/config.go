@@ -2,140 +2,584 @@ package cmd
 
 import (
 	"fmt"
+	"maps"
+	"net/url"
 	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
 
 	"github.com/tutunak/jcli/internal/config"
+	"github.com/tutunak/jcli/internal/credentials"
+	"github.com/tutunak/jcli/internal/output"
 	"github.com/tutunak/jcli/internal/tui"
 )
 
-func executeConfig(args []string) error {
-	if len(args) == 0 {
-		printConfigUsage()
-		return nil
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Configure jcli settings",
+		Long: `Read and change the settings of the active profile.
+
+Keys are dotted names such as jira.url or defaults.project; lists are
+comma-separated. Run 'jcli config list --all' for every key.`,
+		Example: `  jcli config set jira.deployment server
+  jcli config set git.project_keys PROJ,OPS
+  jcli config get defaults.project
+  jcli config project MYPROJ
+  jcli config status "To Do"
+  jcli config credentials
+  jcli config show`,
+		Args: cobra.ArbitraryArgs,
+		RunE: groupRunE,
+	}
+
+	var all, server, origin bool
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List settings that are set",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeConfigList(all)
+		},
+	}
+	list.Flags().BoolVar(&all, "all", false, "list every key with its description and type")
+
+	creds := &cobra.Command{
+		Use:   "credentials",
+		Short: "Set Jira credentials interactively",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeConfigCredentials(server)
+		},
+	}
+	creds.Flags().BoolVar(&server, "server", false, "ask for a Jira Server/Data Center Personal Access Token")
+
+	show := &cobra.Command{
+		Use:   "show",
+		Short: "Show the current configuration",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeConfigShow(origin)
+		},
 	}
+	show.Flags().BoolVar(&origin, "origin", false, "show where each value came from")
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "get <key>",
+			Short: "Print a setting, e.g. jira.url or queries.<name>",
+			Args:  exactArgs(1, "usage: jcli config get <key>"),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return executeConfigGet(args[0])
+			},
+		},
+		&cobra.Command{
+			Use:   "set <key> <value>",
+			Short: "Validate and save a setting",
+			Args:  exactArgs(2, "usage: jcli config set <key> <value>"),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return executeConfigSet(args[0], args[1])
+			},
+		},
+		&cobra.Command{
+			Use:   "unset <key>",
+			Short: "Restore the default of a setting",
+			Args:  exactArgs(1, "usage: jcli config unset <key>"),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return executeConfigUnset(args[0])
+			},
+		},
+		list,
+		&cobra.Command{
+			Use:   "project <key>",
+			Short: "Set the default Jira project (alias for set defaults.project)",
+			Args:  exactArgs(1, "project key required"),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return executeConfigProject(args[0])
+			},
+		},
+		&cobra.Command{
+			Use:   "status <name>",
+			Short: "Set the default status filter (alias for set defaults.status)",
+			Args:  exactArgs(1, "status name required"),
+			ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+				if len(args) > 0 {
+					return nil, cobra.ShellCompDirectiveNoFileComp
+				}
+				return completeStatuses(toComplete)
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return executeConfigStatus(args[0])
+			},
+		},
+		creds,
+		show,
+		newConfigProfileCmd(),
+		&cobra.Command{
+			Use:   "encrypt-credentials",
+			Short: "Move plaintext API tokens into the encrypted store",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return executeConfigEncryptCredentials()
+			},
+		},
+	)
+	return cmd
+}
 
-	switch args[0] {
-	case "project":
-		return executeConfigProject(args[1:])
-	case "status":
-		return executeConfigStatus(args[1:])
-	case "credentials":
-		return executeConfigCredentials(args[1:])
-	case "show":
-		return executeConfigShow()
-	case "help", "--help", "-h":
-		printConfigUsage()
+// exactArgs requires n positional arguments and fails with msg otherwise.
+func exactArgs(n int, msg string) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if len(args) != n {
+			return fmt.Errorf("%s", msg)
+		}
 		return nil
-	default:
-		fmt.Fprintf(os.Stderr, "Unknown config command: %s\n", args[0])
-		printConfigUsage()
-		return fmt.Errorf("unknown config command: %s", args[0])
 	}
 }
 
-func printConfigUsage() {
-	fmt.Println(`jcli config - Configure jcli settings
+func executeConfigGet(name string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	key, _, err := config.LookupKey(name)
+	if err != nil {
+		return err
+	}
+	value, err := cfg.Get(name)
+	if err != nil {
+		return err
+	}
 
-Usage:
-  jcli config <command> [value]
+	if printer.Structured() {
+		return printer.Print(&output.Setting{Key: name, Value: displayValue(key, value), Origin: settingOrigin(cfg, name)})
+	}
+	fmt.Println(displayValue(key, value))
+	return nil
+}
 
-Commands:
-  project <key>     Set default Jira project
-  status <name>     Set default status filter (default: "In Progress")
-  credentials       Set Jira credentials interactively
-  show              Show current configuration
+func executeConfigSet(name, value string) error {
+	if err := setConfigValue(name, value); err != nil {
+		return err
+	}
 
-Examples:
-  jcli config project MYPROJ
-  jcli config status "To Do"
-  jcli config credentials
-  jcli config show`)
+	key, _, _ := config.LookupKey(name)
+	infof("Set %s = %s\n", name, displayValue(key, value))
+	return nil
 }
 
-func executeConfigProject(args []string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("project key required")
-	}
+// setConfigValue validates and saves one setting of the active profile.
+func setConfigValue(key, value string) error {
+	_, err := config.Update(func(cfg *config.Config) error {
+		return cfg.Set(key, value)
+	})
+	return err
+}
 
-	cfg, err := config.Load()
+func executeConfigUnset(name string) error {
+	_, err := config.Update(func(cfg *config.Config) error {
+		return cfg.Unset(name)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
-	cfg.Defaults.Project = args[0]
+	infof("Unset %s\n", name)
+	return nil
+}
 
-	if err := cfg.Save(); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
+// executeConfigList prints "key = value" for every set key, or for every
+// key in the schema with --all.
+func executeConfigList(all bool) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	settings, err := configSettings(cfg, all)
+	if err != nil {
+		return err
+	}
+	if printer.Structured() {
+		return printer.Print(settings)
 	}
 
-	fmt.Printf("Default project set to: %s\n", args[0])
+	for _, setting := range settings {
+		if all {
+			fmt.Printf("%s = %s  # %s (%s)\n", setting.Key, setting.Value, setting.Description, setting.Type)
+		} else {
+			fmt.Printf("%s = %s\n", setting.Key, setting.Value)
+		}
+	}
 	return nil
 }
 
-func executeConfigStatus(args []string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("status name required")
+// configSettings returns the keys that are set, or every key of the schema
+// with all. Secrets are masked. With all, a placeholder stands in for a map
+// without entries.
+func configSettings(cfg *config.Config, all bool) (output.SettingList, error) {
+	settings := output.SettingList{}
+	for _, key := range config.Schema() {
+		if key.Type == config.TypeMap {
+			names := cfg.Entries(key)
+			for _, name := range names {
+				full := key.Name + "." + name
+				value, err := cfg.Get(full)
+				if err != nil {
+					return nil, err
+				}
+				settings = append(settings, output.Setting{
+					Key:         full,
+					Value:       value,
+					Origin:      settingOrigin(cfg, full),
+					Type:        config.TypeString,
+					Description: key.Description,
+				})
+			}
+			if all && len(names) == 0 {
+				settings = append(settings, output.Setting{Key: key.Name + ".<name>", Type: describeType(key), Description: key.Description})
+			}
+			continue
+		}
+
+		value, err := cfg.Get(key.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !all && (value == "" || value == "0" || value == "false") {
+			continue
+		}
+		settings = append(settings, output.Setting{
+			Key:         key.Name,
+			Value:       displayValue(key, value),
+			Origin:      settingOrigin(cfg, key.Name),
+			Type:        describeType(key),
+			Description: key.Description,
+		})
 	}
+	return settings, nil
+}
 
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+// settingOrigin reports where a key's value came from. Origins are only
+// recorded for tracked keys and queries.
+func settingOrigin(cfg *config.Config, key string) string {
+	if slices.Contains(config.TrackedKeys, key) || strings.HasPrefix(key, "queries.") {
+		return cfg.Origin(key)
 	}
+	return ""
+}
 
-	cfg.Defaults.Status = args[0]
+// displayValue masks secrets and proxy passwords embedded in URLs.
+func displayValue(key config.Key, value string) string {
+	switch {
+	case value == "":
+		return value
+	case key.Secret:
+		return maskSecret(value)
+	case key.URL:
+		if u, err := url.Parse(value); err == nil {
+			return u.Redacted()
+		}
+	}
+	return value
+}
 
-	if err := cfg.Save(); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
+func describeType(key config.Key) string {
+	switch {
+	case len(key.Enum) > 0:
+		return strings.Join(key.Enum, "|")
+	case key.URL:
+		return "url"
+	default:
+		return key.Type
 	}
+}
 
-	fmt.Printf("Default status filter set to: %s\n", args[0])
+// executeConfigProject is an alias for "config set defaults.project".
+func executeConfigProject(project string) error {
+	if err := setConfigValue("defaults.project", project); err != nil {
+		return err
+	}
+
+	infof("Default project set to: %s\n", project)
+	return nil
+}
+
+// executeConfigStatus is an alias for "config set defaults.status".
+func executeConfigStatus(status string) error {
+	if err := setConfigValue("defaults.status", status); err != nil {
+		return err
+	}
+
+	infof("Default status filter set to: %s\n", status)
 	return nil
 }
 
-func executeConfigCredentials(args []string) error {
-	cfg, err := config.Load()
+func executeConfigCredentials(server bool) error {
+	cfg, err := loadConfig()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
 	selector := tui.NewSelector()
+	if err := promptCredentials(selector, cfg, server); err != nil {
+		return err
+	}
+
+	if err := storeToken(selector, &cfg.Jira); err != nil {
+		return err
+	}
+
+	if _, err := config.Update(func(c *config.Config) error {
+		setCredentials(c, cfg.Jira)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	infof("Credentials saved successfully.\n")
+	reportTokenStore(&cfg.Jira)
+	return nil
+}
+
+// reportTokenStore tells where storeToken put the API token.
+func reportTokenStore(jira *config.JiraConfig) {
+	switch {
+	case jira.CredentialHelper != "":
+		infof("API token was stored with credential helper %q.\n", jira.CredentialHelper)
+	case jira.TokenStore == config.TokenStoreEncrypted:
+		infof("API token was encrypted with your passphrase. Set JCLI_PASSPHRASE for non-interactive use.\n")
+	case jira.APITokenCommand != "":
+		infof("Note: jira.api_token_command is set, so the API token was not saved. If it changed, update it\n")
+		infof("where %q reads it from.\n", jira.APITokenCommand)
+	default:
+		infof("Note: API token is stored in the config file. Set jira.credential_helper or jira.api_token_command\n")
+		infof("to keep it out of the file, or use the JIRA_API_TOKEN environment variable.\n")
+	}
+}
+
+// promptCredentials asks for the URL and the credentials of a Cloud site,
+// or a Server/Data Center instance when server is set, and sets them in cfg.
+func promptCredentials(selector *tui.Selector, cfg *config.Config, server bool) error {
+	if server {
+		url, token, err := selector.PromptServerCredentials()
+		if err != nil {
+			return err
+		}
+		if err := cfg.Set("jira.url", url); err != nil {
+			return err
+		}
+		cfg.Jira.Email = ""
+		cfg.Jira.APIToken = token
+		cfg.Jira.Deployment = config.DeploymentServer
+		return nil
+	}
+
 	url, email, token, err := selector.PromptCredentials()
 	if err != nil {
 		return err
 	}
-
-	cfg.Jira.URL = url
+	if err := cfg.Set("jira.url", url); err != nil {
+		return err
+	}
 	cfg.Jira.Email = email
 	cfg.Jira.APIToken = token
+	cfg.Jira.Deployment = ""
+	return nil
+}
 
-	if err := cfg.Save(); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
+// setCredentials copies the credentials entered with promptCredentials and
+// storeToken to cfg.
+func setCredentials(cfg *config.Config, jira config.JiraConfig) {
+	cfg.Jira.URL = jira.URL
+	cfg.Jira.Deployment = jira.Deployment
+	cfg.Jira.Email = jira.Email
+	cfg.Jira.APIToken = jira.APIToken
+	cfg.Jira.TokenStore = jira.TokenStore
+}
+
+// storeToken moves jira.APIToken to the credential helper or the encrypted
+// store when the profile uses one, asking whether to encrypt it when no
+// token source is configured. With api_token_command the token is dropped:
+// the command is its source.
+func storeToken(selector *tui.Selector, jira *config.JiraConfig) error {
+	if jira.CredentialHelper != "" {
+		if err := credentials.NewHelper(jira.CredentialHelper).Store(jira.URL, jira.Email, jira.APIToken); err != nil {
+			return err
+		}
+		jira.APIToken = ""
+		return nil
+	}
+	if jira.APITokenCommand != "" {
+		jira.APIToken = ""
+		return nil
+	}
+
+	if jira.TokenStore != config.TokenStoreEncrypted {
+		encrypt, err := selector.PromptTokenStorage()
+		if err != nil || !encrypt {
+			return err
+		}
+	}
+
+	store, err := config.OpenCredentialStore(true)
+	if err != nil {
+		return err
+	}
+	store.Set(jira.URL, jira.Email, jira.APIToken)
+	if err := store.Save(); err != nil {
+		return err
 	}
 
-	fmt.Println("Credentials saved successfully.")
-	fmt.Println("Note: API token is stored in the config file. You can also use JIRA_API_TOKEN environment variable.")
+	jira.APIToken = ""
+	jira.TokenStore = config.TokenStoreEncrypted
 	return nil
 }
 
-func executeConfigShow() error {
-	cfg, err := config.Load()
+// executeConfigEncryptCredentials moves plaintext API tokens of all profiles
+// into the encrypted token store.
+func executeConfigEncryptCredentials() error {
+	if os.Getenv("JIRA_API_TOKEN") != "" {
+		return fmt.Errorf("unset JIRA_API_TOKEN first so the stored tokens are migrated")
+	}
+
+	if _, err := loadConfig(); err != nil {
+		return err
+	}
+
+	store, err := config.OpenCredentialStore(true)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
+	}
+
+	var migrated []string
+	_, err = config.Update(func(cfg *config.Config) error {
+		migrated = cfg.EncryptTokens(store)
+		if len(migrated) == 0 {
+			return nil
+		}
+		// Write the encrypted store first so a failure never loses a token.
+		return store.Save()
+	})
+	if err != nil {
+		return err
+	}
+	if len(migrated) == 0 {
+		fmt.Println("No plaintext API tokens found.")
+		return nil
+	}
+
+	infof("Encrypted API tokens of profiles: %s\n", strings.Join(migrated, ", "))
+	return nil
+}
+
+func executeConfigShow(withOrigin bool) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
 	}
 
 	configPath, _ := config.ConfigPath()
+	var localFiles []string
+	if wd, err := os.Getwd(); err == nil {
+		localFiles = config.LocalConfigPaths(wd)
+	}
+
+	if printer.Structured() {
+		settings, err := configSettings(cfg, true)
+		if err != nil {
+			return err
+		}
+		return printer.Print(&output.Config{
+			ConfigFile: configPath,
+			Profile:    cfg.ActiveProfile(),
+			LocalFiles: append([]string{}, localFiles...),
+			Settings:   settings,
+		})
+	}
+
+	// show prints one setting, followed by its origin when requested.
+	show := func(label, key, value string) {
+		if withOrigin {
+			fmt.Printf("  %s: %s  [%s]\n", label, value, cfg.Origin(key))
+			return
+		}
+		fmt.Printf("  %s: %s\n", label, value)
+	}
 
 	fmt.Println("Configuration:")
 	fmt.Printf("  Config file: %s\n", configPath)
+	fmt.Printf("  Profile: %s\n", cfg.ActiveProfile())
+	for _, path := range localFiles {
+		fmt.Printf("  Local file: %s\n", path)
+	}
 	fmt.Println()
 	fmt.Println("Jira:")
-	fmt.Printf("  URL: %s\n", maskEmpty(cfg.Jira.URL))
-	fmt.Printf("  Email: %s\n", maskEmpty(cfg.Jira.Email))
-	fmt.Printf("  API Token: %s\n", maskSecret(cfg.Jira.APIToken))
+	show("URL", "jira.url", maskEmpty(cfg.Jira.URL))
+	if cfg.Jira.Deployment != "" {
+		show("Deployment", "jira.deployment", cfg.Jira.Deployment)
+	}
+	if cfg.Jira.Auth != "" {
+		show("Auth", "jira.auth", cfg.Jira.Auth)
+	}
+	if cfg.Jira.OAuth.ClientID != "" {
+		show("OAuth client ID", "jira.oauth.client_id", cfg.Jira.OAuth.ClientID)
+	}
+	if !cfg.IsServer() && cfg.Jira.Auth != config.AuthOAuth {
+		show("Email", "jira.email", maskEmpty(cfg.Jira.Email))
+	}
+	show("API Token", "jira.api_token", maskSecret(cfg.Jira.APIToken))
+	if cfg.Jira.CredentialHelper != "" {
+		show("Credential helper", "jira.credential_helper", cfg.Jira.CredentialHelper)
+	}
+	if cfg.Jira.APITokenCommand != "" {
+		show("API token command", "jira.api_token_command", cfg.Jira.APITokenCommand)
+	}
+	if cfg.Jira.TokenStore != "" {
+		show("Token store", "jira.token_store", cfg.Jira.TokenStore)
+	}
+	if cfg.Jira.Proxy != "" {
+		key, _, _ := config.LookupKey("jira.proxy")
+		show("Proxy", "jira.proxy", displayValue(key, cfg.Jira.Proxy))
+	}
+	if len(cfg.Jira.TLS.CAFiles) > 0 {
+		show("CA files", "jira.tls.ca_files", strings.Join(cfg.Jira.TLS.CAFiles, ", "))
+	}
+	if cfg.Jira.TLS.ClientCert != "" {
+		show("Client certificate", "jira.tls.client_cert", cfg.Jira.TLS.ClientCert)
+	}
+	if cfg.Jira.TLS.MinVersion != "" {
+		show("TLS min version", "jira.tls.min_version", cfg.Jira.TLS.MinVersion)
+	}
 	fmt.Println()
 	fmt.Println("Defaults:")
-	fmt.Printf("  Project: %s\n", maskEmpty(cfg.Defaults.Project))
-	fmt.Printf("  Status: %s\n", cfg.Defaults.Status)
+	show("Project", "defaults.project", maskEmpty(cfg.Defaults.Project))
+	show("Status", "defaults.status", cfg.Defaults.Status)
+	fmt.Println()
+	fmt.Println("Git:")
+	show("Detect issue", "git.detect_issue", fmt.Sprint(cfg.Git.DetectIssue))
+	show("Issue pattern", "git.issue_pattern", maskEmpty(cfg.Git.IssuePattern))
+	show("Project keys", "git.project_keys", maskEmpty(strings.Join(cfg.Git.ProjectKeys, ", ")))
+	show("Branch template", "git.branch_template", maskEmpty(cfg.Git.BranchTemplate))
+
+	if len(cfg.Queries) > 0 {
+		fmt.Println()
+		fmt.Println("Queries:")
+		names := slices.Sorted(maps.Keys(cfg.Queries))
+		for _, name := range names {
+			show(name, "queries."+name, cfg.Queries[name])
+		}
+	}
+	if len(cfg.Aliases) > 0 {
+		fmt.Println()
+		fmt.Println("Aliases:")
+		names := slices.Sorted(maps.Keys(cfg.Aliases))
+		for _, name := range names {
+			show(name, "aliases."+name, cfg.Aliases[name])
+		}
+	}
 
 	return nil
 }
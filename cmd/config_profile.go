@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutunak/jcli/internal/config"
+	"github.com/tutunak/jcli/internal/output"
+	"github.com/tutunak/jcli/internal/tui"
+)
+
+func newConfigProfileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage profiles for multiple Jira sites",
+		Long: `Manage profiles for multiple Jira sites.
+
+The active profile is chosen by --profile, then JCLI_PROFILE, then
+'jcli config profile use'. Each profile keeps its own current issue.`,
+		Example: `  jcli config profile add client
+  jcli --profile client config project CLI
+  jcli config profile use client
+  JCLI_PROFILE=default jcli issue current`,
+		Args: cobra.ArbitraryArgs,
+		RunE: groupRunE,
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "add <name>",
+			Short: "Add a profile and set its credentials interactively",
+			Args:  exactArgs(1, "profile name required"),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return executeConfigProfileAdd(args[0])
+			},
+		},
+		&cobra.Command{
+			Use:   "list",
+			Short: "List profiles (* marks the active one)",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return executeConfigProfileList()
+			},
+		},
+		&cobra.Command{
+			Use:   "use <name>",
+			Short: "Make a profile the default for future commands",
+			Args:  exactArgs(1, "profile name required"),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return executeConfigProfileUse(args[0])
+			},
+		},
+		&cobra.Command{
+			Use:   "remove <name>",
+			Short: "Remove a profile",
+			Args:  exactArgs(1, "profile name required"),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return executeConfigProfileRemove(args[0])
+			},
+		},
+	)
+	return cmd
+}
+
+func executeConfigProfileAdd(name string) error {
+	if err := config.ValidateProfileName(name); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.HasProfile(name) {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+
+	selector := tui.NewSelector()
+	url, email, token, err := selector.PromptCredentials()
+	if err != nil {
+		return err
+	}
+
+	profile := config.Profile{
+		Jira: config.JiraConfig{URL: url, Email: email, APIToken: token},
+	}
+	if err := storeToken(selector, &profile.Jira); err != nil {
+		return err
+	}
+	if _, err := config.Update(func(cfg *config.Config) error {
+		return cfg.AddProfile(name, profile)
+	}); err != nil {
+		return err
+	}
+
+	infof("Profile %s added.\n", name)
+	infof("Set its default project with 'jcli --profile %s config project <KEY>'.\n", name)
+	return nil
+}
+
+func executeConfigProfileList() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if printer.Structured() {
+		profiles := output.ProfileList{}
+		for _, name := range cfg.ProfileNames() {
+			profile, _ := cfg.ProfileSettings(name)
+			profiles = append(profiles, output.Profile{Name: name, URL: profile.Jira.URL, Active: name == cfg.ActiveProfile()})
+		}
+		return printer.Print(profiles)
+	}
+
+	for _, name := range cfg.ProfileNames() {
+		marker := " "
+		if name == cfg.ActiveProfile() {
+			marker = "*"
+		}
+		profile, _ := cfg.ProfileSettings(name)
+		fmt.Printf("%s %s\t%s\n", marker, name, maskEmpty(profile.Jira.URL))
+	}
+	return nil
+}
+
+func executeConfigProfileUse(name string) error {
+	if _, err := config.Update(func(cfg *config.Config) error {
+		return cfg.UseProfile(name)
+	}); err != nil {
+		return err
+	}
+
+	infof("Now using profile: %s\n", name)
+	return nil
+}
+
+func executeConfigProfileRemove(name string) error {
+	if _, err := config.Update(func(cfg *config.Config) error {
+		return cfg.RemoveProfile(name)
+	}); err != nil {
+		return err
+	}
+
+	infof("Profile %s removed.\n", name)
+	return nil
+}
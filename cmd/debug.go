@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/tutunak/jcli/internal/trace"
+)
+
+// debugOptions are the values of --debug, --debug-body and --debug-file.
+type debugOptions struct {
+	enabled bool
+	bodies  bool
+	file    string
+}
+
+// debugOut receives the HTTP trace when debugging is enabled.
+var (
+	debugOut    io.Writer
+	debugBodies bool
+	debugLog    *os.File
+)
+
+// setupDebug enables HTTP tracing from the debug flags, or from JCLI_DEBUG
+// ("1", "true" or "body") and JCLI_DEBUG_FILE.
+func setupDebug(opts debugOptions) error {
+	switch env := strings.ToLower(os.Getenv("JCLI_DEBUG")); env {
+	case "", "0", "false":
+	case "body":
+		opts.bodies = true
+	default:
+		opts.enabled = true
+	}
+	if opts.file == "" {
+		opts.file = os.Getenv("JCLI_DEBUG_FILE")
+	}
+
+	if !opts.enabled && !opts.bodies && opts.file == "" {
+		return nil
+	}
+
+	debugBodies = opts.bodies
+	debugOut = os.Stderr
+	if opts.file == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(opts.file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open debug log: %w", err)
+	}
+	debugOut = f
+	debugLog = f
+	return nil
+}
+
+// closeDebug closes the debug log file, if any.
+func closeDebug() {
+	if debugLog != nil {
+		debugLog.Close()
+		debugLog = nil
+	}
+	debugOut = nil
+}
+
+// debugf writes a note to the debug output when debugging is enabled.
+func debugf(format string, a ...any) {
+	if debugOut != nil {
+		fmt.Fprintf(debugOut, "debug: "+format+"\n", a...)
+	}
+}
+
+// withTrace wraps rt in the HTTP trace when debugging is enabled.
+func withTrace(rt http.RoundTripper) http.RoundTripper {
+	if debugOut == nil {
+		return rt
+	}
+	return trace.New(rt, debugOut, debugBodies)
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutunak/jcli/internal/config"
+	"github.com/tutunak/jcli/internal/doctor"
+	"github.com/tutunak/jcli/internal/jira"
+	"github.com/tutunak/jcli/internal/output"
+	"github.com/tutunak/jcli/internal/state"
+)
+
+func newDoctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose configuration and connectivity problems",
+		Long: `Diagnose configuration and connectivity problems.
+
+Checks the configuration, the Jira URL and its TLS certificate, the
+credentials (by fetching the current user), the default project and status,
+and that the config and state directories are writable. Each failed check
+prints a suggested fix. The command exits non-zero if any check fails.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeDoctor()
+		},
+	}
+}
+
+func executeDoctor() error {
+	configPath, _ := config.ConfigPath()
+	configDir, _ := config.ConfigDir()
+	stateDir, _ := state.StateDir()
+
+	// A config that doesn't load is reported as a failed check rather than
+	// aborting: the error hints send users here to diagnose it.
+	cfg, err := loadConfig()
+	d := &doctor.Doctor{
+		Config:     cfg,
+		LoadErr:    err,
+		ConfigPath: configPath,
+		ConfigDir:  configDir,
+		StateDir:   stateDir,
+	}
+	var profile, url string
+	if cfg != nil {
+		d.NewClient = func() (jira.Client, error) { return newJiraClient(cfg) }
+		d.NewTransport = func() (http.RoundTripper, error) { return newTransport(cfg) }
+		profile, url = cfg.ActiveProfile(), cfg.Jira.URL
+	}
+
+	if !printer.Structured() {
+		if cfg != nil {
+			fmt.Printf("Checking profile %q (%s)\n\n", profile, maskEmpty(url))
+		} else {
+			fmt.Printf("Checking %s\n\n", configPath)
+		}
+	}
+
+	results := d.Run()
+	failed := 0
+	for _, result := range results {
+		if result.Status == doctor.Fail {
+			failed++
+		}
+	}
+
+	if printer.Structured() {
+		diagnosis := &output.Diagnosis{Profile: profile, URL: url, Checks: []output.Check{}, Failed: failed}
+		for _, result := range results {
+			diagnosis.Checks = append(diagnosis.Checks, output.Check{
+				Name:   result.Name,
+				Status: result.Status.String(),
+				Detail: result.Detail,
+				Fix:    result.Fix,
+			})
+		}
+		if err := printer.Print(diagnosis); err != nil {
+			return err
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d check(s) failed", failed)
+		}
+		return nil
+	}
+
+	for _, result := range results {
+		fmt.Printf("[%s] %s: %s\n", result.Status, result.Name, result.Detail)
+		if result.Fix != "" && (result.Status == doctor.Fail || result.Status == doctor.Warn) {
+			fmt.Printf("       fix: %s\n", result.Fix)
+		}
+	}
+
+	fmt.Println()
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	infof("All checks passed.\n")
+	return nil
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/tutunak/jcli/internal/config"
+	"github.com/tutunak/jcli/internal/credentials"
+	"github.com/tutunak/jcli/internal/jira"
+	"github.com/tutunak/jcli/internal/state"
+	"github.com/tutunak/jcli/internal/tui"
+)
+
+// Exit codes of jcli. Scripts rely on them, so they never change meaning.
+const (
+	ExitOK        = 0
+	ExitFailure   = 1 // any other error
+	ExitUsage     = 2 // unknown command or flag, wrong arguments
+	ExitConfig    = 3 // invalid or incomplete configuration
+	ExitAuth      = 4 // missing or rejected credentials
+	ExitNotFound  = 5 // issue, project or selection not found
+	ExitNetwork   = 6 // Jira unreachable, timeout or unavailable
+	ExitConflict  = 7 // the change conflicts with the current state
+	ExitCancelled = 130
+)
+
+// ExitError makes the caller of Execute exit with Code without printing an
+// error, because the failure was already reported, e.g. by a plugin.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// UsageError is a command line that can't run: an unknown command or flag,
+// or wrong arguments.
+type UsageError struct {
+	Err error
+	// Command is the path of the command whose help explains the usage.
+	Command string
+}
+
+func (e *UsageError) Error() string { return e.Err.Error() }
+func (e *UsageError) Unwrap() error { return e.Err }
+
+// ExitCode classifies err into the exit codes of jcli.
+func ExitCode(err error) int {
+	var (
+		exitErr  *ExitError
+		usageErr *UsageError
+		cfgErr   *config.Error
+		credErr  *config.CredentialsError
+		authErr  *jira.AuthError
+		apiErr   *jira.APIError
+		notFound *jira.NotFoundError
+	)
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &exitErr):
+		return exitErr.Code
+	case errors.As(err, &usageErr):
+		return ExitUsage
+	case errors.Is(err, tui.ErrCancelled):
+		return ExitCancelled
+	case errors.As(err, &apiErr):
+		switch apiErr.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return ExitAuth
+		case http.StatusNotFound:
+			return ExitNotFound
+		case http.StatusConflict:
+			return ExitConflict
+		case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return ExitNetwork
+		}
+		return ExitFailure
+	// Checked before authentication, whose token refresh may fail offline.
+	case isNetworkError(err):
+		return ExitNetwork
+	case errors.As(err, &authErr), errors.As(err, &credErr), errors.Is(err, credentials.ErrWrongPassphrase):
+		return ExitAuth
+	case errors.As(err, &cfgErr):
+		return ExitConfig
+	case errors.As(err, &notFound), errors.Is(err, state.ErrNoIssue), errors.Is(err, state.ErrNoPrevious):
+		return ExitNotFound
+	case errors.Is(err, config.ErrExists):
+		return ExitConflict
+	}
+	return ExitFailure
+}
+
+// isNetworkError reports whether err comes from reaching Jira. net.Error
+// alone is too broad: syscall.Errno implements it too, so a failure to open
+// /dev/tty would count. So does *url.Error, which also wraps requests that
+// never left, like those to a URL without a scheme.
+func isNetworkError(err error) bool {
+	var (
+		urlErr  *url.Error
+		opErr   *net.OpError
+		dnsErr  *net.DNSError
+		certErr *tls.CertificateVerificationError
+	)
+	return errors.As(err, &opErr) || errors.As(err, &dnsErr) || errors.As(err, &certErr) ||
+		errors.As(err, &urlErr) && urlErr.Timeout() ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// ReportError prints err on w, with a hint for its class, and returns the
+// exit code for it. Errors of plugins and shell aliases are not printed
+// again.
+func ReportError(w io.Writer, err error) int {
+	code := ExitCode(err)
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return code
+	}
+
+	fmt.Fprintf(w, "Error: %v\n", err)
+	var usageErr *UsageError
+	switch {
+	case errors.As(err, &usageErr) && usageErr.Command != "":
+		fmt.Fprintf(w, "Run '%s --help' for usage.\n", usageErr.Command)
+	case code == ExitConfig:
+		fmt.Fprintln(w, "Run 'jcli init' to set up Jira, or 'jcli doctor' to check the configuration.")
+	case code == ExitAuth:
+		fmt.Fprintln(w, "Check the credentials with 'jcli doctor'.")
+	case code == ExitNetwork:
+		fmt.Fprintln(w, "Check the connection to Jira; --debug traces the requests.")
+	}
+	return code
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutunak/jcli/internal/config"
+	"github.com/tutunak/jcli/internal/jira"
+	"github.com/tutunak/jcli/internal/prompt"
+	"github.com/tutunak/jcli/internal/tui"
+)
+
+func newInitCmd() *cobra.Command {
+	var server bool
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Set up jcli interactively",
+		Long: `Walk through the first-time setup of the active profile:
+
+  1. Jira URL and credentials, verified against Jira before going on
+  2. the default project, picked from the projects you can access
+  3. the default status filter, picked from the statuses of that project
+  4. the branch name template
+  5. shell completion and the prompt segment for bash, zsh or fish
+
+Nothing is written until the last step; the settings are then saved to the
+config file and, if you agree, the shell setup is appended to your shell's
+startup file. Running init again shows the current values as defaults.`,
+		Example: `  jcli init
+  jcli init --server
+  jcli --profile work init`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeInit(server)
+		},
+	}
+	cmd.Flags().BoolVar(&server, "server", false, "set up a Jira Server/Data Center instance with a Personal Access Token")
+	return cmd
+}
+
+func executeInit(server bool) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	selector := tui.NewSelector()
+
+	client, err := verifyCredentials(selector, cfg, server)
+	if err != nil {
+		return err
+	}
+
+	projects, err := client.ListProjects()
+	if err != nil {
+		return err
+	}
+	project, err := selector.SelectProject(projects, cfg.Defaults.Project)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Set("defaults.project", project); err != nil {
+		return err
+	}
+
+	statuses, err := client.ProjectStatuses(project)
+	if err != nil {
+		return err
+	}
+	status, err := selector.SelectStatus(statuses, cfg.Defaults.Status)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Set("defaults.status", status); err != nil {
+		return err
+	}
+
+	template, err := selector.PromptBranchTemplate(cfg.Git.BranchTemplate)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Set("git.branch_template", template); err != nil {
+		return err
+	}
+
+	rcFile, block, install, err := promptShellIntegration(selector)
+	if err != nil {
+		return err
+	}
+
+	if err := storeToken(selector, &cfg.Jira); err != nil {
+		return err
+	}
+	if _, err := config.Update(func(c *config.Config) error {
+		setCredentials(c, cfg.Jira)
+		c.Defaults.Project = cfg.Defaults.Project
+		c.Defaults.Status = cfg.Defaults.Status
+		c.Git.BranchTemplate = cfg.Git.BranchTemplate
+		return nil
+	}); err != nil {
+		return err
+	}
+	path, _ := config.ConfigPath()
+	infof("Configuration saved to %s.\n", path)
+	reportTokenStore(&cfg.Jira)
+	infof("Default project: %s, status filter: %s.\n", project, status)
+
+	switch {
+	case install:
+		added, err := prompt.Install(rcFile, block)
+		if err != nil {
+			return err
+		}
+		if added {
+			infof("Shell integration added to %s; open a new shell to use it.\n", rcFile)
+		} else {
+			infof("Shell integration is already set up in %s.\n", rcFile)
+		}
+	case block != "":
+		fmt.Printf("\nAdd this to %s to set up the shell integration:\n\n%s", rcFile, block)
+	}
+	infof("Run 'jcli issue select' to pick the issue you are working on.\n")
+	return nil
+}
+
+// verifyCredentials asks for credentials until Jira accepts them or the user
+// gives up, and returns a client that uses them.
+func verifyCredentials(selector *tui.Selector, cfg *config.Config, server bool) (jira.Client, error) {
+	for {
+		if err := promptCredentials(selector, cfg, server); err != nil {
+			return nil, err
+		}
+
+		client, err := newJiraClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		user, err := client.Myself()
+		if err == nil {
+			infof("Authenticated as %s.\n", user.DisplayName)
+			return client, nil
+		}
+
+		fmt.Fprintf(os.Stderr, "Could not verify the credentials: %v\n", err)
+		retry, cerr := selector.Confirm("Try again?", "Enter the URL and credentials again")
+		if cerr != nil {
+			return nil, cerr
+		}
+		if !retry {
+			return nil, err
+		}
+	}
+}
+
+// promptShellIntegration asks for the shell to set up and whether to append
+// the setup to its startup file. block is empty when the step was skipped.
+func promptShellIntegration(selector *tui.Selector) (file, block string, install bool, err error) {
+	shell, err := selector.SelectShell(prompt.Shells())
+	if err != nil || shell == "" {
+		return "", "", false, err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to find the home directory: %w", err)
+	}
+	file, block, err = prompt.Integration(shell, home)
+	if err != nil {
+		return "", "", false, err
+	}
+	install, err = selector.Confirm("Append the shell setup to "+file+"?", "Otherwise it is printed at the end")
+	if err != nil {
+		return "", "", false, err
+	}
+	return file, block, install, nil
+}
@@ -1,47 +1,158 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutunak/jcli/internal/branch"
+	"github.com/tutunak/jcli/internal/config"
+	"github.com/tutunak/jcli/internal/git"
+	"github.com/tutunak/jcli/internal/output"
+	"github.com/tutunak/jcli/internal/state"
 )
 
-func executeIssue(args []string) error {
-	if len(args) == 0 {
-		printIssueUsage()
-		return nil
+func newIssueCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "issue",
+		Short: "Manage Jira issues",
+		Long: `Select the issue you are working on and generate branch names for it.
+
+The current issue is kept per git repository; --global uses the machine-wide
+selection instead.`,
+		Example: `  jcli issue select              # Interactive selection from In Progress issues
+  jcli issue select PROJ-123     # Select specific issue
+  jcli issue select --query mine # Interactive selection from a named query
+  jcli issue list                # Table of your issues
+  jcli issue list --sort -priority  # ... sorted by priority
+  jcli issue current             # Show currently selected issue
+  jcli issue current --from-branch  # Show issue of the current git branch
+  jcli issue current --global    # Show the machine-wide selection
+  jcli issue branch              # Generate branch name for current issue
+  jcli issue switch -            # Go back to the previously selected issue
+  jcli issue recent              # Pick from recently selected issues`,
+		Args: cobra.ArbitraryArgs,
+		RunE: groupRunE,
+	}
+	cmd.AddCommand(
+		newIssueSelectCmd(),
+		newIssueListCmd(),
+		newIssueCurrentCmd(),
+		newIssueBranchCmd(),
+		newIssueSwitchCmd(),
+		newIssueRecentCmd(),
+	)
+	return cmd
+}
+
+// addGlobalFlag adds --global to an issue command.
+func addGlobalFlag(cmd *cobra.Command, global *bool) {
+	cmd.Flags().BoolVar(global, "global", false, "use the machine-wide selection instead of the repository's")
+}
+
+// addFromBranchFlag adds --from-branch to an issue command.
+func addFromBranchFlag(cmd *cobra.Command, fromBranch *bool) {
+	cmd.Flags().BoolVar(fromBranch, "from-branch", false, "detect the current issue from the checked-out git branch")
+}
+
+// issueDoc converts a selection for --output.
+func issueDoc(issue state.CurrentIssue) *output.Issue {
+	doc := &output.Issue{Key: issue.Key, Summary: issue.Summary}
+	if !issue.SelectedAt.IsZero() {
+		doc.SelectedAt = &issue.SelectedAt
 	}
+	return doc
+}
 
-	switch args[0] {
-	case "select":
-		return executeIssueSelect(args[1:])
-	case "current":
-		return executeIssueCurrent(args[1:])
-	case "branch":
-		return executeIssueBranch(args[1:])
-	case "help", "--help", "-h":
-		printIssueUsage()
+// selectIssue makes key the current issue of scope and returns the new
+// selection. The state is updated under its lock so that concurrent
+// selections in other scopes are kept.
+func selectIssue(scope, key, summary string) (*state.CurrentIssue, error) {
+	st, err := state.Update(func(st *state.State) error {
+		st.SetCurrentIssueFor(scope, key, summary)
 		return nil
-	default:
-		fmt.Fprintf(os.Stderr, "Unknown issue command: %s\n", args[0])
-		printIssueUsage()
-		return fmt.Errorf("unknown issue command: %s", args[0])
+	})
+	if err != nil {
+		return nil, err
 	}
+	return st.CurrentIssueFor(scope), nil
 }
 
-func printIssueUsage() {
-	fmt.Println(`jcli issue - Manage Jira issues
+// errNoIssue is returned by issue commands that need a selected issue when
+// none is found.
+var errNoIssue = fmt.Errorf("%w; use 'jcli issue select' to select an issue first", state.ErrNoIssue)
 
-Usage:
-  jcli issue <command> [flags]
+// resolvedIssue is the active issue together with where it was found.
+type resolvedIssue struct {
+	*state.CurrentIssue
+	Branch string
+}
 
-Commands:
-  select [issue-id]   Select an issue (interactive or by ID)
-  current             Show current active issue
-  branch              Generate branch name for current issue
+func (r *resolvedIssue) FromBranch() bool {
+	return r.Branch != ""
+}
 
-Examples:
-  jcli issue select              # Interactive selection from In Progress issues
-  jcli issue select PROJ-123     # Select specific issue
-  jcli issue current             # Show currently selected issue
-  jcli issue branch              # Generate branch name for current issue`)
+// issueScope returns the state scope for the working directory, or the
+// global scope ("") when global is set.
+func issueScope(global bool) (string, error) {
+	if global {
+		return "", nil
+	}
+	scope, err := state.ScopeFor("")
+	if err != nil {
+		return "", fmt.Errorf("failed to determine issue scope: %w", err)
+	}
+	return scope, nil
+}
+
+// resolveCurrentIssue returns the active issue of scope. When branch
+// detection is enabled (via config or fromBranch) the key in the
+// checked-out git branch wins; the issue stored in state is the fallback.
+// It returns nil when no issue can be found.
+func resolveCurrentIssue(cfg *config.Config, st *state.State, scope string, fromBranch bool) (*resolvedIssue, error) {
+	selected := st.CurrentIssueFor(scope)
+
+	if fromBranch || cfg.Git.DetectIssue {
+		issue, err := issueFromBranch(cfg, selected)
+		if err != nil {
+			return nil, err
+		}
+		if issue != nil {
+			return issue, nil
+		}
+	}
+
+	if selected == nil {
+		return nil, nil
+	}
+	return &resolvedIssue{CurrentIssue: selected}, nil
+}
+
+func issueFromBranch(cfg *config.Config, selected *state.CurrentIssue) (*resolvedIssue, error) {
+	// Any git failure, e.g. no repository or no git binary, leaves the
+	// selection in state as the fallback.
+	branchName, err := git.CurrentBranch("")
+	if err != nil {
+		if !errors.Is(err, git.ErrNotRepository) && !errors.Is(err, git.ErrDetachedHead) {
+			debugf("no issue from the git branch: %v", err)
+		}
+		return nil, nil
+	}
+
+	matcher, err := branch.NewKeyMatcher(cfg.Git.IssuePattern, cfg.IssueProjectKeys())
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := matcher.Match(branchName)
+	if !ok {
+		return nil, nil
+	}
+
+	// Reuse the stored summary when the branch belongs to the selected issue.
+	if selected != nil && selected.Key == key {
+		return &resolvedIssue{CurrentIssue: selected, Branch: branchName}, nil
+	}
+	return &resolvedIssue{CurrentIssue: &state.CurrentIssue{Key: key}, Branch: branchName}, nil
 }
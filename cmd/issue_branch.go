@@ -3,26 +3,81 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/spf13/cobra"
+
 	"github.com/tutunak/jcli/internal/branch"
+	"github.com/tutunak/jcli/internal/output"
 	"github.com/tutunak/jcli/internal/state"
 )
 
-func executeIssueBranch(args []string) error {
+func newIssueBranchCmd() *cobra.Command {
+	var global, fromBranch bool
+	cmd := &cobra.Command{
+		Use:   "branch",
+		Short: "Generate a branch name for the current issue",
+		Long: `Print a git branch name for the current issue, built from git.branch_template.
+
+  git checkout -b $(jcli issue branch)`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeIssueBranch(global, fromBranch)
+		},
+	}
+	addGlobalFlag(cmd, &global)
+	addFromBranchFlag(cmd, &fromBranch)
+	return cmd
+}
+
+func executeIssueBranch(global, fromBranch bool) error {
+	scope, err := issueScope(global)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
 	st, err := state.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load state: %w", err)
 	}
 
-	if !st.HasCurrentIssue() {
-		fmt.Println("No issue currently selected.")
-		fmt.Println("Use 'jcli issue select' to select an issue first.")
-		return fmt.Errorf("no issue selected")
+	issue, err := resolveCurrentIssue(cfg, st, scope, fromBranch)
+	if err != nil {
+		return err
+	}
+
+	if issue == nil {
+		return errNoIssue
+	}
+
+	summary := issue.Summary
+	if summary == "" {
+		// The issue came from the git branch and was never selected, so
+		// look its summary up in Jira.
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("summary of %s is unknown: %w", issue.Key, err)
+		}
+		client, err := newJiraClient(cfg)
+		if err != nil {
+			return err
+		}
+		fetched, err := client.GetIssue(issue.Key)
+		if err != nil {
+			return fmt.Errorf("failed to get issue %s: %w", issue.Key, err)
+		}
+		summary = fetched.Fields.Summary
 	}
 
-	issue := st.CurrentIssue
 	gen := branch.NewGenerator()
-	branchName := gen.Generate(issue.Key, issue.Summary)
+	gen.SetTemplate(cfg.Git.BranchTemplate)
+	branchName := gen.Generate(issue.Key, summary)
 
+	if printer.Structured() {
+		return printer.Print(&output.Branch{Key: issue.Key, Name: branchName})
+	}
 	fmt.Println(branchName)
 	return nil
 }
@@ -3,25 +3,67 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/spf13/cobra"
+
 	"github.com/tutunak/jcli/internal/state"
 )
 
-func executeIssueCurrent(args []string) error {
+func newIssueCurrentCmd() *cobra.Command {
+	var global, fromBranch bool
+	cmd := &cobra.Command{
+		Use:   "current",
+		Short: "Show the current issue",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeIssueCurrent(global, fromBranch)
+		},
+	}
+	addGlobalFlag(cmd, &global)
+	addFromBranchFlag(cmd, &fromBranch)
+	return cmd
+}
+
+func executeIssueCurrent(global, fromBranch bool) error {
+	scope, err := issueScope(global)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
 	st, err := state.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load state: %w", err)
 	}
 
-	if !st.HasCurrentIssue() {
-		fmt.Println("No issue currently selected.")
-		fmt.Println("Use 'jcli issue select' to select an issue.")
-		return nil
+	issue, err := resolveCurrentIssue(cfg, st, scope, fromBranch)
+	if err != nil {
+		return err
+	}
+
+	if issue == nil {
+		return errNoIssue
+	}
+
+	if printer.Structured() {
+		doc := issueDoc(*issue.CurrentIssue)
+		doc.Branch = issue.Branch
+		return printer.Print(doc)
 	}
 
-	issue := st.CurrentIssue
 	fmt.Printf("Current issue: %s\n", issue.Key)
-	fmt.Printf("Summary: %s\n", issue.Summary)
-	fmt.Printf("Selected at: %s\n", issue.SelectedAt.Format("2006-01-02 15:04:05"))
+	if issue.Summary != "" {
+		fmt.Printf("Summary: %s\n", issue.Summary)
+	}
+	if issue.FromBranch() {
+		fmt.Printf("Git branch: %s\n", issue.Branch)
+	}
+	if !issue.SelectedAt.IsZero() {
+		fmt.Printf("Selected at: %s\n", issue.SelectedAt.Format("2006-01-02 15:04:05"))
+	}
 
 	return nil
 }
@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutunak/jcli/internal/config"
+	"github.com/tutunak/jcli/internal/issuelist"
+	"github.com/tutunak/jcli/internal/jira"
+	"github.com/tutunak/jcli/internal/output"
+)
+
+type issueListOptions struct {
+	query   string
+	jql     string
+	columns []string
+	sort    []string
+	limit   int
+}
+
+func newIssueListCmd() *cobra.Command {
+	var opts issueListOptions
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List issues in a table",
+		Long: `List the issues of the default project with the default status that are
+assigned to you, the issues of a named query (--query) or of any JQL (--jql).
+
+Columns and sort order default to list.columns and list.sort in config.yaml.
+Columns are key, summary, type, status, priority, assignee, reporter,
+created, updated and custom field IDs such as customfield_10016. Prefix a
+sort field with "-" for descending order; --sort replaces the ORDER BY of
+the query.
+
+The table is truncated to the terminal width, and issue keys link to Jira in
+terminals that support hyperlinks.`,
+		Example: `  jcli issue list
+  jcli issue list --query mine
+  jcli issue list --jql 'project = PROJ AND sprint in openSprints()'
+  jcli issue list --columns key,status,customfield_10016,summary --sort -priority,key`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeIssueList(opts)
+		},
+	}
+	cmd.Flags().StringVar(&opts.query, "query", "", "list the issues of the named JQL query in config")
+	cmd.Flags().StringVar(&opts.jql, "jql", "", "list the issues matching a JQL query")
+	cmd.Flags().StringSliceVar(&opts.columns, "columns", nil, "comma-separated columns to show")
+	cmd.Flags().StringSliceVar(&opts.sort, "sort", nil, "comma-separated sort fields, e.g. -updated,key")
+	cmd.Flags().IntVar(&opts.limit, "limit", 50, "maximum number of issues")
+	cmd.MarkFlagsMutuallyExclusive("query", "jql")
+	cmd.RegisterFlagCompletionFunc("query", completeQueries)
+	cmd.RegisterFlagCompletionFunc("columns", completeListFields)
+	cmd.RegisterFlagCompletionFunc("sort", completeListFields)
+	return cmd
+}
+
+func executeIssueList(opts issueListOptions) error {
+	if opts.limit <= 0 {
+		return fmt.Errorf("invalid --limit %d: must be positive", opts.limit)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	jql, err := listJQL(cfg, opts)
+	if err != nil {
+		return err
+	}
+
+	columns, err := issuelist.ParseColumns(firstNonEmpty(opts.columns, cfg.List.Columns, issuelist.DefaultColumns))
+	if err != nil {
+		return err
+	}
+
+	client, err := newJiraClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	issues, err := client.Search(jql, jira.SearchOptions{
+		Fields: issuelist.CustomFields(columns),
+		Limit:  opts.limit,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to search issues: %w", err)
+	}
+	rememberIssues(issues)
+
+	browseURL := func(key string) string {
+		return strings.TrimSuffix(cfg.Jira.URL, "/") + "/browse/" + key
+	}
+
+	if printer.Structured() {
+		return printer.Print(newIssueListDoc(issues, columns, browseURL))
+	}
+
+	if len(issues) == 0 {
+		fmt.Println("No issues found.")
+		return nil
+	}
+
+	table := issuelist.Table{
+		Columns: columns,
+		Width:   issuelist.TerminalWidth(os.Stdout),
+	}
+	if issuelist.HyperlinksSupported(os.Stdout) {
+		table.Link = browseURL
+	}
+	return table.Render(os.Stdout, issues)
+}
+
+// listJQL builds the query of "issue list" from the filter flags and the
+// sort order.
+func listJQL(cfg *config.Config, opts issueListOptions) (string, error) {
+	var jql string
+	switch {
+	case opts.jql != "":
+		jql = opts.jql
+	case opts.query != "":
+		q, ok := cfg.Queries[opts.query]
+		if !ok {
+			return "", fmt.Errorf("unknown query %q; define it under 'queries' in config.yaml or .jcli.yaml", opts.query)
+		}
+		jql = q
+	default:
+		if !cfg.HasProject() {
+			fmt.Fprintln(os.Stderr, "Warning: No default project set. Run 'jcli config project <KEY>' to set one.")
+			return "", fmt.Errorf("no project configured")
+		}
+		jql = jira.IssuesJQL(cfg.Defaults.Project, cfg.Defaults.Status)
+	}
+
+	sort := firstNonEmpty(opts.sort, cfg.List.Sort)
+	if len(sort) == 0 && !issuelist.HasOrderBy(jql) {
+		sort = issuelist.DefaultSort
+	}
+	return issuelist.BuildJQL(jql, sort)
+}
+
+// completeListFields completes the comma-separated values of --columns and
+// --sort.
+func completeListFields(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	done, last := "", toComplete
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		done, last = toComplete[:i+1], toComplete[i+1:]
+	}
+	prefix := ""
+	if strings.HasPrefix(last, "-") {
+		prefix, last = "-", last[1:]
+	}
+	var completions []string
+	for _, name := range issuelist.ColumnNames() {
+		if strings.HasPrefix(name, last) {
+			completions = append(completions, done+prefix+name)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// firstNonEmpty returns the first list that has elements.
+func firstNonEmpty(lists ...[]string) []string {
+	for _, list := range lists {
+		if len(list) > 0 {
+			return list
+		}
+	}
+	return nil
+}
+
+// issueListDoc is the --output document of "issue list". It is encoded as
+// an output.IssueList; tables and TSV show the selected columns.
+type issueListDoc struct {
+	output.IssueList
+	columns []issuelist.Column
+	issues  []jira.Issue
+}
+
+func newIssueListDoc(issues []jira.Issue, columns []issuelist.Column, browseURL func(string) string) issueListDoc {
+	list := make(output.IssueList, len(issues))
+	for i, issue := range issues {
+		f := issue.Fields
+		doc := output.Issue{
+			Key:      issue.Key,
+			Summary:  f.Summary,
+			Type:     f.IssueType.Name,
+			Status:   f.Status.Name,
+			Assignee: issuelist.UserName(f.Assignee),
+			URL:      browseURL(issue.Key),
+		}
+		if f.Priority != nil {
+			doc.Priority = f.Priority.Name
+		}
+		if updated, ok := issuelist.ParseTime(f.Updated); ok {
+			doc.Updated = &updated
+		}
+		for _, id := range issuelist.CustomFields(columns) {
+			if doc.Fields == nil {
+				doc.Fields = make(map[string]string)
+			}
+			doc.Fields[id] = f.CustomText(id)
+		}
+		list[i] = doc
+	}
+	return issueListDoc{IssueList: list, columns: columns, issues: issues}
+}
+
+func (d issueListDoc) Header() []string {
+	header := make([]string, len(d.columns))
+	for i, c := range d.columns {
+		header[i] = c.Header
+	}
+	return header
+}
+
+func (d issueListDoc) Rows() [][]string {
+	rows := make([][]string, len(d.issues))
+	for r, issue := range d.issues {
+		row := make([]string, len(d.columns))
+		for i, c := range d.columns {
+			row[i] = c.Value(issue)
+		}
+		rows[r] = row
+	}
+	return rows
+}
+
+func (d issueListDoc) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.IssueList)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutunak/jcli/internal/output"
+	"github.com/tutunak/jcli/internal/state"
+	"github.com/tutunak/jcli/internal/tui"
+)
+
+func newIssueRecentCmd() *cobra.Command {
+	var global, list bool
+	cmd := &cobra.Command{
+		Use:   "recent",
+		Short: "Pick from recently selected issues (no network access)",
+		Long: `Pick from recently selected issues without contacting Jira. With --list,
+--output or --template the history is printed instead.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeIssueRecent(global, list)
+		},
+	}
+	addGlobalFlag(cmd, &global)
+	cmd.Flags().BoolVar(&list, "list", false, "print the history instead of picking an issue")
+	return cmd
+}
+
+func executeIssueRecent(global, list bool) error {
+	scope, err := issueScope(global)
+	if err != nil {
+		return err
+	}
+
+	if _, err := loadConfig(); err != nil {
+		return err
+	}
+
+	st, err := state.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	recent := st.HistoryFor(scope)
+
+	if printer.Structured() {
+		history := make(output.IssueList, len(recent))
+		for i, issue := range recent {
+			history[i] = *issueDoc(issue)
+		}
+		return printer.Print(history)
+	}
+
+	if len(recent) == 0 {
+		fmt.Println("No recently selected issues.")
+		return nil
+	}
+
+	if list {
+		for _, issue := range recent {
+			fmt.Printf("%s\t%s\t%s\n", issue.Key, issue.SelectedAt.Format("2006-01-02 15:04:05"), issue.Summary)
+		}
+		return nil
+	}
+
+	selector := tui.NewSelector()
+	selected, err := selector.SelectRecent(recent)
+	if err != nil {
+		return err
+	}
+
+	return switchToIssue(scope, *selected)
+}
@@ -4,70 +4,121 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/spf13/cobra"
+
 	"github.com/tutunak/jcli/internal/config"
 	"github.com/tutunak/jcli/internal/jira"
-	"github.com/tutunak/jcli/internal/state"
 	"github.com/tutunak/jcli/internal/tui"
 )
 
-func executeIssueSelect(args []string) error {
-	cfg, err := config.Load()
+func newIssueSelectCmd() *cobra.Command {
+	var (
+		global bool
+		query  string
+	)
+	cmd := &cobra.Command{
+		Use:   "select [issue-key]",
+		Short: "Select an issue (interactive or by key)",
+		Long: `Select the issue you are working on. Without a key, pick interactively
+from the issues of the default project with the default status, or from a
+named JQL query with --query.`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeIssueKeys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeIssueSelect(args, global, query)
+		},
+	}
+	addGlobalFlag(cmd, &global)
+	cmd.Flags().StringVar(&query, "query", "", "select from the named JQL query in config")
+	cmd.RegisterFlagCompletionFunc("query", completeQueries)
+	return cmd
+}
+
+func executeIssueSelect(args []string, global bool, query string) error {
+	scope, err := issueScope(global)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
 	}
 
 	if err := cfg.Validate(); err != nil {
-		fmt.Fprintln(os.Stderr, "Configuration error:", err)
-		fmt.Fprintln(os.Stderr, "Run 'jcli config credentials' to set up your Jira credentials.")
 		return err
 	}
 
-	if !cfg.HasProject() {
+	if query == "" && !cfg.HasProject() {
 		fmt.Fprintln(os.Stderr, "Warning: No default project set. Run 'jcli config project <KEY>' to set one.")
 		return fmt.Errorf("no project configured")
 	}
 
-	client := jira.NewClient(cfg.Jira.URL, cfg.Jira.Email, cfg.Jira.APIToken)
-	st, err := state.Load()
+	client, err := newJiraClient(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to load state: %w", err)
+		return err
 	}
 
 	// If issue ID provided, select it directly
 	if len(args) > 0 {
 		issueKey := args[0]
-		return selectIssueByKey(client, st, issueKey)
+		return selectIssueByKey(client, scope, issueKey)
 	}
 
 	// Interactive selection
-	return selectIssueInteractive(client, st, cfg)
+	return selectIssueInteractive(client, scope, cfg, query)
 }
 
-func selectIssueByKey(client jira.Client, st *state.State, issueKey string) error {
+func selectIssueByKey(client jira.Client, scope, issueKey string) error {
 	issue, err := client.GetIssue(issueKey)
 	if err != nil {
 		return fmt.Errorf("failed to get issue %s: %w", issueKey, err)
 	}
+	rememberIssues([]jira.Issue{*issue})
 
-	st.SetCurrentIssue(issue.Key, issue.Fields.Summary)
-	if err := st.Save(); err != nil {
-		return fmt.Errorf("failed to save state: %w", err)
+	current, err := selectIssue(scope, issue.Key, issue.Fields.Summary)
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("Selected: %s - %s\n", issue.Key, issue.Fields.Summary)
+	if printer.Structured() {
+		doc := issueDoc(*current)
+		doc.Description = issue.Fields.DescriptionText()
+		return printer.Print(doc)
+	}
+	infof("Selected: %s - %s\n", issue.Key, issue.Fields.Summary)
 	return nil
 }
 
-func selectIssueInteractive(client jira.Client, st *state.State, cfg *config.Config) error {
-	issues, err := client.SearchIssues(cfg.Defaults.Project, cfg.Defaults.Status)
-	if err != nil {
-		return fmt.Errorf("failed to search issues: %w", err)
+func selectIssueInteractive(client jira.Client, scope string, cfg *config.Config, query string) error {
+	var issues []jira.Issue
+	if query != "" {
+		jql, ok := cfg.Queries[query]
+		if !ok {
+			return fmt.Errorf("unknown query %q; define it under 'queries' in config.yaml or .jcli.yaml", query)
+		}
+		found, err := client.SearchJQL(jql)
+		if err != nil {
+			return fmt.Errorf("failed to search issues: %w", err)
+		}
+		if len(found) == 0 {
+			fmt.Printf("No issues found for query %s\n", query)
+			return nil
+		}
+		issues = found
+	} else {
+		found, err := client.SearchIssues(cfg.Defaults.Project, cfg.Defaults.Status)
+		if err != nil {
+			return fmt.Errorf("failed to search issues: %w", err)
+		}
+		if len(found) == 0 {
+			fmt.Printf("No issues found in project %s with status %q\n", cfg.Defaults.Project, cfg.Defaults.Status)
+			return nil
+		}
+		issues = found
 	}
 
-	if len(issues) == 0 {
-		fmt.Printf("No issues found in project %s with status %q\n", cfg.Defaults.Project, cfg.Defaults.Status)
-		return nil
-	}
+	rememberIssues(issues)
 
 	selector := tui.NewSelector()
 	selected, err := selector.SelectIssue(issues)
@@ -75,11 +126,14 @@ func selectIssueInteractive(client jira.Client, st *state.State, cfg *config.Con
 		return err
 	}
 
-	st.SetCurrentIssue(selected.Key, selected.Fields.Summary)
-	if err := st.Save(); err != nil {
-		return fmt.Errorf("failed to save state: %w", err)
+	current, err := selectIssue(scope, selected.Key, selected.Fields.Summary)
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("Selected: %s - %s\n", selected.Key, selected.Fields.Summary)
+	if printer.Structured() {
+		return printer.Print(issueDoc(*current))
+	}
+	infof("Selected: %s - %s\n", selected.Key, selected.Fields.Summary)
 	return nil
 }
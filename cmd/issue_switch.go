@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutunak/jcli/internal/state"
+)
+
+func newIssueSwitchCmd() *cobra.Command {
+	var global bool
+	cmd := &cobra.Command{
+		Use:   "switch <issue-key|->",
+		Short: "Switch to a recently selected issue ('-' for the previous one)",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("issue key or '-' required")
+			}
+			return nil
+		},
+		ValidArgsFunction: completeRecentIssueKeys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeIssueSwitch(args[0], global)
+		},
+	}
+	addGlobalFlag(cmd, &global)
+	return cmd
+}
+
+func executeIssueSwitch(key string, global bool) error {
+	scope, err := issueScope(global)
+	if err != nil {
+		return err
+	}
+
+	if _, err := loadConfig(); err != nil {
+		return err
+	}
+
+	// The target is looked up under the state lock so that "-" sees the
+	// selections of concurrent jcli processes.
+	st, err := state.Update(func(st *state.State) error {
+		var target *state.CurrentIssue
+		if key == "-" {
+			target = st.PreviousIssue(scope)
+			if target == nil {
+				return state.ErrNoPrevious
+			}
+		} else {
+			target = st.RecentIssue(key)
+			if target == nil {
+				return fmt.Errorf("issue %s is not in the recent history; use 'jcli issue select %s'", key, key)
+			}
+		}
+		st.SetCurrentIssueFor(scope, target.Key, target.Summary)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return printSwitched(*st.CurrentIssueFor(scope))
+}
+
+func switchToIssue(scope string, issue state.CurrentIssue) error {
+	current, err := selectIssue(scope, issue.Key, issue.Summary)
+	if err != nil {
+		return err
+	}
+	return printSwitched(*current)
+}
+
+func printSwitched(issue state.CurrentIssue) error {
+	if printer.Structured() {
+		return printer.Print(issueDoc(issue))
+	}
+	infof("Switched to: %s - %s\n", issue.Key, issue.Summary)
+	return nil
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutunak/jcli/internal/config"
+	"github.com/tutunak/jcli/internal/output"
+	"github.com/tutunak/jcli/internal/plugin"
+	"github.com/tutunak/jcli/internal/state"
+)
+
+func newPluginCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plugin",
+		Short: "List external jcli-<name> commands",
+		Long: `Executables named jcli-<name> on PATH run as 'jcli <name>', like git and
+kubectl plugins. Built-in commands and aliases take precedence, and of several
+plugins with the same name the first on PATH runs.
+
+Plugins receive the remaining arguments and these environment variables:
+
+  JCLI_BIN              path of the jcli executable
+  JCLI_CONFIG           config file in use
+  JCLI_PROFILE          active profile
+  JCLI_JIRA_URL         Jira site URL
+  JCLI_API_URL          base URL of REST requests; differs from the site
+                        URL for OAuth, which goes through api.atlassian.com
+  JCLI_AUTH_SCHEME      "basic" (JCLI_EMAIL and the token) or "bearer"
+  JCLI_EMAIL            account email for basic authentication
+  JCLI_TOKEN_COMMAND    command printing the token ('jcli auth token')
+  JCLI_ISSUE_KEY        current issue, if any
+  JCLI_ISSUE_SUMMARY    summary of the current issue
+
+The token itself is not passed; run JCLI_TOKEN_COMMAND when it is needed.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: groupRunE,
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List the plugins found on PATH",
+		Args:  cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			return executePluginList(c.Root())
+		},
+	})
+	return cmd
+}
+
+func executePluginList(root *cobra.Command) error {
+	var plugins output.PluginList
+	for _, p := range plugin.List() {
+		plugins = append(plugins, output.Plugin{
+			Name:       p.Name,
+			Path:       p.Path,
+			ShadowedBy: p.ShadowedBy,
+			Builtin:    isBuiltin(root, p.Name),
+		})
+	}
+
+	if printer.Structured() {
+		return printer.Print(plugins)
+	}
+
+	if len(plugins) == 0 {
+		fmt.Println("No plugins found. Put executables named jcli-<name> on PATH.")
+		return nil
+	}
+	for _, p := range plugins {
+		fmt.Printf("%s\t%s\n", p.Name, p.Path)
+		switch {
+		case p.Builtin:
+			fmt.Printf("  warning: hidden by the built-in command %q\n", p.Name)
+		case p.ShadowedBy != "":
+			fmt.Printf("  warning: shadowed by %s\n", p.ShadowedBy)
+		}
+	}
+	return nil
+}
+
+// completePlugins suggests the aliases and plugin names next to the
+// built-in commands.
+func completePlugins(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	aliases, _ := config.Aliases()
+	for _, name := range slices.Sorted(maps.Keys(aliases)) {
+		if strings.HasPrefix(name, toComplete) && !isBuiltin(cmd.Root(), name) {
+			names = append(names, name+"\talias for "+aliases[name])
+		}
+	}
+	for _, p := range plugin.List() {
+		if _, ok := aliases[p.Name]; ok {
+			continue
+		}
+		if p.ShadowedBy == "" && strings.HasPrefix(p.Name, toComplete) && !isBuiltin(cmd.Root(), p.Name) {
+			names = append(names, p.Name+"\tplugin "+p.Path)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// isBuiltin reports whether name is a command of root.
+func isBuiltin(root *cobra.Command, name string) bool {
+	c, _, err := root.Find([]string{name})
+	return err == nil && c != root
+}
+
+// findPlugin returns the plugin that args invoke, if the first argument is
+// not a built-in command but a jcli-<name> executable on PATH.
+func findPlugin(root *cobra.Command, args []string) (string, bool) {
+	// Cobra adds its hidden __complete commands only when executing.
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") || strings.HasPrefix(args[0], "__") || isBuiltin(root, args[0]) {
+		return "", false
+	}
+	path, err := plugin.Find(args[0])
+	if err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// runPlugin runs a plugin with the context of jcli in its environment and
+// returns an *ExitError when it fails.
+func runPlugin(path string, args []string) error {
+	return runExternal("plugin "+path, exec.Command(path, args...))
+}
+
+// runExternal runs a plugin or shell alias in the terminal of jcli, with
+// pluginEnv added to its environment. what names it in errors. A non-zero
+// exit becomes an *ExitError with the same code.
+func runExternal(what string, cmd *exec.Cmd) error {
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), pluginEnv()...)
+
+	// The child handles Ctrl-C itself; jcli waits for it to exit.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return &ExitError{Code: exitErr.ExitCode()}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run %s: %w", what, err)
+	}
+	return nil
+}
+
+// pluginEnv describes the active profile and the current issue. It is
+// best effort: without a usable configuration only JCLI_BIN and
+// JCLI_CONFIG are set.
+func pluginEnv() []string {
+	var env []string
+	if exe, err := os.Executable(); err == nil {
+		env = append(env, "JCLI_BIN="+exe)
+	}
+	if path, err := config.ConfigPath(); err == nil {
+		env = append(env, "JCLI_CONFIG="+path)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Warning:", err)
+		return env
+	}
+
+	profile := cfg.ActiveProfile()
+	env = append(env,
+		"JCLI_PROFILE="+profile,
+		"JCLI_JIRA_URL="+cfg.Jira.URL,
+	)
+	if exe, err := os.Executable(); err == nil {
+		env = append(env, "JCLI_TOKEN_COMMAND="+shellQuote(exe)+" --profile "+shellQuote(profile)+" auth token")
+	}
+	apiURL := cfg.Jira.URL
+	if cfg.Jira.Auth == config.AuthOAuth {
+		if source, err := newOAuthSource(cfg, nil); err == nil {
+			apiURL = source.BaseURL()
+		}
+	}
+	env = append(env, "JCLI_API_URL="+apiURL)
+	if cfg.Jira.Auth == config.AuthOAuth || cfg.IsServer() {
+		env = append(env, "JCLI_AUTH_SCHEME=bearer")
+	} else {
+		env = append(env, "JCLI_AUTH_SCHEME=basic", "JCLI_EMAIL="+cfg.Jira.Email)
+	}
+
+	scope, err := issueScope(false)
+	if err != nil {
+		return env
+	}
+	st, err := state.Load()
+	if err != nil {
+		return env
+	}
+	if issue, err := resolveCurrentIssue(cfg, st, scope, false); err == nil && issue != nil {
+		env = append(env,
+			"JCLI_ISSUE_KEY="+issue.Key,
+			"JCLI_ISSUE_SUMMARY="+issue.Summary,
+		)
+	}
+	return env
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`!*?[]{}()<>|&;#~") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
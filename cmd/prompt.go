@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutunak/jcli/internal/cache"
+	"github.com/tutunak/jcli/internal/config"
+	"github.com/tutunak/jcli/internal/prompt"
+	"github.com/tutunak/jcli/internal/state"
+)
+
+func newPromptCmd() *cobra.Command {
+	var (
+		global     bool
+		format     string
+		maxSummary int
+	)
+	cmd := &cobra.Command{
+		Use:   "prompt",
+		Short: "Print the current issue for a shell prompt (offline)",
+		Long: `Print the selected issue for a shell prompt segment, or nothing when no
+issue is selected.
+
+prompt only reads the state file and the local cache: it never contacts Jira,
+doesn't load the rest of the configuration and doesn't start git. Status and
+type are as of the last 'issue select' or 'issue list' that returned the
+issue, and are empty when it wasn't seen in one. Issues detected from the git
+branch (git.detect_issue) are not shown.
+
+The format may use {key}, {summary}, {status}, {type} and {profile}.`,
+		Example: `  jcli prompt
+  jcli prompt --format '{key} ({status})'
+  jcli prompt snippet starship >> ~/.config/starship.toml`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executePrompt(format, maxSummary, global)
+		},
+	}
+	addGlobalFlag(cmd, &global)
+	cmd.Flags().StringVar(&format, "format", prompt.DefaultFormat, "format of the segment")
+	cmd.Flags().IntVar(&maxSummary, "max-summary", 30, "shorten {summary} to this many characters (0 for no limit)")
+
+	cmd.AddCommand(&cobra.Command{
+		Use:       "snippet <framework>",
+		Short:     "Print the prompt configuration for a framework or shell",
+		Long:      "Print a snippet that adds the current issue to the prompt of starship, powerlevel10k, bash, zsh or fish.",
+		Args:      exactArgs(1, "framework required: "+strings.Join(prompt.SnippetNames(), ", ")),
+		ValidArgs: prompt.SnippetNames(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snippet, err := prompt.Snippet(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Print(snippet)
+			return nil
+		},
+	})
+	return cmd
+}
+
+func executePrompt(format string, maxSummary int, global bool) error {
+	profile, err := config.ProfileName()
+	if err != nil {
+		return err
+	}
+	state.SetProfile(profile)
+	cache.SetProfile(profile)
+
+	scope := ""
+	if !global {
+		if scope, err = state.QuickScopeFor(""); err != nil {
+			return fmt.Errorf("failed to determine issue scope: %w", err)
+		}
+	}
+
+	st, err := state.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	issue := st.CurrentIssueFor(scope)
+	if issue == nil {
+		return nil
+	}
+
+	info := prompt.Info{Key: issue.Key, Summary: issue.Summary, Profile: profile}
+	if cached, ok := cache.Load().Issue(issue.Key); ok {
+		info.Status = cached.Status
+		info.Type = cached.Type
+	}
+	fmt.Println(prompt.Render(format, info, maxSummary))
+	return nil
+}
@@ -3,6 +3,19 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/tutunak/jcli/internal/cache"
+	"github.com/tutunak/jcli/internal/config"
+	"github.com/tutunak/jcli/internal/credentials"
+	"github.com/tutunak/jcli/internal/output"
+	"github.com/tutunak/jcli/internal/state"
+	"github.com/tutunak/jcli/internal/tui"
 )
 
 var version = "dev"
@@ -11,51 +24,250 @@ func SetVersion(v string) {
 	version = v
 }
 
+// globalOptions are the flags accepted by every command.
+type globalOptions struct {
+	project    string
+	status     string
+	configPath string
+	profile    string
+	output     string
+	template   string
+	noColor    bool
+	quiet      bool
+	debug      debugOptions
+}
+
+var globals globalOptions
+
+// printer renders the documents of read commands in the --output format.
+var printer output.Printer
+
+// commandStarted is set when the RunE of a command starts. Errors cobra
+// returns before that are usage errors.
+var commandStarted bool
+
+// Execute runs the command line. Its errors are classified by ExitCode.
 func Execute() error {
-	if len(os.Args) < 2 {
-		printUsage()
-		return nil
-	}
-
-	switch os.Args[1] {
-	case "version", "--version", "-v":
-		fmt.Printf("jcli version %s\n", version)
-		return nil
-	case "help", "--help", "-h":
-		printUsage()
-		return nil
-	case "issue":
-		return executeIssue(os.Args[2:])
-	case "config":
-		return executeConfig(os.Args[2:])
-	default:
-		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", os.Args[1])
-		printUsage()
-		return fmt.Errorf("unknown command: %s", os.Args[1])
-	}
-}
-
-func printUsage() {
-	fmt.Println(`jcli - Jira CLI workflow management tool
-
-Usage:
-  jcli <command> [subcommand] [flags]
-
-Commands:
-  issue     Manage Jira issues
-  config    Configure jcli settings
-  version   Print version information
-  help      Show this help message
-
-Issue Commands:
-  jcli issue select [issue-id]   Select an issue (interactive or by ID)
-  jcli issue current             Show current active issue
-  jcli issue branch              Generate branch name for current issue
-
-Config Commands:
-  jcli config project <key>     Set default project
-  jcli config status <name>     Set default status filter
-  jcli config credentials       Set Jira credentials (interactive)
-
-Use "jcli <command> --help" for more information about a command.`)
+	globals = globalOptions{}
+	commandStarted = false
+	defer closeDebug()
+
+	root := newRootCmd()
+	// Global flags before an alias or plugin name apply to it, so --config
+	// and --profile are in effect before aliases are read.
+	flags, args := splitGlobalFlags(root, os.Args[1:])
+	if len(flags) > 0 && root.PersistentFlags().Parse(flags) == nil {
+		applyConfigOverrides()
+	}
+	exp, err := expandAliases(root, args)
+	if err != nil {
+		return err
+	}
+	args = exp.Args
+	if exp.Shell != "" {
+		return runShellAlias(exp.Chain[len(exp.Chain)-1], exp.Shell, args)
+	}
+	if path, ok := findPlugin(root, args); ok {
+		return runPlugin(path, args[1:])
+	}
+	root.SetArgs(append(flags, args...))
+	c, err := root.ExecuteC()
+	if err != nil && !commandStarted && ExitCode(err) == ExitFailure {
+		return &UsageError{Err: err, Command: c.CommandPath()}
+	}
+	return err
+}
+
+// splitGlobalFlags splits the persistent flags of root, with their values,
+// off the start of args. It stops at the first argument that is not one,
+// leaving combined short flags and unknown flags to cobra.
+func splitGlobalFlags(root *cobra.Command, args []string) (flags, rest []string) {
+	fs := root.PersistentFlags()
+	i := 0
+	for i < len(args) {
+		arg := args[i]
+		if arg == "-" || arg == "--" || !strings.HasPrefix(arg, "-") {
+			break
+		}
+		var flag *pflag.Flag
+		var inline bool
+		if name, ok := strings.CutPrefix(arg, "--"); ok {
+			name, _, inline = strings.Cut(name, "=")
+			flag = fs.Lookup(name)
+		} else {
+			flag = fs.ShorthandLookup(arg[1:2])
+			inline = len(arg) > 2
+			if flag != nil && inline && flag.NoOptDefVal != "" {
+				break
+			}
+		}
+		if flag == nil {
+			break
+		}
+		i++
+		if !inline && flag.NoOptDefVal == "" {
+			i++
+		}
+	}
+	i = min(i, len(args))
+	return args[:i], args[i:]
+}
+
+// trackStart marks in commandStarted when the commands of c start running.
+func trackStart(c *cobra.Command) {
+	if run := c.RunE; run != nil {
+		c.RunE = func(cmd *cobra.Command, args []string) error {
+			commandStarted = true
+			return run(cmd, args)
+		}
+	}
+	for _, sub := range c.Commands() {
+		trackStart(sub)
+	}
+}
+
+func newRootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:   "jcli",
+		Short: "Jira CLI workflow management tool",
+		Long: `jcli - Jira CLI workflow management tool
+
+Select the Jira issue you are working on, generate git branch names for it
+and switch between recent issues. Run 'jcli init' to set it up.
+
+Aliases from the aliases section of config.yaml expand before dispatch, and
+executables named jcli-<name> on PATH run as 'jcli <name>' (see 'jcli plugin').`,
+		Version:           version,
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+		PersistentPreRunE: applyGlobals,
+		ValidArgsFunction: completePlugins,
+	}
+	root.SetVersionTemplate("jcli version {{.Version}}\n")
+	// Added up front so that they aren't mistaken for plugins.
+	root.InitDefaultHelpCmd()
+	root.InitDefaultCompletionCmd()
+
+	flags := root.PersistentFlags()
+	flags.StringVarP(&globals.project, "project", "p", "", "Jira project key for this invocation (or set JIRA_PROJECT)")
+	flags.StringVarP(&globals.status, "status", "s", "", "status filter for this invocation (or set JIRA_STATUS)")
+	flags.StringVar(&globals.configPath, "config", "", "config file to use (or set JCLI_CONFIG)")
+	flags.StringVar(&globals.profile, "profile", "", "use the named profile (or set JCLI_PROFILE)")
+	flags.StringVarP(&globals.output, "output", "o", string(output.Text), "output format: "+formatNames())
+	flags.StringVar(&globals.template, "template", "", "format the output data with a Go template")
+	flags.BoolVar(&globals.noColor, "no-color", false, "disable colors (or set NO_COLOR)")
+	flags.BoolVarP(&globals.quiet, "quiet", "q", false, "print only requested data, no confirmations")
+	flags.BoolVar(&globals.debug.enabled, "debug", false, "trace HTTP requests to stderr (or set JCLI_DEBUG=1)")
+	flags.BoolVar(&globals.debug.bodies, "debug-body", false, "include request and response bodies (or set JCLI_DEBUG=body)")
+	flags.StringVar(&globals.debug.file, "debug-file", "", "append the HTTP trace to a file (or set JCLI_DEBUG_FILE)")
+
+	root.AddCommand(
+		newInitCmd(),
+		newIssueCmd(),
+		newConfigCmd(),
+		newAuthCmd(),
+		newDoctorCmd(),
+		newPromptCmd(),
+		newPluginCmd(),
+		newAPICmd(),
+		newVersionCmd(),
+	)
+	trackStart(root)
+
+	root.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(formatValues(), cobra.ShellCompDirectiveNoFileComp))
+	root.RegisterFlagCompletionFunc("profile", completeProfiles)
+	root.RegisterFlagCompletionFunc("status", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeStatuses(toComplete)
+	})
+	root.RegisterFlagCompletionFunc("config", cobra.FixedCompletions([]string{"yaml", "yml"}, cobra.ShellCompDirectiveFilterFileExt))
+	return root
+}
+
+// applyGlobals runs before every command and applies the global flags.
+func applyGlobals(cmd *cobra.Command, args []string) error {
+	format, err := output.ParseFormat(globals.output)
+	if err != nil {
+		return err
+	}
+	printer = output.Printer{Format: format, Out: os.Stdout}
+	if globals.template != "" {
+		if printer.Template, err = output.ParseTemplate(globals.template); err != nil {
+			return err
+		}
+	}
+
+	applyConfigOverrides()
+
+	if globals.noColor || os.Getenv("NO_COLOR") != "" {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+	credentials.Prompt = tui.NewSelector().PromptPassphrase
+
+	return setupDebug(globals.debug)
+}
+
+// applyConfigOverrides passes the config flags to the config package.
+func applyConfigOverrides() {
+	config.SetProfileOverride(globals.profile)
+	config.SetPathOverride(globals.configPath)
+	config.SetFlagOverrides(config.FlagOverrides{
+		Project: globals.project,
+		Status:  globals.status,
+	})
+}
+
+func newVersionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print version information",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if printer.Structured() {
+				return printer.Print(&output.Version{Version: version, SchemaVersion: output.SchemaVersion})
+			}
+			fmt.Printf("jcli version %s\n", version)
+			return nil
+		},
+	}
+}
+
+// groupRunE is the RunE of commands that only hold subcommands. It shows
+// the help, or fails for an unknown subcommand.
+func groupRunE(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 || args[0] == "help" {
+		return cmd.Help()
+	}
+	return &UsageError{Err: fmt.Errorf("unknown %s command: %s", cmd.Name(), args[0]), Command: cmd.CommandPath()}
+}
+
+// loadConfig loads the configuration and points state and cache at the
+// active profile, so that every command reads and writes that profile's
+// files.
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	state.SetProfile(cfg.ActiveProfile())
+	cache.SetProfile(cfg.ActiveProfile())
+	return cfg, nil
+}
+
+// infof prints a confirmation message unless --quiet is set.
+func infof(format string, a ...any) {
+	if !globals.quiet {
+		fmt.Printf(format, a...)
+	}
+}
+
+func formatNames() string {
+	return strings.Join(formatValues(), ", ")
+}
+
+func formatValues() []string {
+	names := make([]string, len(output.Formats))
+	for i, f := range output.Formats {
+		names[i] = string(f)
+	}
+	return names
 }
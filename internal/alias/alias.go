@@ -0,0 +1,164 @@
+// Package alias expands the user-defined command aliases of config.yaml.
+//
+// An alias is a command line that replaces its name: with
+//
+//	aliases:
+//	  mine: issue list --query mine
+//	  co: issue select $1 --global
+//	  sync: "!git fetch && jcli issue current"
+//
+// "jcli co PROJ-1" runs "jcli issue select PROJ-1 --global". $1, $2, ...
+// are replaced by the arguments after the alias name and $@ by all of
+// them; arguments not referenced are appended. Aliases starting with "!"
+// are run by the shell, which sees the arguments as $1, $2, ...
+package alias
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Expansion is the result of expanding a command line.
+type Expansion struct {
+	// Args is the expanded command line, or the arguments of Shell.
+	Args []string
+	// Shell is the command of a "!" alias, to be run by the shell with Args.
+	Shell string
+	// Chain lists the aliases that were expanded, in order.
+	Chain []string
+}
+
+// Expand expands args while args[0] names an alias. isCommand reports
+// built-in commands, which cannot be overridden by aliases. Aliases may
+// refer to other aliases; a loop is an error.
+func Expand(aliases map[string]string, args []string, isCommand func(string) bool) (Expansion, error) {
+	exp := Expansion{Args: args}
+	for len(exp.Args) > 0 && !isCommand(exp.Args[0]) {
+		name := exp.Args[0]
+		definition, ok := aliases[name]
+		if !ok {
+			break
+		}
+		if slices.Contains(exp.Chain, name) {
+			return Expansion{}, fmt.Errorf("alias loop: %s -> %s", strings.Join(exp.Chain, " -> "), name)
+		}
+		exp.Chain = append(exp.Chain, name)
+
+		if shell, ok := strings.CutPrefix(definition, "!"); ok {
+			exp.Shell = shell
+			exp.Args = exp.Args[1:]
+			return exp, nil
+		}
+
+		words, err := Split(definition)
+		if err != nil {
+			return Expansion{}, fmt.Errorf("invalid alias %q: %w", name, err)
+		}
+		if exp.Args, err = substitute(words, exp.Args[1:]); err != nil {
+			return Expansion{}, fmt.Errorf("alias %q: %w", name, err)
+		}
+	}
+	return exp, nil
+}
+
+var placeholder = regexp.MustCompile(`\$(\d+|@)`)
+
+// substitute replaces the placeholders in words with args. A word that is
+// exactly $@ becomes one word per argument.
+func substitute(words, args []string) ([]string, error) {
+	used, all := 0, false
+	var out []string
+	var missing int
+	for _, word := range words {
+		if word == "$@" {
+			all = true
+			out = append(out, args...)
+			continue
+		}
+		word = placeholder.ReplaceAllStringFunc(word, func(m string) string {
+			if m == "$@" {
+				all = true
+				return strings.Join(args, " ")
+			}
+			n, _ := strconv.Atoi(m[1:])
+			if n == 0 {
+				return m
+			}
+			used = max(used, n)
+			if n > len(args) {
+				missing = max(missing, n)
+				return ""
+			}
+			return args[n-1]
+		})
+		out = append(out, word)
+	}
+	if missing > 0 {
+		return nil, fmt.Errorf("needs at least %d argument(s), got %d", missing, len(args))
+	}
+	if !all {
+		out = append(out, args[used:]...)
+	}
+	return out, nil
+}
+
+// Split splits a command line into words like a POSIX shell, honouring
+// single and double quotes and backslash escapes. It doesn't expand
+// variables or globs.
+func Split(line string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range line {
+		switch {
+		case escaped:
+			if quote == '"' && !strings.ContainsRune(`"\$`+"`", r) {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inWord = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,85 @@
+package alias
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{line: "issue list --query mine", want: []string{"issue", "list", "--query", "mine"}},
+		{line: `issue select --transition 'In Progress'`, want: []string{"issue", "select", "--transition", "In Progress"}},
+		{line: `a "b \"c\" \d" e\ f ''`, want: []string{"a", `b "c" \d`, "e f", ""}},
+		{line: "  ", want: nil},
+	}
+	for _, tt := range tests {
+		got, err := Split(tt.line)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("Split(%q) = %q, %v, want %q", tt.line, got, err, tt.want)
+		}
+	}
+
+	for _, line := range []string{`a 'b`, `a "b`, `a\`} {
+		if _, err := Split(line); err == nil {
+			t.Errorf("Split(%q) should fail", line)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	aliases := map[string]string{
+		"mine":   "issue list --query mine",
+		"co":     "issue select $1 --global",
+		"jql":    "issue list --jql 'project = $1 AND status = \"$2\"'",
+		"all":    "issue list $@ --limit 5",
+		"m":      "mine",
+		"sync":   "!git fetch && jcli issue current",
+		"issue":  "version",
+		"loop-a": "loop-b x",
+		"loop-b": "loop-a",
+	}
+	isCommand := func(name string) bool { return name == "issue" || name == "version" }
+
+	tests := []struct {
+		args  []string
+		want  []string
+		chain []string
+	}{
+		{args: []string{"mine", "-o", "json"}, want: []string{"issue", "list", "--query", "mine", "-o", "json"}, chain: []string{"mine"}},
+		{args: []string{"co", "PROJ-1", "-q"}, want: []string{"issue", "select", "PROJ-1", "--global", "-q"}, chain: []string{"co"}},
+		{args: []string{"jql", "PROJ", "In Progress"}, want: []string{"issue", "list", "--jql", `project = PROJ AND status = "In Progress"`}, chain: []string{"jql"}},
+		{args: []string{"all", "--sort", "key"}, want: []string{"issue", "list", "--sort", "key", "--limit", "5"}, chain: []string{"all"}},
+		{args: []string{"m"}, want: []string{"issue", "list", "--query", "mine"}, chain: []string{"m", "mine"}},
+		{args: []string{"issue", "current"}, want: []string{"issue", "current"}},
+		{args: []string{"unknown"}, want: []string{"unknown"}},
+		{args: nil, want: nil},
+	}
+	for _, tt := range tests {
+		exp, err := Expand(aliases, tt.args, isCommand)
+		if err != nil {
+			t.Fatalf("Expand(%q) error = %v", tt.args, err)
+		}
+		if !slices.Equal(exp.Args, tt.want) || !slices.Equal(exp.Chain, tt.chain) || exp.Shell != "" {
+			t.Errorf("Expand(%q) = %+v, want args %q and chain %q", tt.args, exp, tt.want, tt.chain)
+		}
+	}
+
+	exp, err := Expand(aliases, []string{"sync", "a b"}, isCommand)
+	if err != nil || exp.Shell != "git fetch && jcli issue current" || !slices.Equal(exp.Args, []string{"a b"}) {
+		t.Errorf("shell alias = %+v, %v", exp, err)
+	}
+
+	if _, err := Expand(aliases, []string{"loop-a"}, isCommand); err == nil || !strings.Contains(err.Error(), "alias loop: loop-a -> loop-b -> loop-a") {
+		t.Errorf("expected alias loop error, got %v", err)
+	}
+	if _, err := Expand(aliases, []string{"jql", "PROJ"}, isCommand); err == nil || !strings.Contains(err.Error(), "needs at least 2 argument(s), got 1") {
+		t.Errorf("expected missing argument error, got %v", err)
+	}
+	if _, err := Expand(map[string]string{"bad": "issue 'x"}, []string{"bad"}, isCommand); err == nil || !strings.Contains(err.Error(), "unterminated") {
+		t.Errorf("expected invalid alias error, got %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+// Package api builds the requests of "jcli api" from command-line fields.
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Field is a key=value pair given with -f or -F.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// ParseField parses "key=value". Typed fields (-F) convert true, false,
+// null and numbers to JSON values and read the content of @file, or of
+// stdin for @-; other fields (-f) are strings.
+func ParseField(s string, typed bool, stdin io.Reader) (Field, error) {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return Field{}, fmt.Errorf("invalid field %q: expected key=value", s)
+	}
+	if _, err := keyPath(key); err != nil {
+		return Field{}, err
+	}
+	if !typed {
+		return Field{Key: key, Value: value}, nil
+	}
+
+	switch value {
+	case "true":
+		return Field{Key: key, Value: true}, nil
+	case "false":
+		return Field{Key: key, Value: false}, nil
+	case "null":
+		return Field{Key: key, Value: nil}, nil
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return Field{Key: key, Value: json.Number(value)}, nil
+	}
+	if path, ok := strings.CutPrefix(value, "@"); ok {
+		var data []byte
+		var err error
+		if path == "-" {
+			data, err = io.ReadAll(stdin)
+		} else {
+			data, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return Field{}, fmt.Errorf("failed to read field %s: %w", key, err)
+		}
+		return Field{Key: key, Value: string(data)}, nil
+	}
+	return Field{Key: key, Value: value}, nil
+}
+
+// Body returns the fields as a JSON object. A key like fields[summary]
+// sets a nested field and labels[] appends to an array.
+func Body(fields []Field) ([]byte, error) {
+	obj := make(map[string]any)
+	for _, f := range fields {
+		parts, err := keyPath(f.Key)
+		if err != nil {
+			return nil, err
+		}
+		if err := set(obj, parts, f.Value, f.Key); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(obj)
+}
+
+// AddQuery appends the fields to the query string of path. A key given
+// several times adds several parameters.
+func AddQuery(path string, fields []Field) (string, error) {
+	if len(fields) == 0 {
+		return path, nil
+	}
+	base, rawQuery, _ := strings.Cut(path, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", fmt.Errorf("invalid query in %q: %w", path, err)
+	}
+	for _, f := range fields {
+		value := ""
+		switch v := f.Value.(type) {
+		case nil:
+		case string:
+			value = v
+		default:
+			value = fmt.Sprint(v)
+		}
+		query.Add(f.Key, value)
+	}
+	return base + "?" + query.Encode(), nil
+}
+
+// keyPath splits a[b][] into a, b and "" for an append.
+func keyPath(key string) ([]string, error) {
+	name, rest, nested := strings.Cut(key, "[")
+	if name == "" {
+		return nil, fmt.Errorf("invalid field key %q", key)
+	}
+	parts := []string{name}
+	for nested {
+		var part string
+		var ok bool
+		if part, rest, ok = strings.Cut(rest, "]"); !ok {
+			return nil, fmt.Errorf("invalid field key %q: missing ]", key)
+		}
+		if part == "" && rest != "" {
+			return nil, fmt.Errorf("invalid field key %q: [] must come last", key)
+		}
+		parts = append(parts, part)
+		if rest == "" {
+			break
+		}
+		if !strings.HasPrefix(rest, "[") {
+			return nil, fmt.Errorf("invalid field key %q", key)
+		}
+		rest = rest[1:]
+	}
+	return parts, nil
+}
+
+func set(obj map[string]any, parts []string, value any, key string) error {
+	name, rest := parts[0], parts[1:]
+	switch {
+	case len(rest) == 0:
+		obj[name] = value
+	case rest[0] == "":
+		arr, ok := obj[name].([]any)
+		if !ok && obj[name] != nil {
+			return fmt.Errorf("field %s: %s is not an array", key, name)
+		}
+		obj[name] = append(arr, value)
+	default:
+		sub, ok := obj[name].(map[string]any)
+		if !ok {
+			if obj[name] != nil {
+				return fmt.Errorf("field %s: %s is not an object", key, name)
+			}
+			sub = make(map[string]any)
+			obj[name] = sub
+		}
+		return set(sub, rest, value, key)
+	}
+	return nil
+}
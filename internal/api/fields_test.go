@@ -0,0 +1,96 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseField(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "body.txt")
+	if err := os.WriteFile(file, []byte("from file"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		arg   string
+		typed bool
+		want  any
+	}{
+		{arg: "summary=Fix it", want: "Fix it"},
+		{arg: "n=5", want: "5"},
+		{arg: "expr=a=b", want: "a=b"},
+		{arg: "n=5", typed: true, want: "5"},
+		{arg: "ok=true", typed: true, want: true},
+		{arg: "ok=false", typed: true, want: false},
+		{arg: "x=null", typed: true, want: nil},
+		{arg: "body=@" + file, typed: true, want: "from file"},
+		{arg: "body=@-", typed: true, want: "from stdin"},
+		{arg: "name=text", typed: true, want: "text"},
+	}
+	for _, tt := range tests {
+		f, err := ParseField(tt.arg, tt.typed, strings.NewReader("from stdin"))
+		if err != nil {
+			t.Fatalf("ParseField(%q) error = %v", tt.arg, err)
+		}
+		got := f.Value
+		if s, ok := got.(interface{ String() string }); ok {
+			got = s.String()
+		}
+		if got != tt.want {
+			t.Errorf("ParseField(%q) = %#v, want %#v", tt.arg, f.Value, tt.want)
+		}
+	}
+
+	for _, arg := range []string{"novalue", "=x", "a[b=1", "a[]b=1", "a[][b]=1"} {
+		if _, err := ParseField(arg, false, nil); err == nil {
+			t.Errorf("ParseField(%q) should fail", arg)
+		}
+	}
+}
+
+func TestBody(t *testing.T) {
+	var fields []Field
+	for _, arg := range []string{"fields[summary]=Fix it", "fields[project][key]=TEST", "fields[labels][]=a", "fields[labels][]=b", "notify=false", "update[x]=null"} {
+		f, err := ParseField(arg, true, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fields = append(fields, f)
+	}
+	body, err := Body(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"fields":{"labels":["a","b"],"project":{"key":"TEST"},"summary":"Fix it"},"notify":false,"update":{"x":null}}`
+	if string(body) != want {
+		t.Errorf("Body() = %s, want %s", body, want)
+	}
+
+	if _, err := Body([]Field{{Key: "a", Value: "x"}, {Key: "a[b]", Value: "y"}}); err == nil {
+		t.Error("expected an error for a nested field of a string")
+	}
+	if _, err := Body([]Field{{Key: "a", Value: "x"}, {Key: "a[]", Value: "y"}}); err == nil {
+		t.Error("expected an error for appending to a string")
+	}
+}
+
+func TestAddQuery(t *testing.T) {
+	tests := []struct {
+		path   string
+		fields []Field
+		want   string
+	}{
+		{path: "myself", want: "myself"},
+		{path: "search/jql", fields: []Field{{Key: "jql", Value: "project = TEST"}, {Key: "maxResults", Value: "5"}}, want: "search/jql?jql=project+%3D+TEST&maxResults=5"},
+		{path: "issue/TEST-1?expand=names", fields: []Field{{Key: "expand", Value: "changelog"}}, want: "issue/TEST-1?expand=names&expand=changelog"},
+		{path: "x", fields: []Field{{Key: "a", Value: true}, {Key: "b", Value: nil}}, want: "x?a=true&b="},
+	}
+	for _, tt := range tests {
+		got, err := AddQuery(tt.path, tt.fields)
+		if err != nil || got != tt.want {
+			t.Errorf("AddQuery(%q) = %q, %v, want %q", tt.path, got, err, tt.want)
+		}
+	}
+}
@@ -12,8 +12,13 @@ var (
 	multipleHyphens = regexp.MustCompile(`-+`)
 )
 
+// DefaultTemplate produces <KEY>-<normalized-summary>-<random-number>.
+// Templates may use the {key}, {summary} and {random} placeholders.
+const DefaultTemplate = "{key}-{summary}-{random}"
+
 type Generator struct {
 	randFunc func() int
+	template string
 }
 
 func NewGenerator() *Generator {
@@ -30,10 +35,25 @@ func NewGeneratorWithRand(randFunc func() int) *Generator {
 	}
 }
 
+// SetTemplate changes the branch name format; an empty template restores
+// DefaultTemplate.
+func (g *Generator) SetTemplate(template string) {
+	g.template = template
+}
+
 func (g *Generator) Generate(issueKey, summary string) string {
+	template := g.template
+	if template == "" {
+		template = DefaultTemplate
+	}
+
 	normalized := normalizeSummary(summary)
 	randomNum := g.randFunc()
-	return issueKey + "-" + normalized + "-" + formatNumber(randomNum)
+	return strings.NewReplacer(
+		"{key}", issueKey,
+		"{summary}", normalized,
+		"{random}", formatNumber(randomNum),
+	).Replace(template)
 }
 
 func normalizeSummary(summary string) string {
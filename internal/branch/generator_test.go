@@ -57,6 +57,29 @@ func TestGenerator_Generate(t *testing.T) {
 	}
 }
 
+func TestGenerator_SetTemplate(t *testing.T) {
+	gen := NewGeneratorWithRand(func() int { return 42 })
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"", "PROJ-1-add-login-42"},
+		{"feature/{key}-{summary}", "feature/PROJ-1-add-login"},
+		{"{key}", "PROJ-1"},
+		{"{random}/{key}", "42/PROJ-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			gen.SetTemplate(tt.template)
+			if got := gen.Generate("PROJ-1", "Add login"); got != tt.want {
+				t.Errorf("Generate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestGenerator_GenerateWithRandomNumber(t *testing.T) {
 	gen := NewGenerator()
 	branch := gen.Generate("TEST-1", "Test")
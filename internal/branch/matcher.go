@@ -0,0 +1,67 @@
+package branch
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// genericKeyPattern matches any Jira-style issue key when no project keys are known.
+const genericKeyPattern = `[A-Z][A-Z0-9_]+-[0-9]+`
+
+type KeyMatcher struct {
+	re *regexp.Regexp
+}
+
+// NewKeyMatcher builds a matcher for issue keys in branch names. A custom
+// pattern takes precedence; it may use a named group "key" (or its first
+// group) to select the key. Otherwise the pattern is derived from the
+// project keys, falling back to a generic key pattern.
+func NewKeyMatcher(pattern string, projectKeys []string) (*KeyMatcher, error) {
+	if pattern == "" {
+		pattern = defaultKeyPattern(projectKeys)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issue pattern %q: %w", pattern, err)
+	}
+
+	return &KeyMatcher{re: re}, nil
+}
+
+func defaultKeyPattern(projectKeys []string) string {
+	var keys []string
+	for _, key := range projectKeys {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, regexp.QuoteMeta(key))
+		}
+	}
+
+	// Keys must not be glued to a preceding letter or digit, so that
+	// "XPROJ-1" does not match project PROJ.
+	if len(keys) == 0 {
+		return `(?:^|[^A-Za-z0-9])(?P<key>` + genericKeyPattern + `)`
+	}
+	return `(?i)(?:^|[^a-z0-9])(?P<key>(?:` + strings.Join(keys, "|") + `)-[0-9]+)`
+}
+
+// Match returns the first issue key found in the branch name.
+func (m *KeyMatcher) Match(branchName string) (string, bool) {
+	match := m.re.FindStringSubmatch(branchName)
+	if match == nil {
+		return "", false
+	}
+
+	key := match[0]
+	if idx := m.re.SubexpIndex("key"); idx > 0 {
+		key = match[idx]
+	} else if len(match) > 1 {
+		key = match[1]
+	}
+
+	if key == "" {
+		return "", false
+	}
+	return strings.ToUpper(key), true
+}
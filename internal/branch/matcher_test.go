@@ -0,0 +1,99 @@
+package branch
+
+import "testing"
+
+func TestKeyMatcher_Match(t *testing.T) {
+	tests := []struct {
+		name        string
+		pattern     string
+		projectKeys []string
+		branch      string
+		want        string
+		wantOK      bool
+	}{
+		{
+			name:   "generated branch name",
+			branch: "PROJ-123-add-user-authentication-847291",
+			want:   "PROJ-123",
+			wantOK: true,
+		},
+		{
+			name:   "key after prefix",
+			branch: "feature/DEV-42-fix-login",
+			want:   "DEV-42",
+			wantOK: true,
+		},
+		{
+			name:   "no key",
+			branch: "main",
+			wantOK: false,
+		},
+		{
+			name:   "lowercase key ignored without project keys",
+			branch: "feature/proj-1-thing",
+			wantOK: false,
+		},
+		{
+			name:        "lowercase key with project keys",
+			projectKeys: []string{"PROJ"},
+			branch:      "feature/proj-1-thing",
+			want:        "PROJ-1",
+			wantOK:      true,
+		},
+		{
+			name:        "unknown project ignored",
+			projectKeys: []string{"PROJ", "OPS"},
+			branch:      "release-2-OTHER-7",
+			wantOK:      false,
+		},
+		{
+			name:        "project key must not be glued to other letters",
+			projectKeys: []string{"PROJ"},
+			branch:      "XPROJ-1",
+			wantOK:      false,
+		},
+		{
+			name:        "second project key",
+			projectKeys: []string{"PROJ", "OPS"},
+			branch:      "hotfix/OPS-9",
+			want:        "OPS-9",
+			wantOK:      true,
+		},
+		{
+			name:    "custom pattern with named group",
+			pattern: `^jira/(?P<key>[A-Z]+-[0-9]+)/`,
+			branch:  "jira/ABC-5/something",
+			want:    "ABC-5",
+			wantOK:  true,
+		},
+		{
+			name:    "custom pattern without group",
+			pattern: `T-[0-9]+`,
+			branch:  "wip-T-77",
+			want:    "T-77",
+			wantOK:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewKeyMatcher(tt.pattern, tt.projectKeys)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, ok := m.Match(tt.branch)
+			if ok != tt.wantOK {
+				t.Fatalf("Match(%q) ok = %v, want %v", tt.branch, ok, tt.wantOK)
+			}
+			if got != tt.want {
+				t.Errorf("Match(%q) = %q, want %q", tt.branch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewKeyMatcher_InvalidPattern(t *testing.T) {
+	if _, err := NewKeyMatcher(`(`, nil); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
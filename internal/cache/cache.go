@@ -0,0 +1,170 @@
+// Package cache keeps the issues of recent searches and the statuses of
+// projects, so that shell completion works without contacting Jira.
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tutunak/jcli/internal/lockedfile"
+)
+
+// MaxIssues bounds the number of cached issues; the most recently seen
+// issues are kept.
+const MaxIssues = 500
+
+// StatusTTL is how long the statuses of a project are used before they are
+// fetched again.
+const StatusTTL = 24 * time.Hour
+
+// Issue is an issue as it was when last seen in a search result.
+type Issue struct {
+	Key     string `json:"key"`
+	Summary string `json:"summary"`
+	Status  string `json:"status,omitempty"`
+	Type    string `json:"type,omitempty"`
+}
+
+type Statuses struct {
+	Names     []string  `json:"names"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Cache is the content of the cache file. It can be deleted at any time.
+type Cache struct {
+	// Issues lists the issues seen in search results, newest first.
+	Issues []Issue `json:"issues,omitempty"`
+	// Statuses maps project keys to their statuses.
+	Statuses map[string]Statuses `json:"statuses,omitempty"`
+}
+
+func CacheDir() (string, error) {
+	if xdgCache := os.Getenv("XDG_CACHE_HOME"); xdgCache != "" {
+		return filepath.Join(xdgCache, "jcli"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".cache", "jcli"), nil
+}
+
+// profile selects the cache file, like the state file of the profile.
+var profile string
+
+func SetProfile(name string) {
+	if name == "default" {
+		name = ""
+	}
+	profile = name
+}
+
+func CachePath() (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	if profile != "" {
+		return filepath.Join(dir, "profiles", profile, "cache.json"), nil
+	}
+	return filepath.Join(dir, "cache.json"), nil
+}
+
+// Load reads the cache. A missing or unreadable cache is empty.
+func Load() *Cache {
+	c := &Cache{}
+	path, err := CachePath()
+	if err != nil {
+		return c
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return &Cache{}
+	}
+	return c
+}
+
+func (c *Cache) Save() error {
+	path, err := CachePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache: %w", err)
+	}
+	if err := lockedfile.Write(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	return nil
+}
+
+// AddIssues puts issues at the front of the cache, replacing older entries
+// of the same keys.
+func (c *Cache) AddIssues(issues []Issue) {
+	seen := make(map[string]bool, len(issues))
+	merged := make([]Issue, 0, len(issues)+len(c.Issues))
+	for _, list := range [][]Issue{issues, c.Issues} {
+		for _, issue := range list {
+			if issue.Key == "" || seen[issue.Key] {
+				continue
+			}
+			seen[issue.Key] = true
+			merged = append(merged, issue)
+		}
+	}
+	if len(merged) > MaxIssues {
+		merged = merged[:MaxIssues]
+	}
+	c.Issues = merged
+}
+
+// MatchIssues returns the cached issues whose key starts with prefix,
+// ignoring case.
+func (c *Cache) MatchIssues(prefix string) []Issue {
+	prefix = strings.ToUpper(prefix)
+	var matches []Issue
+	for _, issue := range c.Issues {
+		if strings.HasPrefix(strings.ToUpper(issue.Key), prefix) {
+			matches = append(matches, issue)
+		}
+	}
+	return matches
+}
+
+// Issue returns the cached issue with key.
+func (c *Cache) Issue(key string) (Issue, bool) {
+	for _, issue := range c.Issues {
+		if strings.EqualFold(issue.Key, key) {
+			return issue, true
+		}
+	}
+	return Issue{}, false
+}
+
+// ProjectStatuses returns the cached statuses of project, unless they are
+// older than StatusTTL.
+func (c *Cache) ProjectStatuses(project string, now time.Time) ([]string, bool) {
+	s, ok := c.Statuses[strings.ToUpper(project)]
+	if !ok || now.Sub(s.FetchedAt) > StatusTTL {
+		return nil, false
+	}
+	return s.Names, true
+}
+
+func (c *Cache) SetProjectStatuses(project string, names []string, now time.Time) {
+	if c.Statuses == nil {
+		c.Statuses = make(map[string]Statuses)
+	}
+	c.Statuses[strings.ToUpper(project)] = Statuses{Names: names, FetchedAt: now}
+}
@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache_AddIssues(t *testing.T) {
+	c := &Cache{}
+	c.AddIssues([]Issue{{Key: "PROJ-1", Summary: "old"}, {Key: "PROJ-2", Summary: "two"}})
+	c.AddIssues([]Issue{{Key: "PROJ-3", Summary: "three"}, {Key: "PROJ-1", Summary: "new"}, {Key: ""}})
+
+	want := []Issue{{Key: "PROJ-3", Summary: "three"}, {Key: "PROJ-1", Summary: "new"}, {Key: "PROJ-2", Summary: "two"}}
+	if fmt.Sprint(c.Issues) != fmt.Sprint(want) {
+		t.Errorf("Issues = %v, want %v", c.Issues, want)
+	}
+
+	many := make([]Issue, MaxIssues+10)
+	for i := range many {
+		many[i] = Issue{Key: fmt.Sprintf("BIG-%d", i)}
+	}
+	c.AddIssues(many)
+	if len(c.Issues) != MaxIssues || c.Issues[0].Key != "BIG-0" {
+		t.Errorf("expected %d newest issues, got %d starting with %v", MaxIssues, len(c.Issues), c.Issues[0])
+	}
+}
+
+func TestCache_MatchIssues(t *testing.T) {
+	c := &Cache{Issues: []Issue{{Key: "PROJ-12"}, {Key: "OPS-1"}, {Key: "PROJ-1"}}}
+
+	tests := []struct {
+		prefix string
+		want   int
+	}{
+		{prefix: "", want: 3},
+		{prefix: "proj-1", want: 2},
+		{prefix: "OPS", want: 1},
+		{prefix: "X", want: 0},
+	}
+	for _, tt := range tests {
+		if got := c.MatchIssues(tt.prefix); len(got) != tt.want {
+			t.Errorf("MatchIssues(%q) = %v, want %d issues", tt.prefix, got, tt.want)
+		}
+	}
+
+	if issue, ok := c.Issue("ops-1"); !ok || issue.Key != "OPS-1" {
+		t.Errorf("Issue(ops-1) = %v, %v", issue, ok)
+	}
+	if _, ok := c.Issue("OPS-2"); ok {
+		t.Error("expected OPS-2 not to be cached")
+	}
+}
+
+func TestCache_ProjectStatuses(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	c := &Cache{}
+	if _, ok := c.ProjectStatuses("PROJ", now); ok {
+		t.Fatal("expected no statuses in an empty cache")
+	}
+
+	c.SetProjectStatuses("proj", []string{"To Do", "Done"}, now)
+	if names, ok := c.ProjectStatuses("PROJ", now.Add(time.Hour)); !ok || len(names) != 2 {
+		t.Errorf("ProjectStatuses() = %v, %v", names, ok)
+	}
+	if _, ok := c.ProjectStatuses("PROJ", now.Add(StatusTTL+time.Minute)); ok {
+		t.Error("expected expired statuses to be ignored")
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", dir)
+	defer SetProfile("")
+
+	if c := Load(); len(c.Issues) != 0 {
+		t.Fatalf("expected empty cache, got %+v", c)
+	}
+
+	c := &Cache{}
+	c.AddIssues([]Issue{{Key: "PROJ-1", Summary: "one"}})
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if got := Load(); len(got.Issues) != 1 || got.Issues[0].Summary != "one" {
+		t.Errorf("Load() = %+v", got)
+	}
+
+	SetProfile("work")
+	path, _ := CachePath()
+	if want := filepath.Join(dir, "jcli", "profiles", "work", "cache.json"); path != want {
+		t.Errorf("CachePath() = %s, want %s", path, want)
+	}
+	if got := Load(); len(got.Issues) != 0 {
+		t.Errorf("expected profiles to have separate caches, got %+v", got)
+	}
+}
@@ -0,0 +1,14 @@
+package config
+
+// Aliases returns the aliases section of config.yaml. Only the user config
+// defines aliases, so it is read without loading the rest of the
+// configuration.
+func Aliases() (map[string]string, error) {
+	var doc struct {
+		Aliases map[string]string `yaml:"aliases"`
+	}
+	if err := readConfigFile(&doc); err != nil {
+		return nil, err
+	}
+	return doc.Aliases, nil
+}
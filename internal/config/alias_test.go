@@ -0,0 +1,25 @@
+package config
+
+import "testing"
+
+func TestAliases(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if aliases, err := Aliases(); err != nil || len(aliases) != 0 {
+		t.Fatalf("Aliases() without config = %v, %v", aliases, err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.Aliases = map[string]string{"mine": "issue list --query mine", "build": "!make"}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	aliases, err := Aliases()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if aliases["mine"] != "issue list --query mine" || aliases["build"] != "!make" {
+		t.Errorf("Aliases() = %v", aliases)
+	}
+}
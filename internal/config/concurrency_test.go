@@ -0,0 +1,156 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"testing"
+)
+
+// hammer saves and loads the config repeatedly, failing on any error. Every
+// Load must parse: a torn write would surface as a YAML error.
+func hammer(id string, rounds int) error {
+	for i := 0; i < rounds; i++ {
+		cfg := DefaultConfig()
+		cfg.Jira.URL = "https://example.atlassian.net"
+		cfg.Defaults.Project = fmt.Sprintf("%s%d", id, i)
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("save: %w", err)
+		}
+		loaded, err := Load()
+		if err != nil {
+			return fmt.Errorf("load: %w", err)
+		}
+		if loaded.Jira.URL != "https://example.atlassian.net" || !loaded.HasProject() {
+			return fmt.Errorf("loaded incomplete config: %+v", loaded)
+		}
+	}
+	return nil
+}
+
+func TestHammerHelperProcess(t *testing.T) {
+	id := os.Getenv("JCLI_HAMMER_ID")
+	if id == "" {
+		t.Skip("helper process for TestConcurrentSaveLoad")
+	}
+	if err := hammer(id, 50); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConcurrentSaveLoad(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*2)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := hammer(fmt.Sprintf("G%d", i), 50); err != nil {
+				errs <- fmt.Errorf("goroutine %d: %w", i, err)
+			}
+		}(i)
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cmd := exec.Command(os.Args[0], "-test.run=^TestHammerHelperProcess$")
+			cmd.Env = append(os.Environ(),
+				"XDG_CONFIG_HOME="+configDir,
+				fmt.Sprintf("JCLI_HAMMER_ID=P%d", i),
+			)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				errs <- fmt.Errorf("process %d: %v\n%s", i, err, out)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	if _, err := Load(); err != nil {
+		t.Errorf("final config is unreadable: %v", err)
+	}
+}
+
+// addQueries adds one query per round through Update. A lost update would
+// drop the queries another writer added in between.
+func addQueries(id string, rounds int) error {
+	for i := 0; i < rounds; i++ {
+		_, err := Update(func(cfg *Config) error {
+			if cfg.Queries == nil {
+				cfg.Queries = make(map[string]string)
+			}
+			cfg.Queries[fmt.Sprintf("%s-%d", id, i)] = "assignee = currentUser()"
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestUpdateHelperProcess(t *testing.T) {
+	id := os.Getenv("JCLI_UPDATE_ID")
+	if id == "" {
+		t.Skip("helper process for TestConcurrentUpdate")
+	}
+	if err := addQueries(id, 10); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConcurrentUpdate(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+
+	const workers, rounds = 4, 10
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*2)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			if err := addQueries(fmt.Sprintf("G%d", i), rounds); err != nil {
+				errs <- fmt.Errorf("goroutine %d: %w", i, err)
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			cmd := exec.Command(os.Args[0], "-test.run=^TestUpdateHelperProcess$")
+			cmd.Env = append(os.Environ(),
+				"XDG_CONFIG_HOME="+configDir,
+				fmt.Sprintf("JCLI_UPDATE_ID=P%d", i),
+			)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				errs <- fmt.Errorf("process %d: %v\n%s", i, err, out)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("final config is unreadable: %v", err)
+	}
+	if len(cfg.Queries) != workers*2*rounds {
+		t.Errorf("expected %d queries, got %d: updates were lost", workers*2*rounds, len(cfg.Queries))
+	}
+}
@@ -4,28 +4,120 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/tutunak/jcli/internal/credentials"
+	"github.com/tutunak/jcli/internal/lockedfile"
 	"gopkg.in/yaml.v3"
 )
 
+// JiraConfig holds the site and credentials of a profile. Instead of a
+// plaintext APIToken, the token can come from a git-style CredentialHelper,
+// the output of APITokenCommand or the passphrase-encrypted token store.
+// Proxy and TLS apply to every request made for the profile.
+// On Server and Data Center deployments the token is a Personal Access Token
+// and Email is not needed. With Auth set to "oauth", Cloud sites are accessed
+// with OAuth tokens obtained by "jcli auth login" instead.
 type JiraConfig struct {
-	URL      string `yaml:"url"`
-	Email    string `yaml:"email"`
-	APIToken string `yaml:"api_token"`
+	URL              string      `yaml:"url"`
+	Deployment       string      `yaml:"deployment,omitempty"`
+	Auth             string      `yaml:"auth,omitempty"`
+	Email            string      `yaml:"email"`
+	APIToken         string      `yaml:"api_token"`
+	CredentialHelper string      `yaml:"credential_helper,omitempty"`
+	APITokenCommand  string      `yaml:"api_token_command,omitempty"`
+	TokenStore       string      `yaml:"token_store,omitempty"`
+	OAuth            OAuthConfig `yaml:"oauth,omitempty"`
+	Proxy            string      `yaml:"proxy,omitempty"`
+	TLS              TLSConfig   `yaml:"tls,omitempty"`
 }
 
+// TLSConfig adds trusted CA bundles, a client certificate for mutual TLS and
+// a minimum TLS version ("1.2" by default) to the connections of a profile.
+type TLSConfig struct {
+	CAFiles    []string `yaml:"ca_files,omitempty"`
+	ClientCert string   `yaml:"client_cert,omitempty"`
+	ClientKey  string   `yaml:"client_key,omitempty"`
+	MinVersion string   `yaml:"min_version,omitempty"`
+}
+
+// OAuthConfig identifies the OAuth 2.0 (3LO) app used by "jcli auth login".
+// The endpoint URLs default to Atlassian's.
+type OAuthConfig struct {
+	ClientID     string   `yaml:"client_id,omitempty"`
+	ClientSecret string   `yaml:"client_secret,omitempty"`
+	CallbackPort int      `yaml:"callback_port,omitempty"`
+	Scopes       []string `yaml:"scopes,omitempty"`
+	AuthURL      string   `yaml:"auth_url,omitempty"`
+	APIURL       string   `yaml:"api_url,omitempty"`
+}
+
+// AuthOAuth selects OAuth tokens instead of an API token.
+const AuthOAuth = "oauth"
+
+// Jira deployment types. An empty deployment means cloud.
+const (
+	DeploymentCloud      = "cloud"
+	DeploymentServer     = "server"
+	DeploymentDataCenter = "datacenter"
+)
+
+// TokenStoreEncrypted keeps the API token in CredentialsPath, encrypted with
+// a passphrase.
+const TokenStoreEncrypted = "encrypted"
+
 type Defaults struct {
 	Project string `yaml:"project"`
 	Status  string `yaml:"status"`
 }
 
+// GitConfig controls how the current issue is detected from the checked-out
+// git branch. IssuePattern overrides the key pattern derived from the
+// project keys.
+type GitConfig struct {
+	DetectIssue    bool     `yaml:"detect_issue"`
+	IssuePattern   string   `yaml:"issue_pattern,omitempty"`
+	ProjectKeys    []string `yaml:"project_keys,omitempty"`
+	BranchTemplate string   `yaml:"branch_template,omitempty"`
+}
+
+// ListConfig sets the default columns and sort order of "jcli issue list".
+// Columns are key, summary, type, status, priority, assignee, reporter,
+// created, updated or custom field IDs; sort fields take a "-" prefix for
+// descending order.
+type ListConfig struct {
+	Columns []string `yaml:"columns,omitempty"`
+	Sort    []string `yaml:"sort,omitempty"`
+}
+
+// Config is the parsed config.yaml. Jira and Defaults hold the settings of
+// the active profile; the top-level sections of the file are the default
+// profile and Profiles holds the named ones. Queries maps names to JQL and
+// Aliases names to command lines.
 type Config struct {
-	Jira     JiraConfig `yaml:"jira"`
-	Defaults Defaults   `yaml:"defaults"`
+	Version  int                 `yaml:"version"`
+	Jira     JiraConfig          `yaml:"jira"`
+	Defaults Defaults            `yaml:"defaults"`
+	Git      GitConfig           `yaml:"git"`
+	List     ListConfig          `yaml:"list,omitempty"`
+	Queries  map[string]string   `yaml:"queries,omitempty"`
+	Aliases  map[string]string   `yaml:"aliases,omitempty"`
+	Profile  string              `yaml:"profile,omitempty"`
+	Profiles map[string]*Profile `yaml:"profiles,omitempty"`
+
+	active  string
+	base    Profile
+	origins map[string]string
+	// stored and loaded are the overridable settings before and after
+	// .jcli.yaml files and environment variables were applied.
+	stored *overridable
+	loaded *overridable
 }
 
 func DefaultConfig() *Config {
 	return &Config{
+		Version: CurrentVersion,
 		Defaults: Defaults{
 			Status: "In Progress",
 		},
@@ -43,7 +135,22 @@ func ConfigDir() (string, error) {
 	return filepath.Join(home, ".config", "jcli"), nil
 }
 
+// pathOverride is set from the --config flag and wins over JCLI_CONFIG.
+var pathOverride string
+
+func SetPathOverride(path string) {
+	pathOverride = path
+}
+
+// ConfigPath returns the config file: --config, then JCLI_CONFIG, then
+// config.yaml in ConfigDir.
 func ConfigPath() (string, error) {
+	if pathOverride != "" {
+		return pathOverride, nil
+	}
+	if env := os.Getenv("JCLI_CONFIG"); env != "" {
+		return env, nil
+	}
 	dir, err := ConfigDir()
 	if err != nil {
 		return "", err
@@ -51,81 +158,371 @@ func ConfigPath() (string, error) {
 	return filepath.Join(dir, "config.yaml"), nil
 }
 
+// CredentialsPath is the location of the encrypted token store.
+func CredentialsPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "credentials.enc"), nil
+}
+
+// OAuthTokensPath is the location of the tokens stored by "jcli auth login".
+func OAuthTokensPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "oauth_tokens.json"), nil
+}
+
+// Load reads the configuration of the active profile. Its errors are
+// *Error.
 func Load() (*Config, error) {
+	cfg, err := load()
+	if err != nil {
+		return nil, &Error{Err: err}
+	}
+	return cfg, nil
+}
+
+func load() (*Config, error) {
 	path, err := ConfigPath()
 	if err != nil {
 		return nil, err
 	}
 
-	cfg := DefaultConfig()
-
 	data, err := os.ReadFile(path)
-	if os.IsNotExist(err) {
-		cfg.applyEnvOverrides()
-		return cfg, nil
-	}
-	if err != nil {
+	if err != nil && !os.IsNotExist(err) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
+	if err == nil {
+		data, err = upgrade(path, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return parse(path, data)
+}
+
+// parse builds the configuration of the active profile from the contents of
+// the config file at path, nil when it doesn't exist, and applies the local
+// files, environment variables and flags over it.
+func parse(path string, data []byte) (*Config, error) {
+	cfg := DefaultConfig()
+
+	var doc map[string]any
+	if data != nil {
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+	}
 
-	if err := yaml.Unmarshal(data, cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	if err := cfg.activate(cfg.resolveProfile()); err != nil {
+		return nil, err
 	}
+	cfg.markFileOrigins(doc, path)
+
+	stored := cfg.snapshot()
+	cfg.stored = &stored
 
+	// Precedence: defaults < config.yaml < .jcli.yaml files < environment <
+	// flags.
+	if wd, err := os.Getwd(); err == nil {
+		if err := cfg.applyLocal(LocalConfigPaths(wd)); err != nil {
+			return nil, err
+		}
+	}
 	cfg.applyEnvOverrides()
+	cfg.applyFlagOverrides()
+
+	loaded := cfg.snapshot()
+	cfg.loaded = &loaded
 	return cfg, nil
 }
 
 func (c *Config) applyEnvOverrides() {
 	if url := os.Getenv("JIRA_URL"); url != "" {
 		c.Jira.URL = url
+		c.setOrigin("jira.url", "env JIRA_URL")
+	}
+	if deployment := os.Getenv("JIRA_DEPLOYMENT"); deployment != "" {
+		c.Jira.Deployment = deployment
+		c.setOrigin("jira.deployment", "env JIRA_DEPLOYMENT")
 	}
 	if email := os.Getenv("JIRA_EMAIL"); email != "" {
 		c.Jira.Email = email
+		c.setOrigin("jira.email", "env JIRA_EMAIL")
 	}
 	if token := os.Getenv("JIRA_API_TOKEN"); token != "" {
 		c.Jira.APIToken = token
+		c.setOrigin("jira.api_token", "env JIRA_API_TOKEN")
 	}
 	if project := os.Getenv("JIRA_PROJECT"); project != "" {
 		c.Defaults.Project = project
+		c.setOrigin("defaults.project", "env JIRA_PROJECT")
 	}
 	if status := os.Getenv("JIRA_STATUS"); status != "" {
 		c.Defaults.Status = status
+		c.setOrigin("defaults.status", "env JIRA_STATUS")
+	}
+	if detect := os.Getenv("JCLI_DETECT_ISSUE"); detect != "" {
+		c.Git.DetectIssue = detect == "1" || strings.EqualFold(detect, "true")
+		c.setOrigin("git.detect_issue", "env JCLI_DETECT_ISSUE")
+	}
+}
+
+// FlagOverrides are settings given on the command line for one invocation.
+// Like environment variables they are never written to config.yaml.
+type FlagOverrides struct {
+	Project string
+	Status  string
+}
+
+var flagOverrides FlagOverrides
+
+func SetFlagOverrides(o FlagOverrides) {
+	flagOverrides = o
+}
+
+func (c *Config) applyFlagOverrides() {
+	if flagOverrides.Project != "" {
+		c.Defaults.Project = flagOverrides.Project
+		c.setOrigin("defaults.project", "flag --project")
+	}
+	if flagOverrides.Status != "" {
+		c.Defaults.Status = flagOverrides.Status
+		c.setOrigin("defaults.status", "flag --status")
 	}
 }
 
 func (c *Config) Save() error {
-	path, err := ConfigPath()
+	path, err := configPathDir()
 	if err != nil {
 		return err
 	}
 
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0700); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
-	}
-
-	data, err := yaml.Marshal(c)
+	data, err := c.marshal()
 	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+		return err
 	}
 
-	if err := os.WriteFile(path, data, 0600); err != nil {
+	if err := lockedfile.Write(path, data, 0600); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
 	return nil
 }
 
+// Update loads the configuration like Load, lets fn change it and saves the
+// result, all under the lock of the config file, so that concurrent changes
+// are never lost. Errors of fn are returned as they are; loading errors are
+// *Error.
+func Update(fn func(*Config) error) (*Config, error) {
+	path, err := configPathDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to save config: %w", err)
+	}
+
+	var cfg *Config
+	var changed bool
+	_, err = lockedfile.Update(path, 0600, func(data []byte) ([]byte, error) {
+		if data != nil {
+			upgraded, err := upgradeLocked(path, data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load config: %w", &Error{Err: err})
+			}
+			data = upgraded
+		}
+		c, err := parse(path, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", &Error{Err: err})
+		}
+		if err := fn(c); err != nil {
+			return nil, err
+		}
+		cfg, changed = c, true
+		return c.marshal()
+	})
+	if err != nil {
+		if changed {
+			return nil, fmt.Errorf("failed to save config: %w", err)
+		}
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// configPathDir returns ConfigPath after creating its directory.
+func configPathDir() (string, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return path, nil
+}
+
+func (c *Config) marshal() ([]byte, error) {
+	c.Version = CurrentVersion
+	data, err := yaml.Marshal(c.withoutOverrides().persisted())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return data, nil
+}
+
+// Validate checks that the settings needed to talk to Jira are present. Its
+// errors are *Error.
 func (c *Config) Validate() error {
+	if err := c.validate(); err != nil {
+		return &Error{Err: err}
+	}
+	return nil
+}
+
+func (c *Config) validate() error {
 	if c.Jira.URL == "" {
 		return fmt.Errorf("jira.url is not configured")
 	}
-	if c.Jira.Email == "" {
+	switch c.Jira.Deployment {
+	case "", DeploymentCloud, DeploymentServer, DeploymentDataCenter:
+	default:
+		return fmt.Errorf("unknown jira.deployment %q (supported: %s, %s, %s)",
+			c.Jira.Deployment, DeploymentCloud, DeploymentServer, DeploymentDataCenter)
+	}
+	switch c.Jira.Auth {
+	case "":
+	case AuthOAuth:
+		if c.IsServer() {
+			return fmt.Errorf("jira.auth %q is only supported for Jira Cloud", AuthOAuth)
+		}
+		if c.Jira.OAuth.ClientID == "" {
+			return fmt.Errorf("jira.oauth.client_id is not configured")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown jira.auth %q (supported: %s)", c.Jira.Auth, AuthOAuth)
+	}
+	if c.Jira.Email == "" && !c.IsServer() {
 		return fmt.Errorf("jira.email is not configured")
 	}
-	if c.Jira.APIToken == "" {
-		return fmt.Errorf("jira.api_token is not configured (set via config or JIRA_API_TOKEN env var)")
+	if !c.HasTokenSource() {
+		return fmt.Errorf("jira.api_token is not configured (set via config, JIRA_API_TOKEN env var, jira.credential_helper, jira.api_token_command or jira.token_store)")
+	}
+	return nil
+}
+
+// IsServer reports whether the profile targets Jira Server or Data Center,
+// which use REST API v2 and Personal Access Tokens.
+func (c *Config) IsServer() bool {
+	return c.Jira.Deployment == DeploymentServer || c.Jira.Deployment == DeploymentDataCenter
+}
+
+// HasTokenSource reports whether an API token is set or can be obtained.
+func (c *Config) HasTokenSource() bool {
+	return c.Jira.APIToken != "" || c.Jira.APITokenCommand != "" || c.Jira.CredentialHelper != "" ||
+		c.Jira.TokenStore == TokenStoreEncrypted
+}
+
+// ResolveAPIToken fills in Jira.APIToken from api_token_command or the
+// credential helper when no token is set directly and OAuth is not used.
+// Resolved tokens are never written back to config.yaml.
+func (c *Config) ResolveAPIToken() error {
+	if c.Jira.APIToken != "" || c.Jira.Auth == AuthOAuth {
+		return nil
+	}
+
+	var token, origin string
+	var err error
+	switch {
+	case c.Jira.APITokenCommand != "":
+		token, err = credentials.RunTokenCommand(c.Jira.APITokenCommand)
+		origin = "api_token_command"
+	case c.Jira.CredentialHelper != "":
+		token, err = credentials.NewHelper(c.Jira.CredentialHelper).Get(c.Jira.URL, c.Jira.Email)
+		origin = "credential helper"
+	case c.Jira.TokenStore == TokenStoreEncrypted:
+		token, err = c.tokenFromStore()
+		origin = "encrypted token store"
+	case c.Jira.TokenStore != "":
+		return &Error{Err: fmt.Errorf("unknown jira.token_store %q (supported: %s)", c.Jira.TokenStore, TokenStoreEncrypted)}
+	default:
+		return nil
+	}
+	if err != nil {
+		return &CredentialsError{Err: err}
+	}
+
+	c.Jira.APIToken = token
+	if c.loaded != nil {
+		c.loaded.Jira.APIToken = token
+	}
+	c.setOrigin("jira.api_token", origin)
+	return nil
+}
+
+func (c *Config) tokenFromStore() (string, error) {
+	store, err := OpenCredentialStore(false)
+	if err != nil {
+		return "", err
+	}
+	token, ok := store.Get(c.Jira.URL, c.Jira.Email)
+	if !ok {
+		return "", fmt.Errorf("no API token for %s at %s in the encrypted store; run 'jcli config credentials'", c.Jira.Email, c.Jira.URL)
+	}
+	return token, nil
+}
+
+// OpenCredentialStore unlocks the encrypted token store with a passphrase
+// from JCLI_PASSPHRASE or a prompt. create asks for confirmation when the
+// store does not exist yet.
+func OpenCredentialStore(create bool) (*credentials.Store, error) {
+	path, err := CredentialsPath()
+	if err != nil {
+		return nil, err
+	}
+	_, statErr := os.Stat(path)
+	passphrase, err := credentials.Passphrase(create && os.IsNotExist(statErr))
+	if err != nil {
+		return nil, err
+	}
+	return credentials.OpenStore(path, passphrase)
+}
+
+// EncryptTokens moves the plaintext API tokens of all profiles into store
+// and switches those profiles to the encrypted token store. It returns the
+// names of the migrated profiles.
+func (c *Config) EncryptTokens(store *credentials.Store) []string {
+	var migrated []string
+	for _, name := range c.ProfileNames() {
+		jira := c.profileJira(name)
+		if jira == nil || jira.APIToken == "" {
+			continue
+		}
+		store.Set(jira.URL, jira.Email, jira.APIToken)
+		jira.APIToken = ""
+		jira.TokenStore = TokenStoreEncrypted
+		migrated = append(migrated, name)
+	}
+	return migrated
+}
+
+// profileJira returns the editable Jira settings of the named profile.
+func (c *Config) profileJira(name string) *JiraConfig {
+	switch {
+	case name == c.ActiveProfile():
+		return &c.Jira
+	case name == DefaultProfile:
+		return &c.base.Jira
+	}
+	if p, ok := c.Profiles[name]; ok {
+		return &p.Jira
 	}
 	return nil
 }
@@ -133,3 +530,13 @@ func (c *Config) Validate() error {
 func (c *Config) HasProject() bool {
 	return c.Defaults.Project != ""
 }
+
+// IssueProjectKeys returns the project keys used to recognize issue keys in
+// branch names: the configured git project keys plus the default project.
+func (c *Config) IssueProjectKeys() []string {
+	keys := append([]string{}, c.Git.ProjectKeys...)
+	if c.HasProject() && !slices.Contains(keys, c.Defaults.Project) {
+		keys = append(keys, c.Defaults.Project)
+	}
+	return keys
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -150,6 +152,73 @@ func TestEnvOverridesWithoutConfigFile(t *testing.T) {
 	}
 }
 
+func TestFlagOverrides(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("JIRA_PROJECT", "ENVPROJ")
+	t.Cleanup(func() { SetFlagOverrides(FlagOverrides{}) })
+
+	cfg := DefaultConfig()
+	cfg.Defaults.Project = "FILE"
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	SetFlagOverrides(FlagOverrides{Project: "FLAG", Status: "Done"})
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded.Defaults.Project != "FLAG" || loaded.Defaults.Status != "Done" {
+		t.Errorf("expected flag overrides, got project %q status %q", loaded.Defaults.Project, loaded.Defaults.Status)
+	}
+	if origin := loaded.Origin("defaults.project"); origin != "flag --project" {
+		t.Errorf("Origin(defaults.project) = %q", origin)
+	}
+
+	// Flag values are not persisted.
+	if err := loaded.Save(); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	SetFlagOverrides(FlagOverrides{})
+	t.Setenv("JIRA_PROJECT", "")
+	reloaded, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reloaded.Defaults.Project != "FILE" || reloaded.Defaults.Status != "In Progress" {
+		t.Errorf("flag overrides leaked into config.yaml: project %q status %q", reloaded.Defaults.Project, reloaded.Defaults.Status)
+	}
+}
+
+func TestConfigPathOverride(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Cleanup(func() { SetPathOverride("") })
+
+	envPath := filepath.Join(t.TempDir(), "env.yaml")
+	t.Setenv("JCLI_CONFIG", envPath)
+	if path, _ := ConfigPath(); path != envPath {
+		t.Errorf("ConfigPath() = %q, want %q", path, envPath)
+	}
+
+	flagPath := filepath.Join(t.TempDir(), "flag.yaml")
+	SetPathOverride(flagPath)
+	cfg := DefaultConfig()
+	cfg.Defaults.Project = "ALT"
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	if _, err := os.Stat(flagPath); err != nil {
+		t.Fatalf("expected config at %s: %v", flagPath, err)
+	}
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded.Defaults.Project != "ALT" {
+		t.Errorf("expected project from %s, got %q", flagPath, loaded.Defaults.Project)
+	}
+}
+
 func TestValidate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -178,6 +247,17 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "token from command",
+			cfg: &Config{
+				Jira: JiraConfig{
+					URL:             "https://test.atlassian.net",
+					Email:           "test@example.com",
+					APITokenCommand: "pass show jira",
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "valid config",
 			cfg: &Config{
@@ -189,6 +269,47 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "server without email",
+			cfg: &Config{
+				Jira: JiraConfig{
+					URL:        "https://jira.example.com",
+					Deployment: DeploymentServer,
+					APIToken:   "pat",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "oauth without token",
+			cfg: &Config{
+				Jira: JiraConfig{
+					URL:   "https://test.atlassian.net",
+					Auth:  AuthOAuth,
+					OAuth: OAuthConfig{ClientID: "client"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "oauth without client id",
+			cfg: &Config{
+				Jira: JiraConfig{URL: "https://test.atlassian.net", Auth: AuthOAuth},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown deployment",
+			cfg: &Config{
+				Jira: JiraConfig{
+					URL:        "https://jira.example.com",
+					Deployment: "onprem",
+					Email:      "test@example.com",
+					APIToken:   "token",
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -212,3 +333,112 @@ func TestHasProject(t *testing.T) {
 		t.Error("expected HasProject() to return true for set project")
 	}
 }
+
+func TestIssueProjectKeys(t *testing.T) {
+	cfg := &Config{}
+	if keys := cfg.IssueProjectKeys(); len(keys) != 0 {
+		t.Errorf("expected no keys, got %v", keys)
+	}
+
+	cfg.Git.ProjectKeys = []string{"OPS", "PROJ"}
+	cfg.Defaults.Project = "PROJ"
+	if keys := cfg.IssueProjectKeys(); !slices.Equal(keys, []string{"OPS", "PROJ"}) {
+		t.Errorf("expected [OPS PROJ], got %v", keys)
+	}
+
+	cfg.Defaults.Project = "WEB"
+	if keys := cfg.IssueProjectKeys(); !slices.Equal(keys, []string{"OPS", "PROJ", "WEB"}) {
+		t.Errorf("expected [OPS PROJ WEB], got %v", keys)
+	}
+}
+
+func TestDetectIssueEnvOverride(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("JCLI_DETECT_ISSUE", "true")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Git.DetectIssue {
+		t.Error("expected JCLI_DETECT_ISSUE to enable branch detection")
+	}
+}
+
+func TestResolveAPIToken(t *testing.T) {
+	t.Run("api_token_command", func(t *testing.T) {
+		cfg := &Config{Jira: JiraConfig{
+			URL:              "https://test.atlassian.net",
+			APITokenCommand:  "echo command-token",
+			CredentialHelper: "!echo password=helper-token #",
+		}}
+		if err := cfg.ResolveAPIToken(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Jira.APIToken != "command-token" {
+			t.Errorf("expected token from command, got %q", cfg.Jira.APIToken)
+		}
+		if cfg.Origin("jira.api_token") != "api_token_command" {
+			t.Errorf("unexpected origin %q", cfg.Origin("jira.api_token"))
+		}
+	})
+
+	t.Run("credential helper", func(t *testing.T) {
+		cfg := &Config{Jira: JiraConfig{
+			URL:              "https://test.atlassian.net",
+			CredentialHelper: "!echo password=helper-token #",
+		}}
+		if err := cfg.ResolveAPIToken(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Jira.APIToken != "helper-token" {
+			t.Errorf("expected token from helper, got %q", cfg.Jira.APIToken)
+		}
+	})
+
+	t.Run("plaintext token wins", func(t *testing.T) {
+		cfg := &Config{Jira: JiraConfig{APIToken: "plain", APITokenCommand: "exit 1"}}
+		if err := cfg.ResolveAPIToken(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Jira.APIToken != "plain" {
+			t.Errorf("expected plaintext token, got %q", cfg.Jira.APIToken)
+		}
+	})
+
+	t.Run("failing command", func(t *testing.T) {
+		cfg := &Config{Jira: JiraConfig{APITokenCommand: "exit 1"}}
+		if err := cfg.ResolveAPIToken(); err == nil {
+			t.Error("expected error from failing command")
+		}
+	})
+}
+
+func TestResolvedTokenIsNotSaved(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg := DefaultConfig()
+	cfg.Jira.URL = "https://test.atlassian.net"
+	cfg.Jira.APITokenCommand = "echo secret-from-command"
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.ResolveAPIToken(); err != nil {
+		t.Fatal(err)
+	}
+	loaded.Defaults.Project = "NEW"
+	if err := loaded.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := ConfigPath()
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "api_token: secret-from-command") {
+		t.Errorf("resolved token was written to config:\n%s", data)
+	}
+}
@@ -0,0 +1,26 @@
+package config
+
+import "errors"
+
+// Error reports an invalid or incomplete configuration: a config file that
+// can't be read or parsed, a missing or invalid setting, or an unknown
+// profile.
+type Error struct {
+	Err error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+func (e *Error) Unwrap() error { return e.Err }
+
+// CredentialsError reports that the API token of the active profile could
+// not be obtained from its command, credential helper or encrypted store.
+type CredentialsError struct {
+	Err error
+}
+
+func (e *CredentialsError) Error() string { return e.Err.Error() }
+func (e *CredentialsError) Unwrap() error { return e.Err }
+
+// ErrExists is wrapped by the errors of adding a profile whose name is
+// taken.
+var ErrExists = errors.New("already exists")
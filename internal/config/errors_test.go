@@ -0,0 +1,48 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestErrorTypes(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+	t.Setenv("JCLI_CONFIG", "")
+	t.Setenv("JCLI_PROFILE", "")
+
+	path := filepath.Join(tmpDir, "jcli", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("jira: [not a map"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var cfgErr *Error
+	if _, err := Load(); !errors.As(err, &cfgErr) {
+		t.Errorf("Load() of an invalid file = %v, want *Error", err)
+	}
+
+	if err := DefaultConfig().Validate(); !errors.As(err, &cfgErr) {
+		t.Errorf("Validate() of an empty config = %v, want *Error", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.Jira.URL = "https://example.atlassian.net"
+	cfg.Jira.Email = "me@example.com"
+	cfg.Jira.APITokenCommand = "exit 1"
+	var credErr *CredentialsError
+	if err := cfg.ResolveAPIToken(); !errors.As(err, &credErr) {
+		t.Errorf("ResolveAPIToken() with a failing command = %v, want *CredentialsError", err)
+	}
+
+	if err := cfg.AddProfile("work", Profile{}); err != nil {
+		t.Fatal(err)
+	}
+	err := cfg.AddProfile("work", Profile{})
+	if !errors.Is(err, ErrExists) || err.Error() != `profile "work" already exists` {
+		t.Errorf("AddProfile() of a taken name = %v, want ErrExists", err)
+	}
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/tutunak/jcli/internal/git"
+	"gopkg.in/yaml.v3"
+)
+
+// LocalConfigName is the repository-local override file.
+const LocalConfigName = ".jcli.yaml"
+
+// localConfig lists everything a .jcli.yaml may set. Credentials and Jira
+// site settings are deliberately absent: the file is usually committed.
+type localConfig struct {
+	Defaults struct {
+		Project *string `yaml:"project"`
+		Status  *string `yaml:"status"`
+	} `yaml:"defaults"`
+	Git struct {
+		DetectIssue    *bool    `yaml:"detect_issue"`
+		IssuePattern   *string  `yaml:"issue_pattern"`
+		ProjectKeys    []string `yaml:"project_keys"`
+		BranchTemplate *string  `yaml:"branch_template"`
+	} `yaml:"git"`
+	Queries map[string]string `yaml:"queries"`
+}
+
+// forbiddenLocalKeys are rejected with a clear message instead of the
+// generic unknown-field error.
+var forbiddenLocalKeys = []string{"jira", "profile", "profiles", "api_token", "aliases"}
+
+// LocalConfigPaths returns the .jcli.yaml files from the root of the git
+// repository containing dir down to dir, outermost first. Outside a
+// repository only dir itself is searched. The root is found like the state
+// scope, so GIT_CEILING_DIRECTORIES and symlinks are honored the same way.
+func LocalConfigPaths(dir string) []string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	root, err := git.FindRoot(dir)
+	if err != nil {
+		root = dir
+	}
+
+	var candidates []string
+	for d := dir; ; d = filepath.Dir(d) {
+		candidates = append(candidates, d)
+		if d == root || d == filepath.Dir(d) {
+			break
+		}
+	}
+
+	var paths []string
+	for _, d := range slices.Backward(candidates) {
+		path := filepath.Join(d, LocalConfigName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// applyLocal merges the repository-local files over the user config, later
+// (nearer) files winning.
+func (c *Config) applyLocal(paths []string) error {
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		local, err := parseLocal(data)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", path, err)
+		}
+
+		c.mergeLocal(local, path)
+	}
+	return nil
+}
+
+func parseLocal(data []byte) (*localConfig, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	for _, key := range forbiddenLocalKeys {
+		if _, ok := doc[key]; ok {
+			return nil, fmt.Errorf("%q cannot be set in %s; keep Jira sites, credentials and aliases in the user config", key, LocalConfigName)
+		}
+	}
+
+	var local localConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&local); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return &local, nil
+}
+
+func (c *Config) mergeLocal(local *localConfig, path string) {
+	if v := local.Defaults.Project; v != nil {
+		c.Defaults.Project = *v
+		c.setOrigin("defaults.project", path)
+	}
+	if v := local.Defaults.Status; v != nil {
+		c.Defaults.Status = *v
+		c.setOrigin("defaults.status", path)
+	}
+	if v := local.Git.DetectIssue; v != nil {
+		c.Git.DetectIssue = *v
+		c.setOrigin("git.detect_issue", path)
+	}
+	if v := local.Git.IssuePattern; v != nil {
+		c.Git.IssuePattern = *v
+		c.setOrigin("git.issue_pattern", path)
+	}
+	if v := local.Git.ProjectKeys; v != nil {
+		c.Git.ProjectKeys = v
+		c.setOrigin("git.project_keys", path)
+	}
+	if v := local.Git.BranchTemplate; v != nil {
+		c.Git.BranchTemplate = *v
+		c.setOrigin("git.branch_template", path)
+	}
+	for name, jql := range local.Queries {
+		if c.Queries == nil {
+			c.Queries = make(map[string]string)
+		}
+		c.Queries[name] = jql
+		c.setOrigin("queries."+name, path)
+	}
+}
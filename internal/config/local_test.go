@@ -0,0 +1,201 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setupRepo creates a fake repository with a nested service directory and
+// returns both paths.
+func setupRepo(t *testing.T) (string, string) {
+	t.Helper()
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	service := filepath.Join(repo, "services", "api")
+	if err := os.MkdirAll(service, 0700); err != nil {
+		t.Fatal(err)
+	}
+	return repo, service
+}
+
+func TestLocalConfigPaths(t *testing.T) {
+	repo, service := setupRepo(t)
+
+	if paths := LocalConfigPaths(service); len(paths) != 0 {
+		t.Errorf("expected no local config, got %v", paths)
+	}
+
+	writeFile(t, filepath.Join(repo, LocalConfigName), "defaults:\n  project: ROOT\n")
+	writeFile(t, filepath.Join(service, LocalConfigName), "defaults:\n  project: API\n")
+	writeFile(t, filepath.Join(filepath.Dir(repo), LocalConfigName), "defaults:\n  project: OUTSIDE\n")
+
+	want := []string{filepath.Join(repo, LocalConfigName), filepath.Join(service, LocalConfigName)}
+	if got := LocalConfigPaths(service); !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestLocalConfigPathsHonorsCeilings(t *testing.T) {
+	outer := t.TempDir()
+	if err := os.Mkdir(filepath.Join(outer, ".git"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	work := filepath.Join(outer, "ceiling", "work")
+	writeFile(t, filepath.Join(outer, LocalConfigName), "defaults:\n  project: OUTER\n")
+	writeFile(t, filepath.Join(work, LocalConfigName), "defaults:\n  project: WORK\n")
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Join(outer, "ceiling"))
+
+	want := []string{filepath.Join(work, LocalConfigName)}
+	if got := LocalConfigPaths(work); !slices.Equal(got, want) {
+		t.Errorf("expected the search to stop below the ceiling, got %v", got)
+	}
+}
+
+func TestLoadMergesLocalConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repo, service := setupRepo(t)
+
+	cfg := DefaultConfig()
+	cfg.Jira.URL = "https://company.atlassian.net"
+	cfg.Defaults.Project = "USER"
+	cfg.Queries = map[string]string{"mine": "assignee = currentUser()"}
+	cfg.Aliases = map[string]string{"co": "issue select"}
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, filepath.Join(repo, LocalConfigName), `defaults:
+  project: ROOT
+  status: Review
+git:
+  branch_template: "{key}/{summary}"
+queries:
+  bugs: "type = Bug"
+`)
+	writeFile(t, filepath.Join(service, LocalConfigName), "defaults:\n  project: API\n")
+	t.Chdir(service)
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded.Defaults.Project != "API" {
+		t.Errorf("expected nearest project API, got %q", loaded.Defaults.Project)
+	}
+	if loaded.Defaults.Status != "Review" {
+		t.Errorf("expected status from repo root, got %q", loaded.Defaults.Status)
+	}
+	if loaded.Git.BranchTemplate != "{key}/{summary}" {
+		t.Errorf("expected branch template from repo root, got %q", loaded.Git.BranchTemplate)
+	}
+	if loaded.Queries["mine"] == "" || loaded.Queries["bugs"] != "type = Bug" {
+		t.Errorf("expected merged queries, got %v", loaded.Queries)
+	}
+	if loaded.Jira.URL != "https://company.atlassian.net" {
+		t.Errorf("expected user URL, got %q", loaded.Jira.URL)
+	}
+
+	userPath, _ := ConfigPath()
+	for key, want := range map[string]string{
+		"defaults.project":    filepath.Join(service, LocalConfigName),
+		"defaults.status":     filepath.Join(repo, LocalConfigName),
+		"jira.url":            userPath,
+		"jira.email":          userPath,
+		"git.issue_pattern":   OriginDefault,
+		"queries.mine":        userPath,
+		"queries.bugs":        filepath.Join(repo, LocalConfigName),
+		"aliases.co":          userPath,
+		"git.branch_template": filepath.Join(repo, LocalConfigName),
+	} {
+		if got := loaded.Origin(key); got != want {
+			t.Errorf("Origin(%s) = %q, want %q", key, got, want)
+		}
+	}
+
+	t.Setenv("JIRA_PROJECT", "ENV")
+	loaded, err = Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded.Defaults.Project != "ENV" || loaded.Origin("defaults.project") != "env JIRA_PROJECT" {
+		t.Errorf("expected environment to win over local config, got %q from %s",
+			loaded.Defaults.Project, loaded.Origin("defaults.project"))
+	}
+}
+
+func TestSaveDoesNotPersistLocalOverrides(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repo, _ := setupRepo(t)
+
+	cfg := DefaultConfig()
+	cfg.Defaults.Project = "USER"
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, filepath.Join(repo, LocalConfigName), "defaults:\n  project: REPO\nqueries:\n  bugs: type = Bug\n")
+	t.Chdir(repo)
+	t.Setenv("JIRA_API_TOKEN", "env-token")
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded.Defaults.Status = "Done"
+	if err := loaded.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := ConfigPath()
+	data, _ := os.ReadFile(path)
+	content := string(data)
+	if !strings.Contains(content, "project: USER") || strings.Contains(content, "REPO") {
+		t.Errorf("expected local project not to be saved:\n%s", content)
+	}
+	if strings.Contains(content, "bugs") || strings.Contains(content, "env-token") {
+		t.Errorf("expected overrides not to be saved:\n%s", content)
+	}
+	if !strings.Contains(content, "status: Done") {
+		t.Errorf("expected changed status to be saved:\n%s", content)
+	}
+}
+
+func TestLocalConfigRejectsSecrets(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repo, _ := setupRepo(t)
+	t.Chdir(repo)
+
+	for _, content := range []string{
+		"jira:\n  api_token: leaked\n",
+		"jira:\n  url: https://other.atlassian.net\n",
+		"profiles:\n  x: {}\n",
+		"aliases:\n  build: '!make'\n",
+		"defaults:\n  api_token: leaked\n",
+		"unknown: true\n",
+	} {
+		writeFile(t, filepath.Join(repo, LocalConfigName), content)
+		if _, err := Load(); err == nil {
+			t.Errorf("expected error for local config:\n%s", content)
+		}
+	}
+
+	writeFile(t, filepath.Join(repo, LocalConfigName), "")
+	if _, err := Load(); err != nil {
+		t.Errorf("unexpected error for empty local config: %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/tutunak/jcli/internal/lockedfile"
+	"github.com/tutunak/jcli/internal/migrate"
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the config schema version written by Save.
+const CurrentVersion = 1
+
+// migrations upgrade config.yaml one schema version at a time. They edit the
+// top-level mapping node in place so that the user's comments and key order
+// survive. Append new steps and bump CurrentVersion whenever fields are
+// restructured.
+var migrations = []migrate.Step[*yaml.Node]{
+	{
+		// Version 1 is the layout of the first versioned release; files
+		// without a version only get it stamped.
+		From:        0,
+		Description: "stamp the baseline schema version",
+		Apply:       func(doc *yaml.Node) error { return nil },
+	},
+}
+
+// upgrade migrates raw config file contents to CurrentVersion. When the file
+// was older, the original is backed up and the upgraded contents are written
+// back to path. Both happen under the lock Save uses, on the contents read
+// under it.
+func upgrade(path string, data []byte) ([]byte, error) {
+	if _, from, err := migrateYAML(data); err != nil || from == CurrentVersion {
+		return data, err
+	}
+
+	return lockedfile.Update(path, 0600, func(data []byte) ([]byte, error) {
+		// Another jcli may have upgraded the file in the meantime.
+		return upgradeLocked(path, data)
+	})
+}
+
+// upgradeLocked is upgrade for callers holding the lock of path: it backs up
+// data when it is older than CurrentVersion and returns it upgraded.
+func upgradeLocked(path string, data []byte) ([]byte, error) {
+	upgraded, from, err := migrateYAML(data)
+	if err != nil || from == CurrentVersion {
+		return data, err
+	}
+	if _, err := migrate.Backup(path, data, from); err != nil {
+		return nil, err
+	}
+	return upgraded, nil
+}
+
+// migrateYAML upgrades config file contents and returns them with the
+// version they started from. Contents already at CurrentVersion are
+// returned as they are.
+func migrateYAML(data []byte) ([]byte, int, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return data, CurrentVersion, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, 0, fmt.Errorf("failed to parse config file: line %d: expected a mapping", root.Line)
+	}
+
+	var version any
+	if node := mappingValue(root, migrate.VersionKey); node != nil {
+		if err := node.Decode(&version); err != nil {
+			return nil, 0, fmt.Errorf("failed to parse config file: %w", err)
+		}
+	}
+	from, err := migrate.ParseVersion(version)
+	if err == nil && from != CurrentVersion {
+		err = migrate.Apply(root, from, migrations, setVersion)
+	}
+	if err != nil {
+		return nil, from, fmt.Errorf("failed to migrate config file: %w", err)
+	}
+	if from == CurrentVersion {
+		return data, from, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, from, fmt.Errorf("failed to marshal migrated config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, from, fmt.Errorf("failed to marshal migrated config: %w", err)
+	}
+	return buf.Bytes(), from, nil
+}
+
+// setVersion sets the version field of the top-level mapping, adding it as
+// the first key when it is missing.
+func setVersion(doc *yaml.Node, version int) {
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(version)}
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value == migrate.VersionKey {
+			doc.Content[i+1] = value
+			return
+		}
+	}
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: migrate.VersionKey}
+	doc.Content = append([]*yaml.Node{key, value}, doc.Content...)
+}
+
+// mappingValue returns the value of key in the mapping node m, or nil.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
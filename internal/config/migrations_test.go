@@ -0,0 +1,147 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tutunak/jcli/internal/migrate"
+)
+
+func TestMigrationsMatchCurrentVersion(t *testing.T) {
+	if got := migrate.Latest(migrations); got != CurrentVersion {
+		t.Errorf("migrations produce version %d, CurrentVersion is %d", got, CurrentVersion)
+	}
+}
+
+func TestMigrationV0StampsVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "empty status is kept",
+			in:   "defaults:\n  project: TEST\n  status: \"\"\n",
+			want: "version: 1\ndefaults:\n  project: TEST\n  status: \"\"\n",
+		},
+		{
+			name: "no defaults",
+			in:   "jira:\n  url: https://x.atlassian.net\n",
+			want: "version: 1\njira:\n  url: https://x.atlassian.net\n",
+		},
+		{
+			name: "version 0 is replaced",
+			in:   "version: 0\ndefaults:\n  status: Done\n",
+			want: "version: 1\ndefaults:\n  status: Done\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, from, err := migrateYAML([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if from != 0 || string(got) != tt.want {
+				t.Errorf("migrateYAML() = %d, %q, want 0, %q", from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrationKeepsCommentsAndOrder(t *testing.T) {
+	in := `# jcli settings
+jira:
+  url: https://x.atlassian.net # the site
+  email: me@x.com
+# picked by issue select
+defaults:
+  status: ""
+  project: TEST
+queries:
+  mine: assignee = currentUser()
+`
+	want := `version: 1
+# jcli settings
+jira:
+  url: https://x.atlassian.net # the site
+  email: me@x.com
+# picked by issue select
+defaults:
+  status: ""
+  project: TEST
+queries:
+  mine: assignee = currentUser()
+`
+	got, _, err := migrateYAML([]byte(in))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("migrateYAML() =\n%s\nwant\n%s", got, want)
+	}
+
+	current, from, err := migrateYAML(got)
+	if err != nil || from != CurrentVersion || string(current) != string(got) {
+		t.Errorf("expected a current file to be left alone, got %d, %v", from, err)
+	}
+}
+
+func TestLoadUpgradesUnversionedConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+
+	path := filepath.Join(tmpDir, "jcli", "config.yaml")
+	original := "jira:\n  url: https://old.atlassian.net\ndefaults:\n  project: OLD\n  status: \"\"\n"
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Version != CurrentVersion {
+		t.Errorf("expected version %d, got %d", CurrentVersion, cfg.Version)
+	}
+	if cfg.Jira.URL != "https://old.atlassian.net" || cfg.Defaults.Project != "OLD" {
+		t.Errorf("expected values to survive migration, got %+v", cfg)
+	}
+	if cfg.Defaults.Status != "" {
+		t.Errorf("expected the explicit empty status to survive migration, got %q", cfg.Defaults.Status)
+	}
+
+	backup, err := os.ReadFile(path + ".v0.bak")
+	if err != nil {
+		t.Fatalf("expected backup of original config: %v", err)
+	}
+	if string(backup) != original {
+		t.Errorf("backup differs from original: %q", backup)
+	}
+
+	upgraded, _ := os.ReadFile(path)
+	if !strings.Contains(string(upgraded), "version: 1") {
+		t.Errorf("expected upgraded file to carry the version, got:\n%s", upgraded)
+	}
+}
+
+func TestLoadRejectsNewerConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+
+	path := filepath.Join(tmpDir, "jcli", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("version: 99\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(); err == nil {
+		t.Error("expected error for config written by a newer version")
+	}
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// OriginDefault marks values that come from the built-in defaults.
+const OriginDefault = "default"
+
+// TrackedKeys lists the settings whose origin is recorded by Load, in the
+// order "config show --origin" reports them. Queries and aliases are tracked
+// per name as "queries.<name>" and "aliases.<name>".
+var TrackedKeys = []string{
+	"jira.url",
+	"jira.deployment",
+	"jira.auth",
+	"jira.oauth.client_id",
+	"jira.email",
+	"jira.api_token",
+	"jira.credential_helper",
+	"jira.api_token_command",
+	"jira.token_store",
+	"jira.proxy",
+	"jira.tls.ca_files",
+	"jira.tls.client_cert",
+	"jira.tls.min_version",
+	"defaults.project",
+	"defaults.status",
+	"git.detect_issue",
+	"git.issue_pattern",
+	"git.project_keys",
+	"git.branch_template",
+}
+
+// Origin reports where the effective value of key came from: a file path,
+// an environment variable or OriginDefault.
+func (c *Config) Origin(key string) string {
+	if origin, ok := c.origins[key]; ok {
+		return origin
+	}
+	return OriginDefault
+}
+
+func (c *Config) setOrigin(key, origin string) {
+	if c.origins == nil {
+		c.origins = make(map[string]string)
+	}
+	c.origins[key] = origin
+}
+
+// markFileOrigins records path as the origin of every tracked key present in
+// the decoded config file. Jira and defaults settings of a named profile are
+// looked up in its profile section.
+func (c *Config) markFileOrigins(doc map[string]any, path string) {
+	for _, key := range TrackedKeys {
+		docPath := strings.Split(key, ".")
+		origin := path
+		if c.active != "" && (docPath[0] == "jira" || docPath[0] == "defaults") {
+			docPath = append([]string{"profiles", c.active}, docPath...)
+			origin = path + " (profile " + c.active + ")"
+		}
+		if hasPath(doc, docPath) {
+			c.setOrigin(key, origin)
+		}
+	}
+
+	for _, section := range []string{"queries", "aliases"} {
+		entries, _ := doc[section].(map[string]any)
+		for name := range entries {
+			c.setOrigin(section+"."+name, path)
+		}
+	}
+}
+
+func hasPath(doc map[string]any, path []string) bool {
+	for i, part := range path {
+		value, ok := doc[part]
+		if !ok {
+			return false
+		}
+		if i == len(path)-1 {
+			return true
+		}
+		if doc, ok = value.(map[string]any); !ok {
+			return false
+		}
+	}
+	return false
+}
+
+// overridable holds the settings that repository-local files and environment
+// variables may override.
+type overridable struct {
+	Jira     JiraConfig
+	Defaults Defaults
+	Git      GitConfig
+	Queries  map[string]string
+}
+
+func (c *Config) snapshot() overridable {
+	return overridable{
+		Jira:     c.Jira,
+		Defaults: c.Defaults,
+		Git: GitConfig{
+			DetectIssue:    c.Git.DetectIssue,
+			IssuePattern:   c.Git.IssuePattern,
+			ProjectKeys:    slices.Clone(c.Git.ProjectKeys),
+			BranchTemplate: c.Git.BranchTemplate,
+		},
+		Queries: maps.Clone(c.Queries),
+	}
+}
+
+// withoutOverrides returns a copy of c to be written to config.yaml. Values
+// that still equal what .jcli.yaml files or environment variables set are
+// replaced by the values read from config.yaml, so overrides never leak into
+// the user config. Values changed since Load are kept.
+func (c *Config) withoutOverrides() *Config {
+	out := *c
+	if c.stored == nil {
+		return &out
+	}
+	stored, loaded := c.stored, c.loaded
+
+	restore(&out.Jira.URL, loaded.Jira.URL, stored.Jira.URL)
+	restore(&out.Jira.Deployment, loaded.Jira.Deployment, stored.Jira.Deployment)
+	restore(&out.Jira.Email, loaded.Jira.Email, stored.Jira.Email)
+	restore(&out.Jira.APIToken, loaded.Jira.APIToken, stored.Jira.APIToken)
+	restore(&out.Defaults.Project, loaded.Defaults.Project, stored.Defaults.Project)
+	restore(&out.Defaults.Status, loaded.Defaults.Status, stored.Defaults.Status)
+	restore(&out.Git.DetectIssue, loaded.Git.DetectIssue, stored.Git.DetectIssue)
+	restore(&out.Git.IssuePattern, loaded.Git.IssuePattern, stored.Git.IssuePattern)
+	restore(&out.Git.BranchTemplate, loaded.Git.BranchTemplate, stored.Git.BranchTemplate)
+	if slices.Equal(out.Git.ProjectKeys, loaded.Git.ProjectKeys) {
+		out.Git.ProjectKeys = stored.Git.ProjectKeys
+	}
+
+	out.Queries = maps.Clone(c.Queries)
+	for name, jql := range c.Queries {
+		if loadedJQL, ok := loaded.Queries[name]; !ok || loadedJQL != jql {
+			continue
+		}
+		if storedJQL, ok := stored.Queries[name]; ok {
+			out.Queries[name] = storedJQL
+		} else {
+			delete(out.Queries, name)
+		}
+	}
+
+	return &out
+}
+
+func restore[T comparable](current *T, loaded, stored T) {
+	if *current == loaded {
+		*current = stored
+	}
+}
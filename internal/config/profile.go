@@ -0,0 +1,214 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultProfile is the profile stored in the top-level jira and defaults
+// sections of config.yaml.
+const DefaultProfile = "default"
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// Profile holds the settings of one Jira site.
+type Profile struct {
+	Jira     JiraConfig `yaml:"jira"`
+	Defaults Defaults   `yaml:"defaults"`
+}
+
+// profileOverride is set from the --profile flag and wins over JCLI_PROFILE
+// and the profile selected with "jcli config profile use".
+var profileOverride string
+
+func SetProfileOverride(name string) {
+	profileOverride = name
+}
+
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// ActiveProfile returns the name of the profile whose settings are loaded
+// into Jira and Defaults.
+func (c *Config) ActiveProfile() string {
+	if c.active == "" {
+		return DefaultProfile
+	}
+	return c.active
+}
+
+// ProfileNames returns the default profile followed by the named profiles in
+// alphabetical order.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...)
+}
+
+func (c *Config) HasProfile(name string) bool {
+	if name == DefaultProfile {
+		return true
+	}
+	_, ok := c.Profiles[name]
+	return ok
+}
+
+// ProfileSettings returns the stored settings of the named profile.
+func (c *Config) ProfileSettings(name string) (Profile, bool) {
+	switch {
+	case name == c.ActiveProfile():
+		return Profile{Jira: c.Jira, Defaults: c.Defaults}, true
+	case name == DefaultProfile:
+		return c.base, true
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		return Profile{}, false
+	}
+	return *p, true
+}
+
+func (c *Config) AddProfile(name string, p Profile) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if c.HasProfile(name) {
+		return fmt.Errorf("profile %q %w", name, ErrExists)
+	}
+	if p.Defaults.Status == "" {
+		p.Defaults.Status = DefaultConfig().Defaults.Status
+	}
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]*Profile)
+	}
+	c.Profiles[name] = &p
+	return nil
+}
+
+func (c *Config) RemoveProfile(name string) error {
+	if name == DefaultProfile {
+		return fmt.Errorf("the %s profile cannot be removed", DefaultProfile)
+	}
+	if !c.HasProfile(name) {
+		return fmt.Errorf("profile %q not found", name)
+	}
+	delete(c.Profiles, name)
+	if c.Profile == name {
+		c.Profile = ""
+	}
+	return nil
+}
+
+// UseProfile makes name the profile used when neither --profile nor
+// JCLI_PROFILE is given.
+func (c *Config) UseProfile(name string) error {
+	if !c.HasProfile(name) {
+		return fmt.Errorf("profile %q not found", name)
+	}
+	if name == DefaultProfile {
+		name = ""
+	}
+	c.Profile = name
+	return nil
+}
+
+// ProfileName returns the name of the profile Load would activate, reading
+// only the profile key of config.yaml. It doesn't validate the name; it is
+// meant for commands that must be fast, such as "jcli prompt".
+func ProfileName() (string, error) {
+	var doc struct {
+		Profile string `yaml:"profile"`
+	}
+	if profileOverride == "" && os.Getenv("JCLI_PROFILE") == "" {
+		if err := readConfigFile(&doc); err != nil {
+			return "", err
+		}
+	}
+	return (&Config{Profile: doc.Profile}).resolveProfile(), nil
+}
+
+// readConfigFile decodes config.yaml into v, without migrating it or
+// applying local files and overrides. A missing file leaves v unchanged.
+func readConfigFile(v any) error {
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+	return nil
+}
+
+// resolveProfile picks the active profile: --profile, then JCLI_PROFILE,
+// then the persisted selection.
+func (c *Config) resolveProfile() string {
+	if profileOverride != "" {
+		return profileOverride
+	}
+	if env := os.Getenv("JCLI_PROFILE"); env != "" {
+		return env
+	}
+	if c.Profile != "" {
+		return c.Profile
+	}
+	return DefaultProfile
+}
+
+// activate loads the settings of the named profile into Jira and Defaults.
+// The top-level settings are kept aside so Save can write them back.
+func (c *Config) activate(name string) error {
+	if name == DefaultProfile {
+		c.active = ""
+		return nil
+	}
+
+	p, ok := c.Profiles[name]
+	if !ok {
+		return fmt.Errorf("profile %q not found", name)
+	}
+
+	c.base = Profile{Jira: c.Jira, Defaults: c.Defaults}
+	c.active = name
+	c.Jira = p.Jira
+	c.Defaults = p.Defaults
+	if c.Defaults.Status == "" {
+		c.Defaults.Status = DefaultConfig().Defaults.Status
+	}
+	return nil
+}
+
+// persisted returns the config as it is written to disk: the active named
+// profile's settings go back into its profile section.
+func (c *Config) persisted() *Config {
+	if c.active == "" {
+		return c
+	}
+
+	out := *c
+	out.Jira = c.base.Jira
+	out.Defaults = c.base.Defaults
+	if _, ok := c.Profiles[c.active]; ok {
+		out.Profiles = make(map[string]*Profile, len(c.Profiles))
+		for name, p := range c.Profiles {
+			out.Profiles[name] = p
+		}
+		out.Profiles[c.active] = &Profile{Jira: c.Jira, Defaults: c.Defaults}
+	}
+	return &out
+}
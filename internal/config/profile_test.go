@@ -0,0 +1,215 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func saveProfiles(t *testing.T) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Cleanup(func() { SetProfileOverride("") })
+
+	cfg := DefaultConfig()
+	cfg.Jira = JiraConfig{URL: "https://company.atlassian.net", Email: "me@company.com", APIToken: "company-token"}
+	cfg.Defaults.Project = "COMP"
+	if err := cfg.AddProfile("client", Profile{
+		Jira:     JiraConfig{URL: "https://client.atlassian.net", Email: "me@client.com", APIToken: "client-token"},
+		Defaults: Defaults{Project: "CLI"},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestProfileSelection(t *testing.T) {
+	saveProfiles(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ActiveProfile() != DefaultProfile || cfg.Jira.URL != "https://company.atlassian.net" {
+		t.Errorf("expected default profile, got %s (%s)", cfg.ActiveProfile(), cfg.Jira.URL)
+	}
+	if !slices.Equal(cfg.ProfileNames(), []string{"default", "client"}) {
+		t.Errorf("unexpected profile names %v", cfg.ProfileNames())
+	}
+
+	t.Setenv("JCLI_PROFILE", "client")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ActiveProfile() != "client" || cfg.Jira.URL != "https://client.atlassian.net" {
+		t.Errorf("expected client profile from JCLI_PROFILE, got %s (%s)", cfg.ActiveProfile(), cfg.Jira.URL)
+	}
+	if cfg.Defaults.Project != "CLI" || cfg.Defaults.Status != "In Progress" {
+		t.Errorf("expected client defaults with default status, got %+v", cfg.Defaults)
+	}
+	if p, _ := cfg.ProfileSettings(DefaultProfile); p.Jira.URL != "https://company.atlassian.net" {
+		t.Errorf("expected default profile settings to stay available, got %+v", p)
+	}
+
+	SetProfileOverride("default")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ActiveProfile() != DefaultProfile {
+		t.Errorf("expected --profile to win over JCLI_PROFILE, got %s", cfg.ActiveProfile())
+	}
+
+	SetProfileOverride("missing")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+}
+
+func TestUseProfilePersists(t *testing.T) {
+	saveProfiles(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cfg.UseProfile("client"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ActiveProfile() != "client" {
+		t.Errorf("expected persisted client profile, got %s", cfg.ActiveProfile())
+	}
+
+	if err := cfg.UseProfile("nope"); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+
+	if name, err := ProfileName(); err != nil || name != "client" {
+		t.Errorf("ProfileName() = %q, %v, want the persisted client profile", name, err)
+	}
+	t.Setenv("JCLI_PROFILE", "other")
+	if name, _ := ProfileName(); name != "other" {
+		t.Errorf("ProfileName() = %q, want JCLI_PROFILE", name)
+	}
+}
+
+func TestSaveKeepsProfilesSeparate(t *testing.T) {
+	saveProfiles(t)
+	t.Setenv("JCLI_PROFILE", "client")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg.Defaults.Project = "NEWCLI"
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Setenv("JCLI_PROFILE", "")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Defaults.Project != "COMP" {
+		t.Errorf("expected default profile project to be untouched, got %q", cfg.Defaults.Project)
+	}
+	if got := cfg.Profiles["client"].Defaults.Project; got != "NEWCLI" {
+		t.Errorf("expected client profile project to be updated, got %q", got)
+	}
+}
+
+func TestAddAndRemoveProfile(t *testing.T) {
+	cfg := DefaultConfig()
+
+	for _, name := range []string{"", "default", "bad name", "-x"} {
+		if err := cfg.AddProfile(name, Profile{}); err == nil {
+			t.Errorf("expected error adding profile %q", name)
+		}
+	}
+
+	if err := cfg.AddProfile("work", Profile{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cfg.AddProfile("work", Profile{}); err == nil {
+		t.Error("expected error for duplicate profile")
+	}
+	if err := cfg.UseProfile("work"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := cfg.RemoveProfile(DefaultProfile); err == nil {
+		t.Error("expected error removing the default profile")
+	}
+	if err := cfg.RemoveProfile("work"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Profile != "" {
+		t.Errorf("expected persisted selection to be reset, got %q", cfg.Profile)
+	}
+	if err := cfg.RemoveProfile("work"); err == nil {
+		t.Error("expected error removing an unknown profile")
+	}
+}
+
+func TestEncryptTokens(t *testing.T) {
+	saveProfiles(t)
+	t.Setenv("JCLI_PASSPHRASE", "test passphrase")
+	t.Setenv("JCLI_PROFILE", "client")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := OpenCredentialStore(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrated := cfg.EncryptTokens(store)
+	if !slices.Equal(migrated, []string{"default", "client"}) {
+		t.Errorf("expected both profiles to be migrated, got %v", migrated)
+	}
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	for profile, want := range map[string]string{"default": "company-token", "client": "client-token"} {
+		t.Setenv("JCLI_PROFILE", profile)
+		loaded, err := Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded.Jira.APIToken != "" || loaded.Jira.TokenStore != TokenStoreEncrypted {
+			t.Errorf("%s: expected token moved to the encrypted store, got %+v", profile, loaded.Jira)
+		}
+		if err := loaded.ResolveAPIToken(); err != nil {
+			t.Fatalf("%s: unexpected error: %v", profile, err)
+		}
+		if loaded.Jira.APIToken != want {
+			t.Errorf("%s: expected %q, got %q", profile, want, loaded.Jira.APIToken)
+		}
+	}
+
+	t.Setenv("JCLI_PASSPHRASE", "wrong")
+	loaded, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.ResolveAPIToken(); err == nil {
+		t.Error("expected error for wrong passphrase")
+	}
+}
@@ -0,0 +1,349 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Value types of schema keys.
+const (
+	TypeString = "string"
+	TypeBool   = "bool"
+	TypeInt    = "int"
+	TypeList   = "list"
+	TypeMap    = "map"
+)
+
+// Key describes a setting addressed by a dotted name such as "jira.url".
+// Lists are written comma-separated; map keys take one more name segment,
+// e.g. "queries.mine".
+type Key struct {
+	Name        string
+	Type        string
+	Enum        []string
+	URL         bool
+	Secret      bool
+	Description string
+
+	validate func(string) error
+	index    []int
+}
+
+type keyMeta struct {
+	description string
+	enum        []string
+	url         bool
+	secret      bool
+	validate    func(string) error
+}
+
+var projectKeyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// listFieldPattern matches the columns and sort fields of "issue list".
+var listFieldPattern = regexp.MustCompile(`^(key|summary|type|status|priority|assignee|reporter|created|updated|customfield_[0-9]+)$`)
+
+// keyMetadata annotates the keys derived from the Config struct.
+var keyMetadata = map[string]keyMeta{
+	"jira.url":                 {description: "Jira site URL", url: true},
+	"jira.deployment":          {description: "Jira deployment type", enum: []string{DeploymentCloud, DeploymentServer, DeploymentDataCenter}},
+	"jira.auth":                {description: "authentication method; empty for API tokens", enum: []string{AuthOAuth}},
+	"jira.email":               {description: "account email (Jira Cloud)"},
+	"jira.api_token":           {description: "API token or Personal Access Token", secret: true},
+	"jira.credential_helper":   {description: "git-style credential helper for the API token"},
+	"jira.api_token_command":   {description: "shell command printing the API token"},
+	"jira.token_store":         {description: "encrypted store for the API token", enum: []string{TokenStoreEncrypted}},
+	"jira.oauth.client_id":     {description: "OAuth 2.0 app client ID"},
+	"jira.oauth.client_secret": {description: "OAuth 2.0 app client secret", secret: true},
+	"jira.oauth.callback_port": {description: "loopback port of the OAuth callback (default 8976)", validate: validatePort},
+	"jira.oauth.scopes":        {description: "OAuth scopes to request"},
+	"jira.oauth.auth_url":      {description: "OAuth authorization server URL", url: true},
+	"jira.oauth.api_url":       {description: "Atlassian API gateway URL", url: true},
+	"jira.proxy":               {description: "HTTP(S) proxy URL, may include user:password", url: true},
+	"jira.tls.ca_files":        {description: "PEM CA bundles trusted in addition to the system roots"},
+	"jira.tls.client_cert":     {description: "PEM client certificate for mutual TLS"},
+	"jira.tls.client_key":      {description: "PEM private key of the client certificate"},
+	"jira.tls.min_version":     {description: "minimum TLS version", enum: []string{"1.0", "1.1", "1.2", "1.3"}},
+	"defaults.project":         {description: "default project key", validate: validateProjectKey},
+	"defaults.status":          {description: "default status filter"},
+	"git.detect_issue":         {description: "detect the current issue from the git branch"},
+	"git.issue_pattern":        {description: "regular expression matching issue keys in branch names", validate: validateRegexp},
+	"git.project_keys":         {description: "project keys recognized in branch names", validate: validateProjectKey},
+	"git.branch_template":      {description: "branch name template, e.g. {key}-{summary}-{random}"},
+	"list.columns":             {description: "columns of 'issue list'", validate: validateListField},
+	"list.sort":                {description: "sort order of 'issue list', e.g. -updated", validate: validateListSort},
+	"queries":                  {description: "named JQL queries for 'issue select --query'"},
+	"aliases":                  {description: "command aliases, e.g. mine: issue list --query mine; a leading ! runs a shell command", validate: validateAlias},
+}
+
+// schemaSections are the parts of config.yaml exposed through the schema;
+// version and profiles are managed by jcli itself.
+var schemaSections = []string{"jira", "defaults", "git", "list", "queries", "aliases"}
+
+var schema = buildSchema()
+
+// Schema returns all keys in the order of the config file.
+func Schema() []Key {
+	return slices.Clone(schema)
+}
+
+func buildSchema() []Key {
+	var keys []Key
+	walkSchema(reflect.TypeOf(Config{}), "", nil, &keys)
+	return keys
+}
+
+func walkSchema(t reflect.Type, prefix string, index []int, keys *[]Key) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		if prefix == "" && !slices.Contains(schemaSections, name) {
+			continue
+		}
+
+		fullName := prefix + name
+		fieldIndex := append(slices.Clone(index), i)
+
+		var typ string
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			walkSchema(field.Type, fullName+".", fieldIndex, keys)
+			continue
+		case reflect.String:
+			typ = TypeString
+		case reflect.Bool:
+			typ = TypeBool
+		case reflect.Int:
+			typ = TypeInt
+		case reflect.Slice:
+			typ = TypeList
+		case reflect.Map:
+			typ = TypeMap
+		default:
+			continue
+		}
+
+		meta := keyMetadata[fullName]
+		*keys = append(*keys, Key{
+			Name:        fullName,
+			Type:        typ,
+			Enum:        meta.enum,
+			URL:         meta.url,
+			Secret:      meta.secret,
+			Description: meta.description,
+			validate:    meta.validate,
+			index:       fieldIndex,
+		})
+	}
+}
+
+// LookupKey finds the schema key for name. For map keys it also returns the
+// entry name, e.g. "mine" for "queries.mine".
+func LookupKey(name string) (Key, string, error) {
+	for _, key := range schema {
+		if key.Name == name {
+			if key.Type == TypeMap {
+				return Key{}, "", &Error{Err: fmt.Errorf("%s needs an entry name, e.g. %s.<name>", name, name)}
+			}
+			return key, "", nil
+		}
+		if key.Type == TypeMap && strings.HasPrefix(name, key.Name+".") {
+			return key, strings.TrimPrefix(name, key.Name+"."), nil
+		}
+	}
+	return Key{}, "", &Error{Err: fmt.Errorf("unknown config key %q (see 'jcli config list --all')", name)}
+}
+
+// Validate checks a raw value for the key.
+func (k Key) Validate(value string) error {
+	if err := k.validateValue(value); err != nil {
+		return &Error{Err: err}
+	}
+	return nil
+}
+
+func (k Key) validateValue(value string) error {
+	switch k.Type {
+	case TypeBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%s must be true or false, got %q", k.Name, value)
+		}
+		return nil
+	case TypeInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%s must be an integer, got %q", k.Name, value)
+		}
+	}
+
+	values := []string{value}
+	if k.Type == TypeList {
+		values = splitList(value)
+	}
+	for _, v := range values {
+		if len(k.Enum) > 0 && !slices.Contains(k.Enum, v) {
+			return fmt.Errorf("invalid value %q for %s (allowed: %s)", v, k.Name, strings.Join(k.Enum, ", "))
+		}
+		if k.URL {
+			if err := validateURL(v); err != nil {
+				return fmt.Errorf("invalid value for %s: %w", k.Name, err)
+			}
+		}
+		if k.validate != nil {
+			if err := k.validate(v); err != nil {
+				return fmt.Errorf("invalid value for %s: %w", k.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Get returns the value of the dotted key in the active profile. Lists are
+// joined with commas.
+func (c *Config) Get(name string) (string, error) {
+	key, entry, err := LookupKey(name)
+	if err != nil {
+		return "", err
+	}
+
+	field := reflect.ValueOf(c).Elem().FieldByIndex(key.index)
+	switch key.Type {
+	case TypeMap:
+		value, ok := field.Interface().(map[string]string)[entry]
+		if !ok {
+			return "", fmt.Errorf("%s is not set", name)
+		}
+		return value, nil
+	case TypeList:
+		return strings.Join(field.Interface().([]string), ","), nil
+	default:
+		return fmt.Sprint(field.Interface()), nil
+	}
+}
+
+// Entries returns the sorted entry names of the map key, e.g. the query
+// names for "queries".
+func (c *Config) Entries(key Key) []string {
+	if key.Type != TypeMap {
+		return nil
+	}
+	field := reflect.ValueOf(c).Elem().FieldByIndex(key.index)
+	return slices.Sorted(maps.Keys(field.Interface().(map[string]string)))
+}
+
+// Set validates value and assigns it to the dotted key of the active
+// profile.
+func (c *Config) Set(name, value string) error {
+	key, entry, err := LookupKey(name)
+	if err != nil {
+		return err
+	}
+	if err := key.Validate(value); err != nil {
+		return err
+	}
+
+	field := reflect.ValueOf(c).Elem().FieldByIndex(key.index)
+	switch key.Type {
+	case TypeString:
+		field.SetString(value)
+	case TypeBool:
+		b, _ := strconv.ParseBool(value)
+		field.SetBool(b)
+	case TypeInt:
+		n, _ := strconv.Atoi(value)
+		field.SetInt(int64(n))
+	case TypeList:
+		field.Set(reflect.ValueOf(splitList(value)))
+	case TypeMap:
+		if field.IsNil() {
+			field.Set(reflect.MakeMap(field.Type()))
+		}
+		field.SetMapIndex(reflect.ValueOf(entry), reflect.ValueOf(value))
+	}
+	return nil
+}
+
+// Unset restores the built-in default of the dotted key, or removes a map
+// entry.
+func (c *Config) Unset(name string) error {
+	key, entry, err := LookupKey(name)
+	if err != nil {
+		return err
+	}
+
+	field := reflect.ValueOf(c).Elem().FieldByIndex(key.index)
+	if key.Type == TypeMap {
+		if !field.IsNil() {
+			field.SetMapIndex(reflect.ValueOf(entry), reflect.Value{})
+		}
+		return nil
+	}
+	field.Set(reflect.ValueOf(DefaultConfig()).Elem().FieldByIndex(key.index))
+	return nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func validateURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return fmt.Errorf("%q is not an absolute http(s) URL", value)
+	}
+	return nil
+}
+
+// validatePort rejects 0 as well: unset ports read as 0 and get a default,
+// so a free port cannot be asked for.
+func validatePort(value string) error {
+	if port, _ := strconv.Atoi(value); port < 1 || port > 65535 {
+		return fmt.Errorf("port %s is out of range (1-65535)", value)
+	}
+	return nil
+}
+
+func validateProjectKey(value string) error {
+	if !projectKeyPattern.MatchString(value) {
+		return fmt.Errorf("%q is not a project key", value)
+	}
+	return nil
+}
+
+func validateListField(value string) error {
+	if !listFieldPattern.MatchString(value) {
+		return fmt.Errorf("unknown field %q (use key, summary, type, status, priority, assignee, reporter, created, updated or customfield_<id>)", value)
+	}
+	return nil
+}
+
+func validateListSort(value string) error {
+	return validateListField(strings.TrimPrefix(value, "-"))
+}
+
+func validateAlias(value string) error {
+	if strings.TrimSpace(strings.TrimPrefix(value, "!")) == "" {
+		return fmt.Errorf("empty alias")
+	}
+	return nil
+}
+
+func validateRegexp(value string) error {
+	if _, err := regexp.Compile(value); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	names := make(map[string]Key)
+	for _, key := range Schema() {
+		names[key.Name] = key
+	}
+
+	for name, typ := range map[string]string{
+		"jira.url":                 TypeString,
+		"jira.oauth.callback_port": TypeInt,
+		"jira.oauth.scopes":        TypeList,
+		"defaults.project":         TypeString,
+		"git.detect_issue":         TypeBool,
+		"git.project_keys":         TypeList,
+		"queries":                  TypeMap,
+	} {
+		key, ok := names[name]
+		if !ok {
+			t.Errorf("schema is missing %s", name)
+			continue
+		}
+		if key.Type != typ {
+			t.Errorf("%s has type %s, want %s", name, key.Type, typ)
+		}
+		if key.Description == "" {
+			t.Errorf("%s has no description", name)
+		}
+	}
+
+	for _, name := range []string{"version", "profile", "profiles.work.jira.url"} {
+		if _, ok := names[name]; ok {
+			t.Errorf("schema must not expose %s", name)
+		}
+	}
+	if !names["jira.api_token"].Secret {
+		t.Error("jira.api_token must be secret")
+	}
+}
+
+func TestConfig_SetGet(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+		want  string
+	}{
+		{key: "jira.url", value: "https://company.atlassian.net", want: "https://company.atlassian.net"},
+		{key: "jira.deployment", value: "server", want: "server"},
+		{key: "jira.oauth.callback_port", value: "9000", want: "9000"},
+		{key: "defaults.project", value: "PROJ", want: "PROJ"},
+		{key: "git.detect_issue", value: "true", want: "true"},
+		{key: "git.project_keys", value: "PROJ, OPS,", want: "PROJ,OPS"},
+		{key: "list.columns", value: "key,status,customfield_10016", want: "key,status,customfield_10016"},
+		{key: "list.sort", value: "-updated,key", want: "-updated,key"},
+		{key: "queries.mine", value: "assignee = currentUser()", want: "assignee = currentUser()"},
+		{key: "aliases.mine", value: "issue list --query mine", want: "issue list --query mine"},
+	}
+
+	cfg := DefaultConfig()
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if err := cfg.Set(tt.key, tt.value); err != nil {
+				t.Fatalf("Set(%q, %q) error = %v", tt.key, tt.value, err)
+			}
+			got, err := cfg.Get(tt.key)
+			if err != nil {
+				t.Fatalf("Get(%q) error = %v", tt.key, err)
+			}
+			if got != tt.want {
+				t.Errorf("Get(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+
+	if cfg.Jira.OAuth.CallbackPort != 9000 || !cfg.Git.DetectIssue || len(cfg.Git.ProjectKeys) != 2 {
+		t.Errorf("values not assigned to the struct: %+v", cfg)
+	}
+}
+
+func TestConfig_SetInvalid(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		wantErr string
+	}{
+		{key: "jira.urls", value: "x", wantErr: "unknown config key"},
+		{key: "version", value: "2", wantErr: "unknown config key"},
+		{key: "queries", value: "x", wantErr: "needs an entry name"},
+		{key: "jira.url", value: "company.atlassian.net", wantErr: "not an absolute http(s) URL"},
+		{key: "jira.deployment", value: "onprem", wantErr: "allowed: cloud, server, datacenter"},
+		{key: "git.detect_issue", value: "maybe", wantErr: "must be true or false"},
+		{key: "jira.oauth.callback_port", value: "abc", wantErr: "must be an integer"},
+		{key: "jira.oauth.callback_port", value: "70000", wantErr: "out of range"},
+		{key: "jira.oauth.callback_port", value: "0", wantErr: "out of range"},
+		{key: "defaults.project", value: "MY PROJ", wantErr: "not a project key"},
+		{key: "git.project_keys", value: "PROJ,1X", wantErr: "not a project key"},
+		{key: "git.issue_pattern", value: "(", wantErr: "invalid value for git.issue_pattern"},
+		{key: "list.columns", value: "key,labels", wantErr: `unknown field "labels"`},
+		{key: "list.sort", value: "-customfield_x", wantErr: "unknown field"},
+		{key: "aliases.build", value: "! ", wantErr: "empty alias"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			err := DefaultConfig().Set(tt.key, tt.value)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Set(%q, %q) error = %v, want %q", tt.key, tt.value, err, tt.wantErr)
+			}
+			var cfgErr *Error
+			if !errors.As(err, &cfgErr) {
+				t.Errorf("Set(%q, %q) error = %T, want *Error", tt.key, tt.value, err)
+			}
+		})
+	}
+}
+
+func TestConfig_Unset(t *testing.T) {
+	cfg := DefaultConfig()
+	for key, value := range map[string]string{
+		"defaults.status":  "Done",
+		"defaults.project": "PROJ",
+		"queries.mine":     "assignee = currentUser()",
+	} {
+		if err := cfg.Set(key, value); err != nil {
+			t.Fatalf("Set(%q) error = %v", key, err)
+		}
+		if err := cfg.Unset(key); err != nil {
+			t.Fatalf("Unset(%q) error = %v", key, err)
+		}
+	}
+
+	if cfg.Defaults.Status != "In Progress" {
+		t.Errorf("expected defaults.status to fall back to the default, got %q", cfg.Defaults.Status)
+	}
+	if cfg.Defaults.Project != "" {
+		t.Errorf("expected defaults.project to be cleared, got %q", cfg.Defaults.Project)
+	}
+	if _, err := cfg.Get("queries.mine"); err == nil {
+		t.Error("expected queries.mine to be removed")
+	}
+}
+
+func TestConfig_Entries(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Queries = map[string]string{"mine": "assignee = currentUser()", "bugs": "type = Bug"}
+	cfg.Aliases = map[string]string{"st": "issue current"}
+
+	want := map[string][]string{
+		"queries": {"bugs", "mine"},
+		"aliases": {"st"},
+	}
+	for _, key := range Schema() {
+		got := cfg.Entries(key)
+		if key.Type != TypeMap {
+			if got != nil {
+				t.Errorf("Entries(%s) = %v, want nil", key.Name, got)
+			}
+			continue
+		}
+		if !slices.Equal(got, want[key.Name]) {
+			t.Errorf("Entries(%s) = %v, want %v", key.Name, got, want[key.Name])
+		}
+		for _, name := range got {
+			if _, err := cfg.Get(key.Name + "." + name); err != nil {
+				t.Errorf("Get(%s.%s) error = %v", key.Name, name, err)
+			}
+		}
+	}
+}
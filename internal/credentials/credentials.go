@@ -0,0 +1,152 @@
+// Package credentials obtains Jira API tokens from external programs instead
+// of the plaintext config file. Credential helpers speak the git credential
+// helper protocol, so helpers written for git (osxkeychain, libsecret, ...)
+// work unchanged.
+package credentials
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Helper runs a credential helper configured like git's credential.helper:
+// "!cmd" runs cmd in the shell, an absolute path runs that program and a
+// bare name runs "git credential-<name>". Arguments may follow the name.
+type Helper struct {
+	Command string
+}
+
+func NewHelper(command string) *Helper {
+	return &Helper{Command: command}
+}
+
+// Get asks the helper for the token of username at the Jira site baseURL.
+func (h *Helper) Get(baseURL, username string) (string, error) {
+	input, err := describe(baseURL, username, "")
+	if err != nil {
+		return "", err
+	}
+
+	out, err := h.run("get", input)
+	if err != nil {
+		return "", err
+	}
+
+	token := parse(out)["password"]
+	if token == "" {
+		return "", fmt.Errorf("credential helper %q returned no token", h.Command)
+	}
+	return token, nil
+}
+
+// Store hands the token to the helper for safekeeping.
+func (h *Helper) Store(baseURL, username, token string) error {
+	input, err := describe(baseURL, username, token)
+	if err != nil {
+		return err
+	}
+	_, err = h.run("store", input)
+	return err
+}
+
+// Erase asks the helper to forget the token.
+func (h *Helper) Erase(baseURL, username string) error {
+	input, err := describe(baseURL, username, "")
+	if err != nil {
+		return err
+	}
+	_, err = h.run("erase", input)
+	return err
+}
+
+func (h *Helper) shellCommand(action string) string {
+	command := strings.TrimSpace(h.Command)
+	switch {
+	case strings.HasPrefix(command, "!"):
+		return strings.TrimPrefix(command, "!") + " " + action
+	case filepath.IsAbs(strings.Fields(command)[0]):
+		return command + " " + action
+	default:
+		return "git credential-" + command + " " + action
+	}
+}
+
+func (h *Helper) run(action string, input []byte) ([]byte, error) {
+	if strings.TrimSpace(h.Command) == "" {
+		return nil, fmt.Errorf("no credential helper configured")
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command("sh", "-c", h.shellCommand(action))
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("credential helper %q failed on %s: %w", h.Command, action, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// describe encodes the request in the git credential format.
+func describe(baseURL, username, password string) ([]byte, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid Jira URL %q", baseURL)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "protocol=%s\n", u.Scheme)
+	fmt.Fprintf(&b, "host=%s\n", u.Host)
+	if path := strings.Trim(u.Path, "/"); path != "" {
+		fmt.Fprintf(&b, "path=%s\n", path)
+	}
+	if username != "" {
+		fmt.Fprintf(&b, "username=%s\n", username)
+	}
+	if password != "" {
+		fmt.Fprintf(&b, "password=%s\n", password)
+	}
+	b.WriteString("\n")
+	return b.Bytes(), nil
+}
+
+func parse(out []byte) map[string]string {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if ok {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// RunTokenCommand runs command in the shell and returns its trimmed output,
+// e.g. "pass show jira/token" or "vault kv get -field=token secret/jira".
+func RunTokenCommand(command string) (string, error) {
+	var stdout bytes.Buffer
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("api_token_command %q failed: %w", command, err)
+	}
+
+	// Only the first line counts, so "pass show" entries with metadata work.
+	token, _, _ := strings.Cut(stdout.String(), "\n")
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", fmt.Errorf("api_token_command %q printed no token", command)
+	}
+	return token, nil
+}
@@ -0,0 +1,120 @@
+package credentials
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stubHelper writes a helper script that logs each request to a file and
+// answers "get" with a fixed token.
+func stubHelper(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	log := filepath.Join(dir, "requests.log")
+	script := filepath.Join(dir, "helper.sh")
+	content := `#!/bin/sh
+{ echo "action=$1"; cat; } >> "` + log + `"
+if [ "$1" = "get" ]; then
+  echo "username=me@example.com"
+  echo "password=helper-token"
+fi
+`
+	if err := os.WriteFile(script, []byte(content), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return script, log
+}
+
+func TestHelperGet(t *testing.T) {
+	script, log := stubHelper(t)
+	helper := NewHelper(script)
+
+	token, err := helper.Get("https://company.atlassian.net", "me@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token != "helper-token" {
+		t.Errorf("expected helper-token, got %q", token)
+	}
+
+	data, _ := os.ReadFile(log)
+	want := "action=get\nprotocol=https\nhost=company.atlassian.net\nusername=me@example.com\n\n"
+	if string(data) != want {
+		t.Errorf("unexpected helper input:\n%s", data)
+	}
+}
+
+func TestHelperStoreAndErase(t *testing.T) {
+	script, log := stubHelper(t)
+	helper := NewHelper(script)
+
+	if err := helper.Store("https://jira.example.com/jira", "me", "secret"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := helper.Erase("https://jira.example.com/jira", "me"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(log)
+	content := string(data)
+	if !strings.Contains(content, "action=store\nprotocol=https\nhost=jira.example.com\npath=jira\nusername=me\npassword=secret\n") {
+		t.Errorf("unexpected store input:\n%s", content)
+	}
+	if !strings.Contains(content, "action=erase\n") || strings.Count(content, "password=") != 1 {
+		t.Errorf("unexpected erase input:\n%s", content)
+	}
+}
+
+func TestHelperShellCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"osxkeychain", "git credential-osxkeychain get"},
+		{"store --file ~/.jira-creds", "git credential-store --file ~/.jira-creds get"},
+		{"/usr/local/bin/helper --flag", "/usr/local/bin/helper --flag get"},
+		{"!f() { echo password=x; }; f", "f() { echo password=x; }; f get"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if got := NewHelper(tt.command).shellCommand("get"); got != tt.want {
+				t.Errorf("shellCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHelperErrors(t *testing.T) {
+	if _, err := NewHelper("!true").Get("https://company.atlassian.net", "me"); err == nil {
+		t.Error("expected error when helper returns no token")
+	}
+	if _, err := NewHelper("!exit 1").Get("https://company.atlassian.net", "me"); err == nil {
+		t.Error("expected error when helper fails")
+	}
+	if _, err := NewHelper("").Get("https://company.atlassian.net", "me"); err == nil {
+		t.Error("expected error without helper")
+	}
+	if _, err := NewHelper("!true").Get("not a url", "me"); err == nil {
+		t.Error("expected error for invalid URL")
+	}
+}
+
+func TestRunTokenCommand(t *testing.T) {
+	token, err := RunTokenCommand(`printf 'pass-token\nlogin: me\n'`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token != "pass-token" {
+		t.Errorf("expected first line of output, got %q", token)
+	}
+
+	if _, err := RunTokenCommand("true"); err == nil {
+		t.Error("expected error for empty output")
+	}
+	if _, err := RunTokenCommand("exit 3"); err == nil {
+		t.Error("expected error for failing command")
+	}
+}
@@ -0,0 +1,188 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tutunak/jcli/internal/lockedfile"
+	"golang.org/x/crypto/scrypt"
+)
+
+// ErrWrongPassphrase is returned when the store cannot be decrypted.
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted credentials file")
+
+// scrypt parameters recommended for interactive logins.
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	keyLength    = 32
+	saltLength   = 16
+	storeVersion = 1
+)
+
+// storeAAD binds the ciphertext to this file format.
+var storeAAD = []byte("jcli-credentials-v1")
+
+// envelope is the on-disk format of the encrypted store.
+type envelope struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Store is a passphrase-encrypted file of API tokens, keyed by Jira site and
+// username. Keys are derived with scrypt and tokens sealed with AES-256-GCM.
+type Store struct {
+	path       string
+	passphrase string
+	tokens     map[string]string
+}
+
+// OpenStore decrypts the store at path. A missing file yields an empty store
+// that is created on Save.
+func OpenStore(path, passphrase string) (*Store, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase required to unlock %s", path)
+	}
+
+	s := &Store{path: path, passphrase: passphrase, tokens: make(map[string]string)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials file: %w", err)
+	}
+	if env.Version != storeVersion || env.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported credentials file (version %d, kdf %q)", env.Version, env.KDF)
+	}
+	// Never derive with more memory or time than Save asks for: a corrupted
+	// or tampered file could otherwise force huge allocations.
+	if env.N < 2 || env.N > scryptN || env.R < 1 || env.R > scryptR || env.P < 1 || env.P > scryptP {
+		return nil, fmt.Errorf("unsupported scrypt parameters in credentials file (n=%d, r=%d, p=%d)", env.N, env.R, env.P)
+	}
+
+	gcm, err := newGCM(passphrase, env.Salt, env.N, env.R, env.P)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != gcm.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plaintext, err := gcm.Open(nil, env.Nonce, env.Ciphertext, storeAAD)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	if err := json.Unmarshal(plaintext, &s.tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted credentials: %w", err)
+	}
+	return s, nil
+}
+
+func (s *Store) Get(baseURL, username string) (string, bool) {
+	token, ok := s.tokens[storeKey(baseURL, username)]
+	return token, ok
+}
+
+func (s *Store) Set(baseURL, username, token string) {
+	s.tokens[storeKey(baseURL, username)] = token
+}
+
+func (s *Store) Delete(baseURL, username string) {
+	delete(s.tokens, storeKey(baseURL, username))
+}
+
+// Save encrypts the tokens with a fresh salt and nonce and writes the file.
+func (s *Store) Save() error {
+	plaintext, err := json.Marshal(s.tokens)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+
+	env := envelope{Version: storeVersion, KDF: "scrypt", N: scryptN, R: scryptR, P: scryptP}
+	env.Salt = make([]byte, saltLength)
+	if _, err := rand.Read(env.Salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	gcm, err := newGCM(s.passphrase, env.Salt, env.N, env.R, env.P)
+	if err != nil {
+		return err
+	}
+	env.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	env.Ciphertext = gcm.Seal(nil, env.Nonce, plaintext, storeAAD)
+
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials file: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create credentials directory: %w", err)
+	}
+	if err := lockedfile.Write(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write credentials file: %w", err)
+	}
+	return nil
+}
+
+func newGCM(passphrase string, salt []byte, n, r, p int) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, n, r, p, keyLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// storeKey identifies a token by username and site, e.g.
+// "me@company.com@company.atlassian.net".
+func storeKey(baseURL, username string) string {
+	host := baseURL
+	if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+		host = strings.TrimSuffix(u.Host+u.Path, "/")
+	}
+	return username + "@" + host
+}
+
+// Prompt asks the user for the store passphrase; confirm requests a second
+// entry when a new passphrase is chosen. It is set by the command layer.
+var Prompt func(confirm bool) (string, error)
+
+// Passphrase returns JCLI_PASSPHRASE or, if unset, asks via Prompt.
+func Passphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv("JCLI_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+	if Prompt == nil {
+		return "", fmt.Errorf("passphrase required: set JCLI_PASSPHRASE")
+	}
+	return Prompt(confirm)
+}
@@ -0,0 +1,131 @@
+package credentials
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+
+	store, err := OpenStore(path, "correct horse")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := store.Get("https://company.atlassian.net", "me@company.com"); ok {
+		t.Error("expected empty store")
+	}
+
+	store.Set("https://company.atlassian.net/", "me@company.com", "secret-token")
+	store.Set("https://client.atlassian.net", "me@client.com", "client-token")
+	if err := store.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "secret-token") || strings.Contains(string(data), "me@company.com") {
+		t.Error("credentials file contains plaintext")
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	reopened, err := OpenStore(path, "correct horse")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token, ok := reopened.Get("https://company.atlassian.net", "me@company.com"); !ok || token != "secret-token" {
+		t.Errorf("expected secret-token, got %q", token)
+	}
+
+	reopened.Delete("https://client.atlassian.net", "me@client.com")
+	if _, ok := reopened.Get("https://client.atlassian.net", "me@client.com"); ok {
+		t.Error("expected token to be deleted")
+	}
+}
+
+func TestStoreWrongPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+
+	store, err := OpenStore(path, "right")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Set("https://company.atlassian.net", "me", "token")
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := OpenStore(path, "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("expected ErrWrongPassphrase, got %v", err)
+	}
+	if _, err := OpenStore(path, ""); err == nil {
+		t.Error("expected error for empty passphrase")
+	}
+}
+
+func TestStoreRejectsExpensiveParameters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+
+	store, err := OpenStore(path, "right")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Set("https://company.atlassian.net", "me", "token")
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		from string
+		to   string
+	}{
+		{"n", `"n": 32768`, `"n": 1073741824`},
+		{"r", `"r": 8`, `"r": 1024`},
+		{"p", `"p": 1`, `"p": 64`},
+		{"zero n", `"n": 32768`, `"n": 0`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := strings.Replace(string(data), tt.from, tt.to, 1)
+			if tampered == string(data) {
+				t.Fatalf("credentials file has no %s", tt.from)
+			}
+			if err := os.WriteFile(path, []byte(tampered), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := OpenStore(path, "right"); err == nil || !strings.Contains(err.Error(), "scrypt parameters") {
+				t.Errorf("expected unsupported scrypt parameters, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPassphrase(t *testing.T) {
+	t.Cleanup(func() { Prompt = nil })
+
+	t.Setenv("JCLI_PASSPHRASE", "from-env")
+	Prompt = func(bool) (string, error) { return "from-prompt", nil }
+	if got, _ := Passphrase(false); got != "from-env" {
+		t.Errorf("expected passphrase from environment, got %q", got)
+	}
+
+	t.Setenv("JCLI_PASSPHRASE", "")
+	if got, _ := Passphrase(false); got != "from-prompt" {
+		t.Errorf("expected passphrase from prompt, got %q", got)
+	}
+
+	Prompt = nil
+	if _, err := Passphrase(false); err == nil {
+		t.Error("expected error without environment or prompt")
+	}
+}
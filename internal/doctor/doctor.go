@@ -0,0 +1,310 @@
+// Package doctor diagnoses configuration, connectivity and credential
+// problems. Each check yields a Result with a suggested fix on failure.
+package doctor
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tutunak/jcli/internal/config"
+	"github.com/tutunak/jcli/internal/jira"
+)
+
+type Status int
+
+const (
+	Pass Status = iota
+	Warn
+	Fail
+	Skip
+)
+
+func (s Status) String() string {
+	switch s {
+	case Pass:
+		return "PASS"
+	case Warn:
+		return "WARN"
+	case Fail:
+		return "FAIL"
+	default:
+		return "SKIP"
+	}
+}
+
+// Result is the outcome of one check. Fix suggests how to resolve a failure
+// or warning.
+type Result struct {
+	Name   string
+	Status Status
+	Detail string
+	Fix    string
+}
+
+// Doctor runs all checks for the active profile. NewClient is called once
+// the configuration is valid; the transport from NewTransport carries the TLS
+// check, so that proxy and CA settings apply to it. When the configuration
+// could not be loaded, Config is nil and LoadErr says why.
+type Doctor struct {
+	Config       *config.Config
+	LoadErr      error
+	ConfigPath   string
+	NewClient    func() (jira.Client, error)
+	NewTransport func() (http.RoundTripper, error)
+	ConfigDir    string
+	StateDir     string
+	Timeout      time.Duration
+}
+
+// Run executes the checks in order. Checks that depend on an earlier failed
+// check are skipped.
+func (d *Doctor) Run() []Result {
+	cfg := d.Config
+	if cfg == nil {
+		results := []Result{CheckLoad(d.LoadErr, d.ConfigPath)}
+		for _, name := range []string{"URL", "TLS", "Credentials", "Project", "Status"} {
+			results = append(results, Result{Name: name, Status: Skip, Detail: "skipped: configuration could not be loaded"})
+		}
+		return append(results,
+			CheckWritable("Config directory", d.ConfigDir),
+			CheckWritable("State directory", d.StateDir),
+		)
+	}
+	var results []Result
+
+	configOK := CheckConfig(cfg)
+	results = append(results, configOK)
+
+	urlOK := CheckURL(cfg.Jira.URL)
+	results = append(results, urlOK)
+
+	transport, err := d.newTransport()
+	if err != nil {
+		results = append(results, Result{Name: "Network", Status: Fail, Detail: err.Error(),
+			Fix: "check jira.proxy and the jira.tls settings"})
+	}
+
+	tlsOK := Result{Name: "TLS", Status: Skip, Detail: "skipped: invalid URL or network settings"}
+	if urlOK.Status != Fail && err == nil {
+		tlsOK = CheckTLS(cfg.Jira.URL, transport, d.timeout())
+	}
+	results = append(results, tlsOK)
+
+	var client jira.Client
+	credentials := Result{Name: "Credentials", Status: Skip, Detail: "skipped: configuration or connection failed"}
+	if configOK.Status != Fail && urlOK.Status != Fail && tlsOK.Status != Fail && err == nil {
+		if client, err = d.NewClient(); err != nil {
+			credentials = Result{Name: "Credentials", Status: Fail, Detail: err.Error(),
+				Fix: "check jira.api_token_command, jira.credential_helper or the token store"}
+		} else {
+			credentials = CheckCredentials(client)
+		}
+	}
+	results = append(results, credentials)
+
+	project := Result{Name: "Project", Status: Skip, Detail: "skipped: not authenticated"}
+	statuses := Result{Name: "Status", Status: Skip, Detail: "skipped: project not verified"}
+	if credentials.Status == Pass {
+		project = CheckProject(client, cfg.Defaults.Project)
+		if project.Status == Pass {
+			statuses = CheckStatus(client, cfg.Defaults.Project, cfg.Defaults.Status)
+		}
+	}
+	results = append(results, project, statuses)
+
+	results = append(results,
+		CheckWritable("Config directory", d.ConfigDir),
+		CheckWritable("State directory", d.StateDir),
+	)
+	return results
+}
+
+func (d *Doctor) newTransport() (http.RoundTripper, error) {
+	if d.NewTransport == nil {
+		return nil, nil
+	}
+	return d.NewTransport()
+}
+
+func (d *Doctor) timeout() time.Duration {
+	if d.Timeout > 0 {
+		return d.Timeout
+	}
+	return 10 * time.Second
+}
+
+// CheckLoad reports a configuration that could not be loaded, such as a
+// malformed config file or an unknown profile.
+func CheckLoad(err error, path string) Result {
+	return Result{Name: "Config", Status: Fail, Detail: err.Error(),
+		Fix: fmt.Sprintf("correct %s, or select an existing profile with --profile, JCLI_PROFILE or its profile key", path)}
+}
+
+func CheckConfig(cfg *config.Config) Result {
+	if err := cfg.Validate(); err != nil {
+		return Result{Name: "Config", Status: Fail, Detail: err.Error(),
+			Fix: "run 'jcli config credentials' or edit the config file"}
+	}
+	return Result{Name: "Config", Status: Pass, Detail: "valid"}
+}
+
+// CheckURL verifies that rawURL is an absolute http(s) URL. Plain HTTP is
+// reported as a warning because credentials would be sent unencrypted.
+func CheckURL(rawURL string) Result {
+	const name = "URL"
+	if rawURL == "" {
+		return Result{Name: name, Status: Fail, Detail: "jira.url is not set",
+			Fix: "run 'jcli config credentials'"}
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return Result{Name: name, Status: Fail, Detail: fmt.Sprintf("%q is not an http(s) URL", rawURL),
+			Fix: "use the site URL, e.g. https://company.atlassian.net"}
+	}
+	if u.Scheme == "http" {
+		return Result{Name: name, Status: Warn, Detail: rawURL + " does not use HTTPS",
+			Fix: "use https:// so credentials are not sent in plain text"}
+	}
+	if u.Path != "" && u.Path != "/" && strings.HasSuffix(u.Host, ".atlassian.net") {
+		return Result{Name: name, Status: Warn, Detail: "URL has a path: " + u.Path,
+			Fix: "Jira Cloud URLs have no path, e.g. https://" + u.Host}
+	}
+	return Result{Name: name, Status: Pass, Detail: rawURL}
+}
+
+// CheckTLS connects to rawURL over HTTPS and reports when the server
+// certificate expires.
+func CheckTLS(rawURL string, transport http.RoundTripper, timeout time.Duration) Result {
+	const name = "TLS"
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" {
+		return Result{Name: name, Status: Skip, Detail: "skipped: not an HTTPS URL"}
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Head(rawURL)
+	if err != nil {
+		var certErr *tls.CertificateVerificationError
+		if errors.As(err, &certErr) {
+			return Result{Name: name, Status: Fail, Detail: err.Error(),
+				Fix: "the server certificate is not trusted; add the issuing CA with 'jcli config set jira.tls.ca_files <file>'"}
+		}
+		return Result{Name: name, Status: Fail, Detail: err.Error(),
+			Fix: "check the host name, your network and jira.proxy"}
+	}
+	resp.Body.Close()
+
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return Result{Name: name, Status: Pass, Detail: "handshake succeeded"}
+	}
+	expiry := resp.TLS.PeerCertificates[0].NotAfter
+	detail := fmt.Sprintf("%s, certificate valid until %s", tls.VersionName(resp.TLS.Version), expiry.Format("2006-01-02"))
+	if time.Until(expiry) < 14*24*time.Hour {
+		return Result{Name: name, Status: Warn, Detail: detail, Fix: "the certificate expires soon; tell your Jira administrator"}
+	}
+	return Result{Name: name, Status: Pass, Detail: detail}
+}
+
+func CheckCredentials(client jira.Client) Result {
+	const name = "Credentials"
+	user, err := client.Myself()
+	if err != nil {
+		result := Result{Name: name, Status: Fail, Detail: err.Error()}
+		switch statusCode(err) {
+		case http.StatusUnauthorized:
+			result.Fix = "the credentials are wrong or were revoked; run 'jcli config credentials' or 'jcli auth login'"
+		case http.StatusForbidden:
+			result.Fix = "the account may need a CAPTCHA login in the browser or lacks Jira access"
+		case http.StatusNotFound:
+			result.Fix = "jira.url does not point at a Jira site, or jira.deployment is wrong"
+		default:
+			result.Fix = "check jira.url and your network connection"
+		}
+		return result
+	}
+
+	who := user.DisplayName
+	if user.EmailAddress != "" {
+		who += " <" + user.EmailAddress + ">"
+	}
+	return Result{Name: name, Status: Pass, Detail: "authenticated as " + who}
+}
+
+func CheckProject(client jira.Client, key string) Result {
+	const name = "Project"
+	if key == "" {
+		return Result{Name: name, Status: Warn, Detail: "no default project set",
+			Fix: "run 'jcli config project <KEY>'"}
+	}
+
+	project, err := client.GetProject(key)
+	if err != nil {
+		result := Result{Name: name, Status: Fail, Detail: err.Error(),
+			Fix: "check the project key with 'jcli config project <KEY>'"}
+		if statusCode(err) == http.StatusNotFound {
+			result.Detail = fmt.Sprintf("project %s does not exist or is not visible to you", key)
+		}
+		return result
+	}
+	return Result{Name: name, Status: Pass, Detail: fmt.Sprintf("%s (%s)", project.Key, project.Name)}
+}
+
+// CheckStatus verifies that the default status filter is a status of the
+// project.
+func CheckStatus(client jira.Client, project, status string) Result {
+	const name = "Status"
+	statuses, err := client.ProjectStatuses(project)
+	if err != nil {
+		return Result{Name: name, Status: Fail, Detail: err.Error()}
+	}
+
+	var names []string
+	for _, s := range statuses {
+		names = append(names, s.Name)
+	}
+	if slices.ContainsFunc(names, func(n string) bool { return strings.EqualFold(n, status) }) {
+		return Result{Name: name, Status: Pass, Detail: fmt.Sprintf("%q is used by %s", status, project)}
+	}
+	return Result{Name: name, Status: Fail, Detail: fmt.Sprintf("%q is not a status of %s", status, project),
+		Fix: fmt.Sprintf("run 'jcli config status <name>' with one of: %s", strings.Join(names, ", "))}
+}
+
+// CheckWritable creates dir if needed and writes a probe file to it.
+func CheckWritable(name, dir string) Result {
+	if dir == "" {
+		return Result{Name: name, Status: Fail, Detail: "cannot determine directory", Fix: "set HOME or the XDG base directories"}
+	}
+	fix := fmt.Sprintf("check the permissions of %s", dir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return Result{Name: name, Status: Fail, Detail: err.Error(), Fix: fix}
+	}
+	probe, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return Result{Name: name, Status: Fail, Detail: err.Error(), Fix: fix}
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return Result{Name: name, Status: Pass, Detail: dir + " is writable"}
+}
+
+func statusCode(err error) int {
+	var apiErr *jira.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
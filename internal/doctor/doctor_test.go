@@ -0,0 +1,197 @@
+package doctor
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tutunak/jcli/internal/config"
+	"github.com/tutunak/jcli/internal/jira"
+)
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want Status
+	}{
+		{url: "", want: Fail},
+		{url: "company.atlassian.net", want: Fail},
+		{url: "ftp://company.atlassian.net", want: Fail},
+		{url: "http://jira.local", want: Warn},
+		{url: "https://company.atlassian.net/jira", want: Warn},
+		{url: "https://company.atlassian.net", want: Pass},
+		{url: "https://jira.company.com/jira", want: Pass},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := CheckURL(tt.url); got.Status != tt.want {
+				t.Errorf("CheckURL(%q) = %v (%s), want %v", tt.url, got.Status, got.Detail, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	untrusted := CheckTLS(server.URL, nil, time.Second)
+	if untrusted.Status != Fail || untrusted.Fix == "" {
+		t.Errorf("expected failure for untrusted certificate, got %+v", untrusted)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	trusted := CheckTLS(server.URL, &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}, time.Second)
+	if trusted.Status != Pass {
+		t.Errorf("expected pass with trusted CA, got %+v", trusted)
+	}
+
+	if got := CheckTLS("http://jira.local", nil, time.Second); got.Status != Skip {
+		t.Errorf("expected skip for plain HTTP, got %+v", got)
+	}
+}
+
+func newMock() *jira.MockClient {
+	client := jira.NewMockClient()
+	client.User = &jira.User{DisplayName: "Jane Doe", EmailAddress: "jane@example.com"}
+	client.Projects["PROJ"] = &jira.Project{Key: "PROJ", Name: "Project"}
+	client.Statuses["PROJ"] = []jira.Status{{Name: "To Do"}, {Name: "In Progress"}, {Name: "Done"}}
+	return client
+}
+
+func TestCheckCredentials(t *testing.T) {
+	client := newMock()
+	if got := CheckCredentials(client); got.Status != Pass {
+		t.Errorf("expected pass, got %+v", got)
+	}
+
+	client.MyselfErr = &jira.APIError{StatusCode: http.StatusUnauthorized}
+	got := CheckCredentials(client)
+	if got.Status != Fail || got.Fix == "" {
+		t.Errorf("expected failure with fix, got %+v", got)
+	}
+}
+
+func TestCheckProjectAndStatus(t *testing.T) {
+	client := newMock()
+
+	tests := []struct {
+		name  string
+		check Result
+		want  Status
+	}{
+		{name: "existing project", check: CheckProject(client, "PROJ"), want: Pass},
+		{name: "missing project", check: CheckProject(client, "NOPE"), want: Fail},
+		{name: "no default project", check: CheckProject(client, ""), want: Warn},
+		{name: "valid status", check: CheckStatus(client, "PROJ", "in progress"), want: Pass},
+		{name: "invalid status", check: CheckStatus(client, "PROJ", "Doing"), want: Fail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.check.Status != tt.want {
+				t.Errorf("got %v (%s), want %v", tt.check.Status, tt.check.Detail, tt.want)
+			}
+			if tt.want != Pass && tt.check.Fix == "" {
+				t.Error("expected a fix suggestion")
+			}
+		})
+	}
+}
+
+func TestCheckWritable(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "jcli")
+	if got := CheckWritable("Config directory", dir); got.Status != Pass {
+		t.Errorf("expected pass, got %+v", got)
+	}
+
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got := CheckWritable("Config directory", filepath.Join(file, "jcli")); got.Status != Fail {
+		t.Errorf("expected failure below a regular file, got %+v", got)
+	}
+}
+
+func TestDoctor_Run(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Jira = config.JiraConfig{URL: "http://jira.local", Email: "jane@example.com", APIToken: "token"}
+	cfg.Defaults.Project = "PROJ"
+
+	d := &Doctor{
+		Config:    cfg,
+		NewClient: func() (jira.Client, error) { return newMock(), nil },
+		ConfigDir: t.TempDir(),
+		StateDir:  t.TempDir(),
+	}
+
+	want := map[string]Status{
+		"Config":           Pass,
+		"URL":              Warn,
+		"TLS":              Skip,
+		"Credentials":      Pass,
+		"Project":          Pass,
+		"Status":           Pass,
+		"Config directory": Pass,
+		"State directory":  Pass,
+	}
+	results := d.Run()
+	if len(results) != len(want) {
+		t.Fatalf("expected %d results, got %d", len(want), len(results))
+	}
+	for _, r := range results {
+		if r.Status != want[r.Name] {
+			t.Errorf("%s: got %v (%s), want %v", r.Name, r.Status, r.Detail, want[r.Name])
+		}
+	}
+
+	d.NewTransport = func() (http.RoundTripper, error) { return nil, errors.New("bad CA bundle") }
+	results = d.Run()
+	if results[2].Name != "Network" || results[2].Status != Fail {
+		t.Errorf("expected network failure, got %+v", results[2])
+	}
+
+	cfg.Jira.APIToken = ""
+	for _, r := range d.Run() {
+		if r.Name == "Credentials" && r.Status != Skip {
+			t.Errorf("expected credentials check to be skipped for invalid config, got %v", r.Status)
+		}
+	}
+}
+
+func TestDoctor_RunWithoutConfig(t *testing.T) {
+	d := &Doctor{
+		LoadErr:    errors.New("failed to parse config file: yaml: line 2: did not find expected key"),
+		ConfigPath: "/home/jane/.config/jcli/config.yaml",
+		ConfigDir:  t.TempDir(),
+		StateDir:   t.TempDir(),
+	}
+
+	results := d.Run()
+	if len(results) != 8 {
+		t.Fatalf("expected 8 results, got %d", len(results))
+	}
+	if r := results[0]; r.Name != "Config" || r.Status != Fail || !strings.Contains(r.Detail, "did not find expected key") || !strings.Contains(r.Fix, d.ConfigPath) {
+		t.Errorf("expected the load error as a failed config check, got %+v", r)
+	}
+	for _, r := range results[1:6] {
+		if r.Status != Skip {
+			t.Errorf("%s: expected skip without a config, got %v", r.Name, r.Status)
+		}
+	}
+	for _, r := range results[6:] {
+		if r.Status != Pass {
+			t.Errorf("%s: got %v (%s), want pass", r.Name, r.Status, r.Detail)
+		}
+	}
+}
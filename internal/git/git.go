@@ -0,0 +1,81 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrNotRepository = errors.New("not a git repository")
+	ErrDetachedHead  = errors.New("HEAD is detached")
+
+	errNoOutput = errors.New("git exited without output")
+)
+
+// CurrentBranch returns the name of the branch checked out in dir.
+func CurrentBranch(dir string) (string, error) {
+	// symbolic-ref also works in a repository without commits and exits
+	// quietly with an error when HEAD is detached.
+	out, err := run(dir, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if errors.Is(err, errNoOutput) {
+		return "", ErrDetachedHead
+	}
+	return out, err
+}
+
+// RepoRoot returns the top-level directory of the repository containing dir.
+func RepoRoot(dir string) (string, error) {
+	return run(dir, "rev-parse", "--show-toplevel")
+}
+
+// FindRoot returns the top-level directory of the repository containing dir
+// like RepoRoot, but without starting git: it looks for a .git directory or
+// file in dir and its parents, up to GIT_CEILING_DIRECTORIES. dir should be
+// absolute and free of symlinks, as git reports roots that way.
+func FindRoot(dir string) (string, error) {
+	ceilings := filepath.SplitList(os.Getenv("GIT_CEILING_DIRECTORIES"))
+	dir = filepath.Clean(dir)
+	for {
+		if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir || slices.Contains(ceilings, parent) {
+			return "", ErrNotRepository
+		}
+		dir = parent
+	}
+}
+
+func run(dir string, args ...string) (string, error) {
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", fmt.Errorf("git executable not found: %w", err)
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if strings.Contains(msg, "not a git repository") {
+			return "", ErrNotRepository
+		}
+		if msg != "" {
+			return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), msg)
+		}
+		return "", fmt.Errorf("git %s: %w: %w", strings.Join(args, " "), errNoOutput, err)
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
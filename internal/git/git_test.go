@@ -0,0 +1,118 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestCurrentBranch(t *testing.T) {
+	dir := initRepo(t)
+
+	got, err := CurrentBranch(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "main" {
+		t.Errorf("expected branch main, got %q", got)
+	}
+
+	if out, err := exec.Command("git", "-C", dir, "checkout", "-q", "-b", "feature/PROJ-1-thing").CombinedOutput(); err != nil {
+		t.Fatalf("checkout failed: %v\n%s", err, out)
+	}
+
+	got, err = CurrentBranch(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "feature/PROJ-1-thing" {
+		t.Errorf("expected branch feature/PROJ-1-thing, got %q", got)
+	}
+}
+
+func TestRepoRoot(t *testing.T) {
+	dir := initRepo(t)
+
+	got, err := RepoRoot(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want, _ := filepath.EvalSymlinks(dir)
+	if got, _ = filepath.EvalSymlinks(got); got != want {
+		t.Errorf("expected root %q, got %q", want, got)
+	}
+}
+
+func TestFindRoot(t *testing.T) {
+	dir := initRepo(t)
+	root, _ := filepath.EvalSymlinks(dir)
+	sub := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := RepoRoot(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := FindRoot(sub)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != want {
+		t.Errorf("FindRoot() = %q, want %q as reported by git", got, want)
+	}
+
+	outside := t.TempDir()
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(outside))
+	if _, err := FindRoot(outside); !errors.Is(err, ErrNotRepository) {
+		t.Errorf("expected ErrNotRepository, got %v", err)
+	}
+}
+
+func TestNotRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(t.TempDir()))
+
+	_, err := CurrentBranch(t.TempDir())
+	if !errors.Is(err, ErrNotRepository) {
+		t.Errorf("expected ErrNotRepository, got %v", err)
+	}
+}
+
+func TestCurrentBranch_Detached(t *testing.T) {
+	dir := initRepo(t)
+
+	if out, err := exec.Command("git", "-C", dir, "checkout", "-q", "--detach").CombinedOutput(); err != nil {
+		t.Fatalf("checkout failed: %v\n%s", err, out)
+	}
+
+	_, err := CurrentBranch(dir)
+	if !errors.Is(err, ErrDetachedHead) {
+		t.Errorf("expected ErrDetachedHead, got %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+// Package issuelist builds the JQL and renders the table of
+// "jcli issue list".
+package issuelist
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tutunak/jcli/internal/jira"
+)
+
+// DefaultColumns applies when list.columns is not configured. DefaultSort
+// applies when list.sort is not configured either and the query has no ORDER
+// BY clause of its own.
+var (
+	DefaultColumns = []string{"key", "type", "status", "priority", "assignee", "updated", "summary"}
+	DefaultSort    = []string{"-updated"}
+)
+
+// Column is a column of the issue table.
+type Column struct {
+	// Name is the name used in list.columns and --columns.
+	Name   string
+	Header string
+	// Field is the JQL field used to sort by the column.
+	Field string
+	value func(jira.Issue) string
+}
+
+// Value returns the cell of issue in the column.
+func (c Column) Value(issue jira.Issue) string {
+	return c.value(issue)
+}
+
+// Custom reports whether the column is a custom field, which has to be
+// requested from the search API explicitly.
+func (c Column) Custom() bool {
+	return strings.HasPrefix(c.Name, "customfield_")
+}
+
+var builtinColumns = []Column{
+	{Name: "key", Header: "KEY", Field: "key", value: func(i jira.Issue) string { return i.Key }},
+	{Name: "summary", Header: "SUMMARY", Field: "summary", value: func(i jira.Issue) string { return i.Fields.Summary }},
+	{Name: "type", Header: "TYPE", Field: "issuetype", value: func(i jira.Issue) string { return i.Fields.IssueType.Name }},
+	{Name: "status", Header: "STATUS", Field: "status", value: func(i jira.Issue) string { return i.Fields.Status.Name }},
+	{Name: "priority", Header: "PRIORITY", Field: "priority", value: func(i jira.Issue) string {
+		if i.Fields.Priority == nil {
+			return ""
+		}
+		return i.Fields.Priority.Name
+	}},
+	{Name: "assignee", Header: "ASSIGNEE", Field: "assignee", value: func(i jira.Issue) string { return UserName(i.Fields.Assignee) }},
+	{Name: "reporter", Header: "REPORTER", Field: "reporter", value: func(i jira.Issue) string { return UserName(i.Fields.Reporter) }},
+	{Name: "created", Header: "CREATED", Field: "created", value: func(i jira.Issue) string { return displayTime(i.Fields.Created) }},
+	{Name: "updated", Header: "UPDATED", Field: "updated", value: func(i jira.Issue) string { return displayTime(i.Fields.Updated) }},
+}
+
+var customFieldPattern = regexp.MustCompile(`^customfield_([0-9]+)$`)
+
+// LookupColumn returns the column called name.
+func LookupColumn(name string) (Column, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, c := range builtinColumns {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	if m := customFieldPattern.FindStringSubmatch(name); m != nil {
+		return Column{
+			Name:   name,
+			Header: strings.ToUpper(name),
+			Field:  "cf[" + m[1] + "]",
+			value:  func(i jira.Issue) string { return i.Fields.CustomText(name) },
+		}, nil
+	}
+	return Column{}, fmt.Errorf("unknown column %q (use %s or customfield_<id>)", name, strings.Join(ColumnNames(), ", "))
+}
+
+// ColumnNames returns the names of the built-in columns.
+func ColumnNames() []string {
+	names := make([]string, len(builtinColumns))
+	for i, c := range builtinColumns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// ParseColumns looks up the named columns; empty names are skipped.
+func ParseColumns(names []string) ([]Column, error) {
+	var columns []Column
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		c, err := LookupColumn(name)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, c)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns selected")
+	}
+	return columns, nil
+}
+
+// CustomFields returns the custom field IDs among columns.
+func CustomFields(columns []Column) []string {
+	var fields []string
+	for _, c := range columns {
+		if c.Custom() {
+			fields = append(fields, c.Name)
+		}
+	}
+	return fields
+}
+
+// orderByPattern matches the ORDER BY keywords at the start of a string.
+var orderByPattern = regexp.MustCompile(`(?i)^order\s+by\b`)
+
+// stripOrderBy removes the ORDER BY clause from jql. Only an ORDER BY
+// outside quoted strings counts, and as the clause comes last in JQL, the
+// last such occurrence is the one.
+func stripOrderBy(jql string) string {
+	last := -1
+	var quote byte
+	for i := 0; i < len(jql); i++ {
+		c := jql[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case (i == 0 || !isWordByte(jql[i-1])) && orderByPattern.MatchString(jql[i:]):
+			last = i
+		}
+	}
+	if last < 0 {
+		return jql
+	}
+	return jql[:last]
+}
+
+// HasOrderBy reports whether jql ends in an ORDER BY clause.
+func HasOrderBy(jql string) bool {
+	return stripOrderBy(jql) != jql
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// BuildJQL appends the sort order to jql, replacing an ORDER BY clause it
+// already has. Sort fields are column names; a "-" prefix sorts in
+// descending order. Without sort fields jql is returned unchanged.
+func BuildJQL(jql string, sort []string) (string, error) {
+	var terms []string
+	for _, s := range sort {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		direction := "ASC"
+		if strings.HasPrefix(s, "-") {
+			direction = "DESC"
+			s = s[1:]
+		}
+		c, err := LookupColumn(s)
+		if err != nil {
+			return "", fmt.Errorf("invalid sort field: %w", err)
+		}
+		terms = append(terms, c.Field+" "+direction)
+	}
+	if len(terms) == 0 {
+		return jql, nil
+	}
+
+	jql = strings.TrimSpace(stripOrderBy(jql))
+	order := "ORDER BY " + strings.Join(terms, ", ")
+	if jql == "" {
+		return order, nil
+	}
+	return jql + " " + order, nil
+}
+
+// UserName returns the display name of u, or "" when the field is unset.
+func UserName(u *jira.User) string {
+	if u == nil {
+		return ""
+	}
+	return u.DisplayName
+}
+
+// jiraTimeLayout is the timestamp format of the Jira REST API.
+const jiraTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+// ParseTime parses a Jira timestamp. It returns false for empty or
+// malformed values.
+func ParseTime(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range []string{jiraTimeLayout, time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func displayTime(value string) string {
+	t, ok := ParseTime(value)
+	if !ok {
+		return value
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
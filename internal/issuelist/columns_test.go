@@ -0,0 +1,114 @@
+package issuelist
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/tutunak/jcli/internal/jira"
+)
+
+func TestParseColumns(t *testing.T) {
+	columns, err := ParseColumns([]string{"key", " Status ", "", "customfield_10016"})
+	if err != nil {
+		t.Fatalf("ParseColumns() error = %v", err)
+	}
+	var names []string
+	for _, c := range columns {
+		names = append(names, c.Name)
+	}
+	if got := strings.Join(names, ","); got != "key,status,customfield_10016" {
+		t.Errorf("columns = %s", got)
+	}
+	if got := CustomFields(columns); len(got) != 1 || got[0] != "customfield_10016" {
+		t.Errorf("CustomFields() = %v", got)
+	}
+
+	if _, err := ParseColumns([]string{"key", "labels"}); err == nil || !strings.Contains(err.Error(), `unknown column "labels"`) {
+		t.Errorf("expected unknown column error, got %v", err)
+	}
+	if _, err := ParseColumns(nil); err == nil {
+		t.Error("expected error for no columns")
+	}
+}
+
+func TestColumn_Value(t *testing.T) {
+	issue := jira.Issue{Key: "PROJ-1", Fields: jira.IssueFields{
+		Summary:   "Fix login",
+		IssueType: jira.Type{Name: "Bug"},
+		Priority:  &jira.Priority{Name: "High"},
+		Updated:   "2024-05-01T09:30:00.000+0000",
+		Custom:    map[string]json.RawMessage{"customfield_10016": json.RawMessage(`5`)},
+	}}
+
+	tests := map[string]string{
+		"key":               "PROJ-1",
+		"type":              "Bug",
+		"priority":          "High",
+		"assignee":          "",
+		"customfield_10016": "5",
+		"customfield_1":     "",
+	}
+	for name, want := range tests {
+		c, err := LookupColumn(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.Value(issue); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	updated, _ := LookupColumn("updated")
+	if got := updated.Value(issue); !strings.HasPrefix(got, "2024-05-01") && !strings.HasPrefix(got, "2024-04-30") {
+		t.Errorf("updated = %q", got)
+	}
+}
+
+func TestBuildJQL(t *testing.T) {
+	tests := []struct {
+		jql  string
+		sort []string
+		want string
+	}{
+		{jql: "project = PROJ", sort: nil, want: "project = PROJ"},
+		{jql: "project = PROJ ORDER BY created", sort: nil, want: "project = PROJ ORDER BY created"},
+		{jql: "project = PROJ", sort: []string{"-updated", "key"}, want: "project = PROJ ORDER BY updated DESC, key ASC"},
+		{jql: "project = PROJ order by created ASC", sort: []string{"priority"}, want: "project = PROJ ORDER BY priority ASC"},
+		{jql: "", sort: []string{"type", "-customfield_10016"}, want: "ORDER BY issuetype ASC, cf[10016] DESC"},
+		{jql: `summary ~ "order by x"`, sort: []string{"key"}, want: `summary ~ "order by x" ORDER BY key ASC`},
+		{jql: `summary ~ 'it\'s order by' ORDER BY created`, sort: []string{"key"}, want: `summary ~ 'it\'s order by' ORDER BY key ASC`},
+		{jql: "reorder_by = 1 ORDER\nBY created", sort: []string{"key"}, want: "reorder_by = 1 ORDER BY key ASC"},
+	}
+	for _, tt := range tests {
+		got, err := BuildJQL(tt.jql, tt.sort)
+		if err != nil {
+			t.Fatalf("BuildJQL(%q, %v) error = %v", tt.jql, tt.sort, err)
+		}
+		if got != tt.want {
+			t.Errorf("BuildJQL(%q, %v) = %q, want %q", tt.jql, tt.sort, got, tt.want)
+		}
+	}
+
+	if _, err := BuildJQL("project = PROJ", []string{"-labels"}); err == nil || !strings.Contains(err.Error(), "invalid sort field") {
+		t.Errorf("expected invalid sort field error, got %v", err)
+	}
+}
+
+func TestHasOrderBy(t *testing.T) {
+	tests := []struct {
+		jql  string
+		want bool
+	}{
+		{"project = PROJ", false},
+		{"project = PROJ ORDER BY created", true},
+		{"order by key", true},
+		{`summary ~ "order by x"`, false},
+		{"reorder_by = 1", false},
+	}
+	for _, tt := range tests {
+		if got := HasOrderBy(tt.jql); got != tt.want {
+			t.Errorf("HasOrderBy(%q) = %v, want %v", tt.jql, got, tt.want)
+		}
+	}
+}
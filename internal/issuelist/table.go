@@ -0,0 +1,155 @@
+package issuelist
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/tutunak/jcli/internal/jira"
+)
+
+// columnGap separates the columns of a table.
+const columnGap = "  "
+
+// minColumnWidth is the width a column is never shrunk below.
+const minColumnWidth = 6
+
+// Table renders issues as an aligned table.
+type Table struct {
+	Columns []Column
+	// Width is the width to fit the table into; cells are truncated with
+	// "…" from the widest column on. Zero disables truncation.
+	Width int
+	// Link returns the URL an issue key links to. When set, keys are
+	// printed as OSC 8 hyperlinks.
+	Link func(key string) string
+}
+
+var cellReplacer = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+
+func (t Table) Render(w io.Writer, issues []jira.Issue) error {
+	rows := make([][]string, len(issues)+1)
+	rows[0] = make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		rows[0][i] = c.Header
+	}
+	for r, issue := range issues {
+		row := make([]string, len(t.Columns))
+		for i, c := range t.Columns {
+			row[i] = cellReplacer.Replace(c.Value(issue))
+		}
+		rows[r+1] = row
+	}
+
+	widths := t.fit(columnWidths(rows))
+
+	var b strings.Builder
+	for r, row := range rows {
+		b.Reset()
+		for i, cell := range row {
+			cell = runewidth.Truncate(cell, widths[i], "…")
+			pad := widths[i] - runewidth.StringWidth(cell)
+			if r > 0 && t.Link != nil && t.Columns[i].Name == "key" && cell != "" {
+				cell = hyperlink(t.Link(row[i]), cell)
+			}
+			b.WriteString(cell)
+			if i < len(row)-1 {
+				b.WriteString(strings.Repeat(" ", pad))
+				b.WriteString(columnGap)
+			}
+		}
+		b.WriteString("\n")
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func columnWidths(rows [][]string) []int {
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], runewidth.StringWidth(cell))
+		}
+	}
+	return widths
+}
+
+// fit shrinks the widest columns until the table fits into t.Width. Key
+// columns are never shrunk.
+func (t Table) fit(widths []int) []int {
+	if t.Width <= 0 {
+		return widths
+	}
+	total := len(columnGap) * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+	for total > t.Width {
+		widest := -1
+		for i, w := range widths {
+			if t.Columns[i].Name == "key" || w <= minColumnWidth {
+				continue
+			}
+			if widest < 0 || w > widths[widest] {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
+
+// hyperlink wraps text in an OSC 8 terminal hyperlink to url.
+func hyperlink(url, text string) string {
+	return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+// HyperlinksSupported reports whether the terminal on f renders OSC 8
+// hyperlinks. FORCE_HYPERLINK=1 or 0 overrides the detection.
+func HyperlinksSupported(f *os.File) bool {
+	if force := os.Getenv("FORCE_HYPERLINK"); force != "" {
+		enabled, err := strconv.ParseBool(force)
+		return err == nil && enabled
+	}
+	if !term.IsTerminal(f.Fd()) {
+		return false
+	}
+	return supportsHyperlinks(os.Getenv)
+}
+
+func supportsHyperlinks(getenv func(string) string) bool {
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty":
+		return true
+	}
+	if vte, err := strconv.Atoi(getenv("VTE_VERSION")); err == nil && vte >= 5000 {
+		return true
+	}
+	return getenv("KITTY_WINDOW_ID") != "" || getenv("WT_SESSION") != ""
+}
+
+// TerminalWidth returns the width to fit tables into: $COLUMNS, or the size
+// of the terminal on f. It returns 0 when f is not a terminal.
+func TerminalWidth(f *os.File) int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	if !term.IsTerminal(f.Fd()) {
+		return 0
+	}
+	width, _, err := term.GetSize(f.Fd())
+	if err != nil {
+		return 0
+	}
+	return width
+}
@@ -0,0 +1,100 @@
+package issuelist
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/tutunak/jcli/internal/jira"
+)
+
+func render(t *testing.T, table Table, issues []jira.Issue) []string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := table.Render(&buf, issues); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+}
+
+func testIssues() []jira.Issue {
+	return []jira.Issue{
+		{Key: "PROJ-1", Fields: jira.IssueFields{Summary: "Short", Status: jira.Status{Name: "To Do"}}},
+		{Key: "PROJ-22", Fields: jira.IssueFields{Summary: "A much longer summary\twith a tab", Status: jira.Status{Name: "In Progress"}}},
+	}
+}
+
+func TestTable_Render(t *testing.T) {
+	columns, _ := ParseColumns([]string{"key", "status", "summary"})
+	lines := render(t, Table{Columns: columns}, testIssues())
+
+	want := []string{
+		"KEY      STATUS       SUMMARY",
+		"PROJ-1   To Do        Short",
+		"PROJ-22  In Progress  A much longer summary with a tab",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("table =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestTable_RenderTruncates(t *testing.T) {
+	columns, _ := ParseColumns([]string{"key", "status", "summary"})
+	lines := render(t, Table{Columns: columns, Width: 40}, testIssues())
+
+	for _, line := range lines {
+		if w := runewidth.StringWidth(line); w > 40 {
+			t.Errorf("line %q is %d wide, want at most 40", line, w)
+		}
+	}
+	if !strings.HasPrefix(lines[2], "PROJ-22  In Progress  A much") || !strings.HasSuffix(lines[2], "…") {
+		t.Errorf("expected the summary to be truncated, got %q", lines[2])
+	}
+}
+
+func TestTable_RenderHyperlinks(t *testing.T) {
+	columns, _ := ParseColumns([]string{"key", "summary"})
+	link := func(key string) string { return "https://jira.example.com/browse/" + key }
+	lines := render(t, Table{Columns: columns, Link: link}, testIssues())
+
+	if strings.Contains(lines[0], "\x1b") {
+		t.Errorf("header must not be linked: %q", lines[0])
+	}
+	want := "\x1b]8;;https://jira.example.com/browse/PROJ-1\x1b\\PROJ-1\x1b]8;;\x1b\\   Short"
+	if lines[1] != want {
+		t.Errorf("row = %q, want %q", lines[1], want)
+	}
+}
+
+func TestSupportsHyperlinks(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want bool
+	}{
+		{env: map[string]string{}, want: false},
+		{env: map[string]string{"TERM_PROGRAM": "iTerm.app"}, want: true},
+		{env: map[string]string{"TERM_PROGRAM": "Apple_Terminal"}, want: false},
+		{env: map[string]string{"VTE_VERSION": "6003"}, want: true},
+		{env: map[string]string{"VTE_VERSION": "4200"}, want: false},
+		{env: map[string]string{"WT_SESSION": "abc"}, want: true},
+	}
+	for _, tt := range tests {
+		getenv := func(name string) string { return tt.env[name] }
+		if got := supportsHyperlinks(getenv); got != tt.want {
+			t.Errorf("supportsHyperlinks(%v) = %v, want %v", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestHyperlinksSupported_Force(t *testing.T) {
+	t.Setenv("FORCE_HYPERLINK", "1")
+	if !HyperlinksSupported(nil) {
+		t.Error("expected FORCE_HYPERLINK=1 to enable hyperlinks")
+	}
+	t.Setenv("FORCE_HYPERLINK", "0")
+	if HyperlinksSupported(nil) {
+		t.Error("expected FORCE_HYPERLINK=0 to disable hyperlinks")
+	}
+}
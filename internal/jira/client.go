@@ -1,25 +1,86 @@
 package jira
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
 
 type Client interface {
 	SearchIssues(project, status string) ([]Issue, error)
+	SearchJQL(jql string) ([]Issue, error)
+	Search(jql string, opts SearchOptions) ([]Issue, error)
 	GetIssue(key string) (*Issue, error)
+	Myself() (*User, error)
+	GetProject(key string) (*Project, error)
+	ListProjects() ([]Project, error)
+	ProjectStatuses(key string) ([]Status, error)
 }
 
+// APIError is returned for responses with a non-2xx status code.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// AuthError is returned when no OAuth access token can be obtained, e.g.
+// because the user never logged in or the refresh token was revoked.
+type AuthError struct {
+	Err error
+}
+
+func (e *AuthError) Error() string { return e.Err.Error() }
+func (e *AuthError) Unwrap() error { return e.Err }
+
+const (
+	searchFields = "summary,status,issuetype,priority,assignee,reporter,created,updated"
+	pageSize     = 50
+
+	// maxRetries is how often a rate-limited or failed request is repeated.
+	// The first retry waits retryDelay, each further one twice as long,
+	// unless the server asks for a delay with Retry-After.
+	maxRetries    = 3
+	retryDelay    = time.Second
+	maxRetryDelay = 30 * time.Second
+)
+
+// SearchOptions extend a search. Fields are requested in addition to the
+// default fields, e.g. "customfield_10016"; Limit caps the number of issues
+// and defaults to one page of 50. Larger limits fetch further pages.
+type SearchOptions struct {
+	Fields []string
+	Limit  int
+}
+
+// TokenSource supplies OAuth access tokens. Refresh is called once when a
+// request is rejected with 401 Unauthorized.
+type TokenSource interface {
+	Token() (string, error)
+	Refresh() (string, error)
+}
+
+// HTTPClient talks to Jira Cloud over REST API v3 with email and API token
+// basic auth or OAuth, or to Server and Data Center (server set) over REST
+// API v2 with a Personal Access Token. The deployment is chosen by the
+// constructor; config.Config.IsServer decides which one to call.
 type HTTPClient struct {
-	baseURL    string
-	email      string
-	apiToken   string
-	httpClient *http.Client
+	baseURL     string
+	email       string
+	apiToken    string
+	server      bool
+	tokenSource TokenSource
+	httpClient  *http.Client
+	sleep       func(time.Duration)
 }
 
 func NewClient(baseURL, email, apiToken string) *HTTPClient {
@@ -30,9 +91,40 @@ func NewClient(baseURL, email, apiToken string) *HTTPClient {
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		sleep: time.Sleep,
 	}
 }
 
+// NewServerClient returns a client for Jira Server or Data Center that
+// authenticates with a Personal Access Token.
+func NewServerClient(baseURL, personalAccessToken string) *HTTPClient {
+	c := NewClient(baseURL, "", personalAccessToken)
+	c.server = true
+	return c
+}
+
+// NewOAuthClient returns a Jira Cloud client that authenticates with OAuth
+// access tokens. baseURL is the cloud ID–scoped API URL of the site.
+func NewOAuthClient(baseURL string, source TokenSource) *HTTPClient {
+	c := NewClient(baseURL, "", "")
+	c.tokenSource = source
+	return c
+}
+
+// SetTransport replaces the transport used for requests, e.g. to apply proxy
+// and TLS settings.
+func (c *HTTPClient) SetTransport(rt http.RoundTripper) {
+	c.httpClient.Transport = rt
+}
+
+// api returns the REST API path for endpoint on this deployment.
+func (c *HTTPClient) api(endpoint string) string {
+	if c.server {
+		return "/rest/api/2" + endpoint
+	}
+	return "/rest/api/3" + endpoint
+}
+
 func (c *HTTPClient) doRequest(method, endpoint string, query url.Values) ([]byte, error) {
 	// Build URL by joining base URL and endpoint, handling trailing slashes
 	baseURL := strings.TrimSuffix(c.baseURL, "/")
@@ -46,61 +138,203 @@ func (c *HTTPClient) doRequest(method, endpoint string, query url.Values) ([]byt
 		return nil, fmt.Errorf("invalid URL: %w", err)
 	}
 
-	req, err := http.NewRequest(method, u.String(), nil)
+	status, body, err := c.do(method, u.String(), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	req.SetBasicAuth(c.email, c.apiToken)
+	if status < 200 || status >= 300 {
+		return nil, &APIError{StatusCode: status, Body: string(body)}
+	}
+
+	return body, nil
+}
+
+// do authenticates and performs a request, refreshing the OAuth access
+// token once if it is rejected.
+func (c *HTTPClient) do(method, rawURL string, reqBody []byte) (int, []byte, error) {
+	var bearer string
+	var err error
+	if c.tokenSource != nil {
+		if bearer, err = c.tokenSource.Token(); err != nil {
+			return 0, nil, &AuthError{Err: err}
+		}
+	}
+
+	status, body, err := c.sendRetrying(method, rawURL, bearer, reqBody)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// An access token may be revoked or expire early; refresh and retry once.
+	if status == http.StatusUnauthorized && c.tokenSource != nil {
+		if bearer, err = c.tokenSource.Refresh(); err != nil {
+			return 0, nil, &AuthError{Err: err}
+		}
+		if status, body, err = c.sendRetrying(method, rawURL, bearer, reqBody); err != nil {
+			return 0, nil, err
+		}
+	}
+	return status, body, nil
+}
+
+// sendRetrying performs a request and repeats it up to maxRetries times
+// while it is rate limited (429) or, for idempotent methods, fails with a
+// server error (5xx). The last response is returned whatever its status.
+func (c *HTTPClient) sendRetrying(method, rawURL, bearer string, reqBody []byte) (int, []byte, error) {
+	for attempt := 0; ; attempt++ {
+		status, header, body, err := c.send(method, rawURL, bearer, reqBody)
+		if err != nil || attempt == maxRetries || !retryable(method, status) {
+			return status, body, err
+		}
+		c.sleep(backoff(attempt, header.Get("Retry-After")))
+	}
+}
+
+// retryable reports whether a response with status may be retried. A 429
+// means the request was not processed; a server error may come after a
+// non-idempotent request took effect, so POST and PATCH are not repeated.
+func retryable(method string, status int) bool {
+	if status == http.StatusTooManyRequests {
+		return true
+	}
+	if status < 500 {
+		return false
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// backoff returns the delay before retry attempt+1: the Retry-After
+// seconds or date when the server sent one, an exponential delay
+// otherwise, never more than maxRetryDelay.
+func backoff(attempt int, retryAfter string) time.Duration {
+	delay := retryDelay << attempt
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		delay = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(retryAfter); err == nil {
+		delay = max(time.Until(at), 0)
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// send performs one request, authenticating with bearer when it is set.
+func (c *HTTPClient) send(method, rawURL, bearer string, reqBody []byte) (int, http.Header, []byte, error) {
+	var bodyReader io.Reader
+	if reqBody != nil {
+		bodyReader = bytes.NewReader(reqBody)
+	}
+	req, err := http.NewRequest(method, rawURL, bodyReader)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	switch {
+	case bearer != "":
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	case c.server:
+		req.Header.Set("Authorization", "Bearer "+c.apiToken)
+	default:
+		req.SetBasicAuth(c.email, c.apiToken)
+	}
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return 0, nil, nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return 0, nil, nil, fmt.Errorf("failed to read response: %w", err)
 	}
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
-	}
-
-	return body, nil
+	return resp.StatusCode, resp.Header, body, nil
 }
 
 func (c *HTTPClient) SearchIssues(project, status string) ([]Issue, error) {
+	return c.SearchJQL(IssuesJQL(project, status))
+}
+
+// IssuesJQL is the query of SearchIssues: the issues of project in status
+// that are assigned to the authenticated user, most recently updated first.
+func IssuesJQL(project, status string) string {
 	// JQL: project keys work without quotes, status with spaces needs quotes
-	// assignee = currentUser() filters to only issues assigned to the authenticated user
-	jql := fmt.Sprintf(`project = %s AND status = "%s" AND assignee = currentUser() ORDER BY updated DESC`, project, status)
+	return fmt.Sprintf(`project = %s AND status = "%s" AND assignee = currentUser() ORDER BY updated DESC`, project, status)
+}
 
-	query := url.Values{}
-	query.Set("jql", jql)
-	query.Set("fields", "summary,status,issuetype,priority,assignee,reporter,created,updated")
-	query.Set("maxResults", "50")
+// SearchJQL returns the first page of issues matching jql.
+func (c *HTTPClient) SearchJQL(jql string) ([]Issue, error) {
+	return c.Search(jql, SearchOptions{})
+}
 
-	body, err := c.doRequest(http.MethodGet, "/rest/api/3/search/jql", query)
-	if err != nil {
-		return nil, err
+// Search returns the issues matching jql, following the token pagination of
+// Cloud's /search/jql or the offset pagination of Server's /search.
+func (c *HTTPClient) Search(jql string, opts SearchOptions) ([]Issue, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = pageSize
+	}
+	fields := searchFields
+	if len(opts.Fields) > 0 {
+		fields += "," + strings.Join(opts.Fields, ",")
 	}
 
-	var result SearchResult
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	var issues []Issue
+	var nextPageToken string
+
+	for len(issues) < limit {
+		query := url.Values{}
+		query.Set("jql", jql)
+		query.Set("fields", fields)
+		query.Set("maxResults", strconv.Itoa(min(pageSize, limit-len(issues))))
+
+		endpoint := c.api("/search/jql")
+		if c.server {
+			endpoint = c.api("/search")
+			query.Set("startAt", strconv.Itoa(len(issues)))
+		} else if nextPageToken != "" {
+			query.Set("nextPageToken", nextPageToken)
+		}
+
+		body, err := c.doRequest(http.MethodGet, endpoint, query)
+		if err != nil {
+			return nil, err
+		}
+
+		var result SearchResult
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+		issues = append(issues, result.Issues...)
+
+		if len(result.Issues) == 0 {
+			break
+		}
+		if c.server {
+			if result.StartAt+len(result.Issues) >= result.Total {
+				break
+			}
+		} else {
+			if result.IsLast || result.NextPageToken == "" {
+				break
+			}
+			nextPageToken = result.NextPageToken
+		}
 	}
 
-	return result.Issues, nil
+	return issues, nil
 }
 
 func (c *HTTPClient) GetIssue(key string) (*Issue, error) {
-	endpoint := fmt.Sprintf("/rest/api/3/issue/%s", key)
+	endpoint := c.api(fmt.Sprintf("/issue/%s", key))
 
 	query := url.Values{}
-	query.Set("fields", "summary,status,issuetype,priority,assignee,reporter,created,updated,description")
+	query.Set("fields", searchFields+",description")
 
 	body, err := c.doRequest(http.MethodGet, endpoint, query)
 	if err != nil {
@@ -114,3 +348,100 @@ func (c *HTTPClient) GetIssue(key string) (*Issue, error) {
 
 	return &issue, nil
 }
+
+// Myself returns the authenticated user.
+func (c *HTTPClient) Myself() (*User, error) {
+	body, err := c.doRequest(http.MethodGet, c.api("/myself"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var user User
+	if err := json.Unmarshal(body, &user); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (c *HTTPClient) GetProject(key string) (*Project, error) {
+	body, err := c.doRequest(http.MethodGet, c.api(fmt.Sprintf("/project/%s", key)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var project Project
+	if err := json.Unmarshal(body, &project); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &project, nil
+}
+
+// ListProjects returns the projects the user can browse, following the
+// pagination of Cloud's /project/search. Server lists all projects at once.
+func (c *HTTPClient) ListProjects() ([]Project, error) {
+	if c.server {
+		body, err := c.doRequest(http.MethodGet, c.api("/project"), nil)
+		if err != nil {
+			return nil, err
+		}
+		var projects []Project
+		if err := json.Unmarshal(body, &projects); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+		return projects, nil
+	}
+
+	var projects []Project
+	for {
+		query := url.Values{}
+		query.Set("startAt", strconv.Itoa(len(projects)))
+		query.Set("maxResults", strconv.Itoa(pageSize))
+		query.Set("orderBy", "key")
+
+		body, err := c.doRequest(http.MethodGet, c.api("/project/search"), query)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Values []Project `json:"values"`
+			IsLast bool      `json:"isLast"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+		projects = append(projects, page.Values...)
+		if page.IsLast || len(page.Values) == 0 {
+			return projects, nil
+		}
+	}
+}
+
+// ProjectStatuses returns the statuses used by the issue types of a
+// project, without duplicates and in the order Jira lists them.
+func (c *HTTPClient) ProjectStatuses(key string) ([]Status, error) {
+	body, err := c.doRequest(http.MethodGet, c.api(fmt.Sprintf("/project/%s/statuses", key)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var issueTypes []struct {
+		Statuses []Status `json:"statuses"`
+	}
+	if err := json.Unmarshal(body, &issueTypes); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	var statuses []Status
+	seen := make(map[string]bool)
+	for _, issueType := range issueTypes {
+		for _, status := range issueType.Statuses {
+			if !seen[status.Name] {
+				seen[status.Name] = true
+				statuses = append(statuses, status)
+			}
+		}
+	}
+	return statuses, nil
+}
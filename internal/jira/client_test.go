@@ -2,9 +2,16 @@ package jira
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestHTTPClient_SearchIssues(t *testing.T) {
@@ -27,6 +34,9 @@ func TestHTTPClient_SearchIssues(t *testing.T) {
 		if jql == "" {
 			t.Error("missing jql parameter")
 		}
+		if max := r.URL.Query().Get("maxResults"); max != "50" {
+			t.Errorf("expected a single page of 50, got maxResults %s", max)
+		}
 
 		result := SearchResult{
 			Total: 2,
@@ -59,6 +69,32 @@ func TestHTTPClient_SearchIssues(t *testing.T) {
 	}
 }
 
+func TestHTTPClient_SearchJQL(t *testing.T) {
+	const jql = `assignee = currentUser() AND resolution = Unresolved`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("jql"); got != jql {
+			t.Errorf("expected jql %q, got %q", jql, got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(w).Encode(SearchResult{Issues: []Issue{{Key: "TEST-7"}}})
+		if err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test@example.com", "token123")
+	issues, err := client.SearchJQL(jql)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Key != "TEST-7" {
+		t.Errorf("unexpected issues: %+v", issues)
+	}
+}
+
 func TestHTTPClient_GetIssue(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/rest/api/3/issue/TEST-123" {
@@ -99,6 +135,273 @@ func TestHTTPClient_GetIssue(t *testing.T) {
 	}
 }
 
+func TestHTTPClient_SearchJQL_TokenPagination(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var result SearchResult
+		switch token := r.URL.Query().Get("nextPageToken"); token {
+		case "":
+			result = SearchResult{Issues: []Issue{{Key: "TEST-1"}}, NextPageToken: "page2"}
+		case "page2":
+			result = SearchResult{Issues: []Issue{{Key: "TEST-2"}}, IsLast: true}
+		default:
+			t.Errorf("unexpected nextPageToken %q", token)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test@example.com", "token123")
+	issues, err := client.SearchJQL("project = TEST")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 2 || issues[1].Key != "TEST-2" {
+		t.Errorf("unexpected issues: %+v", issues)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestHTTPClient_Search_FieldsAndLimit(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		query := r.URL.Query()
+		if fields := query.Get("fields"); !strings.HasSuffix(fields, ",customfield_10016") {
+			t.Errorf("expected custom field to be requested, got fields %q", fields)
+		}
+		if max := query.Get("maxResults"); max != "3" {
+			t.Errorf("expected maxResults 3, got %s", max)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"issues":[
+			{"key":"TEST-1","fields":{"summary":"One","customfield_10016":5}},
+			{"key":"TEST-2","fields":{"summary":"Two","customfield_10016":null}},
+			{"key":"TEST-3","fields":{"summary":"Three"}}
+		],"nextPageToken":"more"}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test@example.com", "token123")
+	issues, err := client.Search("project = TEST", SearchOptions{Fields: []string{"customfield_10016"}, Limit: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 3 || requests != 1 {
+		t.Fatalf("expected 3 issues in 1 request, got %d in %d", len(issues), requests)
+	}
+	if got := issues[0].Fields.CustomText("customfield_10016"); got != "5" {
+		t.Errorf("CustomText() = %q, want 5", got)
+	}
+	if _, ok := issues[1].Fields.Custom["customfield_10016"]; ok {
+		t.Error("expected null custom field to be dropped")
+	}
+}
+
+func TestServerClient_SearchJQL(t *testing.T) {
+	all := []Issue{{Key: "OPS-1"}, {Key: "OPS-2"}, {Key: "OPS-3"}}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/search" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer pat123" {
+			t.Errorf("expected bearer auth, got %q", got)
+		}
+
+		// Serve at most two issues per page, as servers may cap maxResults.
+		startAt, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+		end := min(startAt+2, len(all))
+		result := SearchResult{StartAt: startAt, MaxResults: 2, Total: len(all), Issues: all[startAt:end]}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewServerClient(server.URL, "pat123")
+	issues, err := client.SearchJQL("project = OPS")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 3 || issues[2].Key != "OPS-3" {
+		t.Errorf("unexpected issues: %+v", issues)
+	}
+}
+
+func TestServerClient_GetIssue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/issue/OPS-5" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		if _, _, ok := r.BasicAuth(); ok {
+			t.Error("server client must not use basic auth")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"key":"OPS-5","fields":{"summary":"Rotate certs","description":"h2. Steps\n* renew"}}`))
+	}))
+	defer server.Close()
+
+	issue, err := NewServerClient(server.URL, "pat123").GetIssue("OPS-5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := issue.Fields.DescriptionText(); got != "h2. Steps\n* renew" {
+		t.Errorf("unexpected description %q", got)
+	}
+}
+
+func TestIssueFields_DescriptionText(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		want        string
+	}{
+		{name: "missing", description: "", want: ""},
+		{name: "null", description: "null", want: ""},
+		{name: "wiki markup", description: `"*bold* text"`, want: "*bold* text"},
+		{
+			name: "adf document",
+			description: `{"type":"doc","version":1,"content":[
+				{"type":"paragraph","content":[{"type":"text","text":"First "},{"type":"text","text":"line"}]},
+				{"type":"paragraph","content":[{"type":"text","text":"Second"},{"type":"hardBreak"},{"type":"text","text":"line"}]}
+			]}`,
+			want: "First line\nSecond\nline",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := IssueFields{Description: json.RawMessage(tt.description)}
+			if got := fields.DescriptionText(); got != tt.want {
+				t.Errorf("DescriptionText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIssueFields_CustomText(t *testing.T) {
+	var fields IssueFields
+	err := json.Unmarshal([]byte(`{
+		"summary": "Custom",
+		"customfield_1": "text",
+		"customfield_2": 2.5,
+		"customfield_3": {"value": "Option A", "id": "10"},
+		"customfield_4": [{"name": "Sprint 1"}, {"name": "Sprint 2"}],
+		"customfield_5": {"displayName": "Ada"},
+		"customfield_6": true
+	}`), &fields)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fields.Summary != "Custom" {
+		t.Errorf("expected typed fields to be decoded, got summary %q", fields.Summary)
+	}
+
+	want := map[string]string{
+		"customfield_1": "text",
+		"customfield_2": "2.5",
+		"customfield_3": "Option A",
+		"customfield_4": "Sprint 1, Sprint 2",
+		"customfield_5": "Ada",
+		"customfield_6": "true",
+		"customfield_7": "",
+	}
+	for id, text := range want {
+		if got := fields.CustomText(id); got != text {
+			t.Errorf("CustomText(%s) = %q, want %q", id, got, text)
+		}
+	}
+}
+
+func TestHTTPClient_MyselfAndProject(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/rest/api/3/myself":
+			_, _ = w.Write([]byte(`{"displayName":"Jane Doe","emailAddress":"jane@example.com"}`))
+		case "/rest/api/3/project/TEST":
+			_, _ = w.Write([]byte(`{"key":"TEST","name":"Test Project"}`))
+		case "/rest/api/3/project/TEST/statuses":
+			_, _ = w.Write([]byte(`[
+				{"name":"Task","statuses":[{"name":"To Do"},{"name":"In Progress"}]},
+				{"name":"Bug","statuses":[{"name":"In Progress"},{"name":"Done"}]}
+			]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test@example.com", "token123")
+
+	user, err := client.Myself()
+	if err != nil || user.DisplayName != "Jane Doe" {
+		t.Errorf("Myself() = %+v, %v", user, err)
+	}
+
+	project, err := client.GetProject("TEST")
+	if err != nil || project.Name != "Test Project" {
+		t.Errorf("GetProject() = %+v, %v", project, err)
+	}
+
+	statuses, err := client.ProjectStatuses("TEST")
+	if err != nil {
+		t.Fatalf("ProjectStatuses() error = %v", err)
+	}
+	var names []string
+	for _, status := range statuses {
+		names = append(names, status.Name)
+	}
+	if got := strings.Join(names, ","); got != "To Do,In Progress,Done" {
+		t.Errorf("ProjectStatuses() = %s", got)
+	}
+}
+
+func TestHTTPClient_ListProjects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/rest/api/3/project/search":
+			if r.URL.Query().Get("startAt") == "0" {
+				_, _ = w.Write([]byte(`{"startAt":0,"isLast":false,"values":[{"key":"ABC","name":"Alpha"}]}`))
+			} else {
+				_, _ = w.Write([]byte(`{"startAt":1,"isLast":true,"values":[{"key":"XYZ","name":"Omega"}]}`))
+			}
+		case "/rest/api/2/project":
+			_, _ = w.Write([]byte(`[{"key":"SRV","name":"Server"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	projects, err := NewClient(server.URL, "test@example.com", "token123").ListProjects()
+	if err != nil || len(projects) != 2 || projects[1].Key != "XYZ" {
+		t.Errorf("ListProjects() on Cloud = %+v, %v", projects, err)
+	}
+
+	projects, err = NewServerClient(server.URL, "pat").ListProjects()
+	if err != nil || len(projects) != 1 || projects[0].Name != "Server" {
+		t.Errorf("ListProjects() on Server = %+v, %v", projects, err)
+	}
+}
+
 func TestHTTPClient_APIError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, `{"errorMessages":["Issue not found"]}`, http.StatusNotFound)
@@ -108,7 +411,105 @@ func TestHTTPClient_APIError(t *testing.T) {
 	client := NewClient(server.URL, "test@example.com", "token123")
 	_, err := client.GetIssue("NONEXISTENT-999")
 	if err == nil {
-		t.Error("expected error for non-existent issue")
+		t.Fatal("expected error for non-existent issue")
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected APIError with status 404, got %v", err)
+	}
+}
+
+func TestHTTPClient_Retry(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		statuses   []int
+		retryAfter string
+		wantStatus int
+		wantDelays []time.Duration
+	}{
+		{"rate limited GET", http.MethodGet, []int{429, 200}, "", 200, []time.Duration{time.Second}},
+		{"Retry-After seconds", http.MethodGet, []int{429, 429, 200}, "5", 200, []time.Duration{5 * time.Second, 5 * time.Second}},
+		{"Retry-After capped", http.MethodGet, []int{429, 200}, "3600", 200, []time.Duration{maxRetryDelay}},
+		{"server error GET gives up", http.MethodGet, []int{503, 503, 503, 503, 200}, "", 503,
+			[]time.Duration{time.Second, 2 * time.Second, 4 * time.Second}},
+		{"rate limited POST", http.MethodPost, []int{429, 201}, "", 201, []time.Duration{time.Second}},
+		{"server error POST", http.MethodPost, []int{500, 201}, "", 500, nil},
+		{"server error PUT", http.MethodPut, []int{502, 204}, "", 204, []time.Duration{time.Second}},
+		{"client error", http.MethodGet, []int{404, 200}, "", 404, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != tt.method {
+					t.Errorf("expected %s, got %s", tt.method, r.Method)
+				}
+				if r.Method == http.MethodPost {
+					if body, _ := io.ReadAll(r.Body); string(body) != `{"a":1}` {
+						t.Errorf("request %d sent body %q", requests, body)
+					}
+				}
+				status := tt.statuses[requests]
+				requests++
+				if status == 429 && tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, "test@example.com", "token123")
+			var delays []time.Duration
+			client.sleep = func(d time.Duration) { delays = append(delays, d) }
+
+			var body []byte
+			if tt.method == http.MethodPost {
+				body = []byte(`{"a":1}`)
+			}
+			resp, err := client.Do(tt.method, "issue", body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if requests != len(tt.wantDelays)+1 {
+				t.Errorf("sent %d requests, want %d", requests, len(tt.wantDelays)+1)
+			}
+			if !slices.Equal(delays, tt.wantDelays) {
+				t.Errorf("delays = %v, want %v", delays, tt.wantDelays)
+			}
+		})
+	}
+}
+
+func TestBackoff_RetryAfterDate(t *testing.T) {
+	past := time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)
+	if got := backoff(0, past); got != 0 {
+		t.Errorf("backoff for a past date = %v, want 0", got)
+	}
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got := backoff(0, future); got != maxRetryDelay {
+		t.Errorf("backoff for a distant date = %v, want %v", got, maxRetryDelay)
+	}
+	if got := backoff(2, "soon"); got != 4*time.Second {
+		t.Errorf("backoff with an invalid Retry-After = %v, want 4s", got)
+	}
+}
+
+type failingSource struct{}
+
+func (failingSource) Token() (string, error)   { return "", errors.New("not logged in") }
+func (failingSource) Refresh() (string, error) { return "", errors.New("not logged in") }
+
+func TestOAuthClient_AuthError(t *testing.T) {
+	client := NewOAuthClient("https://api.example.com/ex/jira/c1", failingSource{})
+	_, err := client.Myself()
+	var authErr *AuthError
+	if !errors.As(err, &authErr) || err.Error() != "not logged in" {
+		t.Errorf("expected AuthError, got %v", err)
 	}
 }
 
@@ -142,6 +543,19 @@ func TestMockClient(t *testing.T) {
 		}
 	})
 
+	t.Run("SearchJQL records query", func(t *testing.T) {
+		issues, err := mock.SearchJQL("project = MOCK")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(issues) != 2 {
+			t.Errorf("expected 2 issues, got %d", len(issues))
+		}
+		if mock.LastJQL != "project = MOCK" {
+			t.Errorf("expected recorded JQL, got %q", mock.LastJQL)
+		}
+	})
+
 	t.Run("GetIssue returns issue by key", func(t *testing.T) {
 		issue, err := mock.GetIssue("MOCK-1")
 		if err != nil {
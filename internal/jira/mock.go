@@ -1,15 +1,30 @@
 package jira
 
+import (
+	"maps"
+	"slices"
+)
+
 type MockClient struct {
 	Issues     []Issue
 	IssueByKey map[string]*Issue
 	SearchErr  error
 	GetErr     error
+	LastJQL    string
+	LastSearch SearchOptions
+
+	User      *User
+	MyselfErr error
+	Projects  map[string]*Project
+	Statuses  map[string][]Status
 }
 
 func NewMockClient() *MockClient {
 	return &MockClient{
 		IssueByKey: make(map[string]*Issue),
+		User:       &User{DisplayName: "Mock User"},
+		Projects:   make(map[string]*Project),
+		Statuses:   make(map[string][]Status),
 	}
 }
 
@@ -32,6 +47,29 @@ func (m *MockClient) SearchIssues(project, status string) ([]Issue, error) {
 	return filtered, nil
 }
 
+// SearchJQL records the query and returns all issues, since the mock cannot
+// evaluate JQL.
+func (m *MockClient) SearchJQL(jql string) ([]Issue, error) {
+	m.LastJQL = jql
+	if m.SearchErr != nil {
+		return nil, m.SearchErr
+	}
+	return m.Issues, nil
+}
+
+// Search records the query and options and returns up to opts.Limit issues.
+func (m *MockClient) Search(jql string, opts SearchOptions) ([]Issue, error) {
+	m.LastSearch = opts
+	issues, err := m.SearchJQL(jql)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Limit > 0 && len(issues) > opts.Limit {
+		issues = issues[:opts.Limit]
+	}
+	return issues, nil
+}
+
 func (m *MockClient) GetIssue(key string) (*Issue, error) {
 	if m.GetErr != nil {
 		return nil, m.GetErr
@@ -44,6 +82,37 @@ func (m *MockClient) GetIssue(key string) (*Issue, error) {
 	return issue, nil
 }
 
+func (m *MockClient) Myself() (*User, error) {
+	if m.MyselfErr != nil {
+		return nil, m.MyselfErr
+	}
+	return m.User, nil
+}
+
+func (m *MockClient) GetProject(key string) (*Project, error) {
+	project, ok := m.Projects[key]
+	if !ok {
+		return nil, &APIError{StatusCode: 404, Body: "No project could be found with key '" + key + "'."}
+	}
+	return project, nil
+}
+
+// ListProjects returns the mock's projects sorted by key.
+func (m *MockClient) ListProjects() ([]Project, error) {
+	projects := make([]Project, 0, len(m.Projects))
+	for _, key := range slices.Sorted(maps.Keys(m.Projects)) {
+		projects = append(projects, *m.Projects[key])
+	}
+	return projects, nil
+}
+
+func (m *MockClient) ProjectStatuses(key string) ([]Status, error) {
+	if _, ok := m.Projects[key]; !ok {
+		return nil, &APIError{StatusCode: 404, Body: "No project could be found with key '" + key + "'."}
+	}
+	return m.Statuses[key], nil
+}
+
 type NotFoundError struct {
 	Key string
 }
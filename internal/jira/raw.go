@@ -0,0 +1,196 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Response is the reply to a raw request.
+type Response struct {
+	StatusCode int
+	Body       []byte
+}
+
+// OK reports whether the status code is 2xx.
+func (r *Response) OK() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// Do sends a request with an optional JSON body and returns the response
+// whatever its status code. A path starting with "/" is relative to the
+// site, e.g. "/rest/agile/1.0/board"; other paths are relative to the REST
+// API of the deployment, e.g. "myself" or "issue/PROJ-1?fields=summary".
+func (c *HTTPClient) Do(method, path string, body []byte) (*Response, error) {
+	rawURL, err := c.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	status, respBody, err := c.do(method, rawURL, body)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{StatusCode: status, Body: respBody}, nil
+}
+
+// Paginate fetches all pages of a GET endpoint and merges them into one
+// response. It follows both pagination styles of Jira: nextPageToken and
+// isLast, as in Cloud's search/jql, and startAt with total or isLast, as in
+// most other lists. The items of all pages are collected in the list field
+// of the first page. A page with an error status is returned as is.
+func (c *HTTPClient) Paginate(path string) (*Response, error) {
+	rawURL, err := c.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	var (
+		first   map[string]json.RawMessage
+		listKey string
+		items   []json.RawMessage
+	)
+	for {
+		status, body, err := c.do(http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		resp := &Response{StatusCode: status, Body: body}
+		if !resp.OK() {
+			return resp, nil
+		}
+
+		var page map[string]json.RawMessage
+		if err := json.Unmarshal(body, &page); err != nil {
+			if first == nil {
+				// Not a paginated object, e.g. a plain array.
+				return resp, nil
+			}
+			return nil, fmt.Errorf("failed to parse page: %w", err)
+		}
+		if first == nil {
+			first = page
+			if listKey = pageList(page); listKey == "" {
+				return resp, nil
+			}
+		}
+
+		var pageItems []json.RawMessage
+		if err := json.Unmarshal(page[listKey], &pageItems); err != nil {
+			return nil, fmt.Errorf("failed to parse %s of page: %w", listKey, err)
+		}
+		items = append(items, pageItems...)
+
+		query, more := nextPage(page, len(pageItems))
+		if !more {
+			break
+		}
+		q := u.Query()
+		for key, value := range query {
+			q.Set(key, value)
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	if items == nil {
+		items = []json.RawMessage{}
+	}
+	merged, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	first[listKey] = merged
+	delete(first, "nextPageToken")
+	if _, ok := first["isLast"]; ok {
+		first["isLast"] = json.RawMessage("true")
+	}
+	body, err := json.Marshal(first)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{StatusCode: http.StatusOK, Body: body}, nil
+}
+
+// resolve returns the URL of a path given to Do.
+func (c *HTTPClient) resolve(path string) (string, error) {
+	if strings.Contains(path, "://") {
+		return "", fmt.Errorf("invalid path %q: give a path on the Jira site, not a URL", path)
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = c.api("/" + path)
+	}
+	rawURL := strings.TrimSuffix(c.baseURL, "/") + path
+	if _, err := url.Parse(rawURL); err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	return rawURL, nil
+}
+
+// pageList returns the field holding the items of a page: "values" or
+// "issues" if present, otherwise the only array field.
+func pageList(page map[string]json.RawMessage) string {
+	var arrays []string
+	for key, value := range page {
+		if strings.HasPrefix(strings.TrimSpace(string(value)), "[") {
+			arrays = append(arrays, key)
+		}
+	}
+	for _, key := range []string{"values", "issues"} {
+		if slices.Contains(arrays, key) {
+			return key
+		}
+	}
+	if len(arrays) == 1 {
+		return arrays[0]
+	}
+	return ""
+}
+
+// nextPage returns the query parameters of the page after page, which held
+// n items, and whether there is one.
+func nextPage(page map[string]json.RawMessage, n int) (map[string]string, bool) {
+	var isLast bool
+	if value, ok := page["isLast"]; ok {
+		_ = json.Unmarshal(value, &isLast)
+	}
+	if isLast || n == 0 {
+		return nil, false
+	}
+
+	if value, ok := page["nextPageToken"]; ok {
+		var token string
+		if err := json.Unmarshal(value, &token); err != nil || token == "" {
+			return nil, false
+		}
+		return map[string]string{"nextPageToken": token}, true
+	}
+
+	value, ok := page["startAt"]
+	if !ok {
+		return nil, false
+	}
+	var startAt int
+	if err := json.Unmarshal(value, &startAt); err != nil {
+		return nil, false
+	}
+	if value, ok := page["total"]; ok {
+		var total int
+		if err := json.Unmarshal(value, &total); err == nil && startAt+n >= total {
+			return nil, false
+		}
+	} else if _, ok := page["isLast"]; !ok {
+		// Without total or isLast the end is a short page.
+		var maxResults int
+		if value, ok := page["maxResults"]; !ok || json.Unmarshal(value, &maxResults) != nil || n < maxResults {
+			return nil, false
+		}
+	}
+	return map[string]string{"startAt": strconv.Itoa(startAt + n)}, true
+}
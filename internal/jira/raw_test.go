@@ -0,0 +1,107 @@
+package jira
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestHTTPClient_Do(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method == http.MethodPost && string(body) != `{"body":"hi"}` {
+			t.Errorf("unexpected body %q", body)
+		}
+		if r.URL.Path == "/rest/api/3/missing" {
+			http.Error(w, `{"errorMessages":["not found"]}`, http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"path":"` + r.URL.Path + `","q":"` + r.URL.Query().Get("q") + `"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test@example.com", "token123")
+	tests := []struct {
+		method string
+		path   string
+		body   []byte
+		status int
+		want   string
+	}{
+		{method: "GET", path: "myself", status: 200, want: `{"path":"/rest/api/3/myself","q":""}`},
+		{method: "GET", path: "/rest/agile/1.0/board?q=x", status: 200, want: `{"path":"/rest/agile/1.0/board","q":"x"}`},
+		{method: "POST", path: "issue/TEST-1/comment", body: []byte(`{"body":"hi"}`), status: 200, want: `{"path":"/rest/api/3/issue/TEST-1/comment","q":""}`},
+		{method: "GET", path: "missing", status: 404, want: "{\"errorMessages\":[\"not found\"]}\n"},
+	}
+	for _, tt := range tests {
+		resp, err := client.Do(tt.method, tt.path, tt.body)
+		if err != nil {
+			t.Fatalf("Do(%s %s) error = %v", tt.method, tt.path, err)
+		}
+		if resp.StatusCode != tt.status || string(resp.Body) != tt.want || resp.OK() != (tt.status == 200) {
+			t.Errorf("Do(%s %s) = %d %q, want %d %q", tt.method, tt.path, resp.StatusCode, resp.Body, tt.status, tt.want)
+		}
+	}
+
+	if _, err := client.Do("GET", "https://example.com/x", nil); err == nil {
+		t.Error("expected an error for an absolute URL")
+	}
+}
+
+func TestHTTPClient_Paginate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		var page any
+		switch r.URL.Path {
+		case "/rest/api/3/search/jql":
+			if query.Get("jql") != "project = TEST" {
+				t.Errorf("query lost between pages: %v", query)
+			}
+			switch query.Get("nextPageToken") {
+			case "":
+				page = map[string]any{"issues": []any{map[string]string{"key": "TEST-1"}}, "nextPageToken": "p2", "isLast": false}
+			case "p2":
+				page = map[string]any{"issues": []any{map[string]string{"key": "TEST-2"}}, "isLast": true}
+			}
+		case "/rest/api/3/issue/TEST-1/comment":
+			startAt, _ := strconv.Atoi(query.Get("startAt"))
+			comments := []int{1, 2, 3, 4, 5}[startAt:min(startAt+2, 5)]
+			page = map[string]any{"startAt": startAt, "maxResults": 2, "total": 5, "comments": comments}
+		case "/rest/api/3/project/search":
+			startAt, _ := strconv.Atoi(query.Get("startAt"))
+			page = map[string]any{"startAt": startAt, "maxResults": 1, "isLast": startAt == 1, "values": []int{startAt}}
+		case "/rest/api/3/project":
+			page = []string{"a", "b"}
+		default:
+			http.Error(w, "nope", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test@example.com", "token123")
+	tests := []struct {
+		path   string
+		status int
+		want   string
+	}{
+		{path: "search/jql?jql=project+%3D+TEST", status: 200, want: `{"isLast":true,"issues":[{"key":"TEST-1"},{"key":"TEST-2"}]}`},
+		{path: "issue/TEST-1/comment", status: 200, want: `{"comments":[1,2,3,4,5],"maxResults":2,"startAt":0,"total":5}`},
+		{path: "project/search", status: 200, want: `{"isLast":true,"maxResults":1,"startAt":0,"values":[0,1]}`},
+		{path: "project", status: 200, want: "[\"a\",\"b\"]\n"},
+		{path: "missing", status: 404, want: "nope\n"},
+	}
+	for _, tt := range tests {
+		resp, err := client.Paginate(tt.path)
+		if err != nil {
+			t.Fatalf("Paginate(%s) error = %v", tt.path, err)
+		}
+		if resp.StatusCode != tt.status || string(resp.Body) != tt.want {
+			t.Errorf("Paginate(%s) = %d %s, want %d %s", tt.path, resp.StatusCode, resp.Body, tt.status, tt.want)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package jira
 
 import (
 	"encoding/json"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,7 +14,7 @@ type Issue struct {
 
 type IssueFields struct {
 	Summary     string          `json:"summary"`
-	Description json.RawMessage `json:"description"` // ADF format in API v3
+	Description json.RawMessage `json:"description"` // ADF in API v3, wiki markup string in API v2
 	Status      Status          `json:"status"`
 	IssueType   Type            `json:"issuetype"`
 	Priority    *Priority       `json:"priority,omitempty"`
@@ -20,6 +22,75 @@ type IssueFields struct {
 	Reporter    *User           `json:"reporter,omitempty"`
 	Created     string          `json:"created"`
 	Updated     string          `json:"updated"`
+
+	// Custom holds the requested custom fields (customfield_*) as raw JSON.
+	Custom map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes the typed fields and keeps custom fields in Custom.
+func (f *IssueFields) UnmarshalJSON(data []byte) error {
+	type plain IssueFields
+	if err := json.Unmarshal(data, (*plain)(f)); err != nil {
+		return err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	for name, value := range all {
+		if !strings.HasPrefix(name, "customfield_") || string(value) == "null" {
+			continue
+		}
+		if f.Custom == nil {
+			f.Custom = make(map[string]json.RawMessage)
+		}
+		f.Custom[name] = value
+	}
+	return nil
+}
+
+// CustomText renders a custom field as text. Options, users and similar
+// objects are shown by their value, name or display name; lists are joined
+// with commas.
+func (f IssueFields) CustomText(id string) string {
+	raw, ok := f.Custom[id]
+	if !ok {
+		return ""
+	}
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return ""
+	}
+	return customText(v)
+}
+
+func customText(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			if text := customText(item); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		return strings.Join(parts, ", ")
+	case map[string]any:
+		for _, key := range []string{"value", "name", "displayName", "key"} {
+			if text, ok := v[key].(string); ok {
+				return text
+			}
+		}
+	}
+	return ""
 }
 
 type Status struct {
@@ -39,11 +110,20 @@ type User struct {
 	EmailAddress string `json:"emailAddress"`
 }
 
+type Project struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+// SearchResult covers both pagination styles: offset based (startAt, total)
+// on Server and token based (nextPageToken, isLast) on Cloud's /search/jql.
 type SearchResult struct {
-	StartAt    int     `json:"startAt"`
-	MaxResults int     `json:"maxResults"`
-	Total      int     `json:"total"`
-	Issues     []Issue `json:"issues"`
+	StartAt       int     `json:"startAt"`
+	MaxResults    int     `json:"maxResults"`
+	Total         int     `json:"total"`
+	Issues        []Issue `json:"issues"`
+	NextPageToken string  `json:"nextPageToken,omitempty"`
+	IsLast        bool    `json:"isLast,omitempty"`
 }
 
 type SelectedIssue struct {
@@ -51,3 +131,50 @@ type SelectedIssue struct {
 	Summary    string    `json:"summary"`
 	SelectedAt time.Time `json:"selected_at"`
 }
+
+// DescriptionText returns the description as text. API v2 returns wiki
+// markup, which is kept as is; for the ADF documents of API v3 the text
+// nodes are joined, with paragraphs and similar blocks on separate lines.
+func (f IssueFields) DescriptionText() string {
+	if len(f.Description) == 0 || string(f.Description) == "null" {
+		return ""
+	}
+
+	var markup string
+	if err := json.Unmarshal(f.Description, &markup); err == nil {
+		return markup
+	}
+
+	var doc adfNode
+	if err := json.Unmarshal(f.Description, &doc); err != nil {
+		return ""
+	}
+	var b strings.Builder
+	doc.writeText(&b)
+	return strings.TrimSpace(b.String())
+}
+
+// adfNode is the subset of the Atlassian Document Format needed for text.
+type adfNode struct {
+	Type    string    `json:"type"`
+	Text    string    `json:"text"`
+	Content []adfNode `json:"content"`
+}
+
+func (n adfNode) writeText(b *strings.Builder) {
+	switch n.Type {
+	case "text":
+		b.WriteString(n.Text)
+	case "hardBreak":
+		b.WriteString("\n")
+	}
+	for _, child := range n.Content {
+		child.writeText(b)
+	}
+	switch n.Type {
+	case "paragraph", "heading", "listItem", "codeBlock", "blockquote":
+		if !strings.HasSuffix(b.String(), "\n") {
+			b.WriteString("\n")
+		}
+	}
+}
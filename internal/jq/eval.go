@@ -0,0 +1,498 @@
+package jq
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type node interface {
+	eval(v any) ([]any, error)
+}
+
+type identityNode struct{}
+
+func (identityNode) eval(v any) ([]any, error) {
+	return []any{v}, nil
+}
+
+type literalNode struct {
+	value any
+}
+
+func (n literalNode) eval(any) ([]any, error) {
+	return []any{n.value}, nil
+}
+
+type pipeNode struct {
+	left, right node
+}
+
+func (n pipeNode) eval(v any) ([]any, error) {
+	inputs, err := n.left.eval(v)
+	if err != nil {
+		return nil, err
+	}
+	var out []any
+	for _, input := range inputs {
+		results, err := n.right.eval(input)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, results...)
+	}
+	return out, nil
+}
+
+type commaNode []node
+
+func (n commaNode) eval(v any) ([]any, error) {
+	var out []any
+	for _, item := range n {
+		results, err := item.eval(v)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, results...)
+	}
+	return out, nil
+}
+
+// alternativeNode yields the truthy outputs of left, or else those of right.
+type alternativeNode struct {
+	left, right node
+}
+
+func (n alternativeNode) eval(v any) ([]any, error) {
+	var out []any
+	if results, err := n.left.eval(v); err == nil {
+		for _, r := range results {
+			if truthy(r) {
+				out = append(out, r)
+			}
+		}
+	}
+	if len(out) > 0 {
+		return out, nil
+	}
+	return n.right.eval(v)
+}
+
+type logicNode struct {
+	op          string
+	left, right node
+}
+
+func (n logicNode) eval(v any) ([]any, error) {
+	lefts, err := n.left.eval(v)
+	if err != nil {
+		return nil, err
+	}
+	var out []any
+	for _, l := range lefts {
+		if n.op == "and" && !truthy(l) || n.op == "or" && truthy(l) {
+			out = append(out, n.op == "or")
+			continue
+		}
+		rights, err := n.right.eval(v)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rights {
+			out = append(out, truthy(r))
+		}
+	}
+	return out, nil
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n compareNode) eval(v any) ([]any, error) {
+	return cartesian(v, n.left, n.right, func(l, r any) (any, error) {
+		c := compare(l, r)
+		switch n.op {
+		case "==":
+			return c == 0, nil
+		case "!=":
+			return c != 0, nil
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		default:
+			return c >= 0, nil
+		}
+	})
+}
+
+// indexNode is .name, .[i] and .["name"].
+type indexNode struct {
+	target, index node
+}
+
+func (n indexNode) eval(v any) ([]any, error) {
+	return cartesian(v, n.target, n.index, index)
+}
+
+func index(target, key any) (any, error) {
+	if target == nil {
+		return nil, nil
+	}
+	switch t := target.(type) {
+	case map[string]any:
+		if k, ok := key.(string); ok {
+			return t[k], nil
+		}
+	case []any:
+		if f, ok := toFloat(key); ok {
+			i := int(math.Floor(f))
+			if i < 0 {
+				i += len(t)
+			}
+			if i < 0 || i >= len(t) {
+				return nil, nil
+			}
+			return t[i], nil
+		}
+	}
+	return nil, fmt.Errorf("cannot index %s with %s", typeName(target), describe(key))
+}
+
+type iterateNode struct {
+	target node
+}
+
+func (n iterateNode) eval(v any) ([]any, error) {
+	targets, err := n.target.eval(v)
+	if err != nil {
+		return nil, err
+	}
+	var out []any
+	for _, target := range targets {
+		switch t := target.(type) {
+		case []any:
+			out = append(out, t...)
+		case map[string]any:
+			for _, key := range sortedKeys(t) {
+				out = append(out, t[key])
+			}
+		default:
+			return nil, fmt.Errorf("cannot iterate over %s", typeName(target))
+		}
+	}
+	return out, nil
+}
+
+type sliceNode struct {
+	target, from, to node
+}
+
+func (n sliceNode) eval(v any) ([]any, error) {
+	targets, err := n.target.eval(v)
+	if err != nil {
+		return nil, err
+	}
+	bound := func(bn node, def int, length int) (int, error) {
+		if bn == nil {
+			return def, nil
+		}
+		results, err := bn.eval(v)
+		if err != nil {
+			return 0, err
+		}
+		if len(results) != 1 {
+			return 0, fmt.Errorf("slice bounds must be single numbers")
+		}
+		f, ok := toFloat(results[0])
+		if !ok {
+			return 0, fmt.Errorf("slice bounds must be numbers, got %s", typeName(results[0]))
+		}
+		i := int(math.Floor(f))
+		if i < 0 {
+			i += length
+		}
+		return min(max(i, 0), length), nil
+	}
+
+	var out []any
+	for _, target := range targets {
+		var length int
+		switch t := target.(type) {
+		case nil:
+			out = append(out, nil)
+			continue
+		case []any:
+			length = len(t)
+		case string:
+			length = len([]rune(t))
+		default:
+			return nil, fmt.Errorf("cannot slice %s", typeName(target))
+		}
+		from, err := bound(n.from, 0, length)
+		if err != nil {
+			return nil, err
+		}
+		to, err := bound(n.to, length, length)
+		if err != nil {
+			return nil, err
+		}
+		to = max(from, to)
+		if s, ok := target.(string); ok {
+			out = append(out, string([]rune(s)[from:to]))
+		} else {
+			out = append(out, slices.Clone(target.([]any)[from:to]))
+		}
+	}
+	return out, nil
+}
+
+// tryNode is a?, which drops the errors of a.
+type tryNode struct {
+	target node
+}
+
+func (n tryNode) eval(v any) ([]any, error) {
+	results, err := n.target.eval(v)
+	if err != nil {
+		return nil, nil
+	}
+	return results, nil
+}
+
+// collectNode is [a], the array of the outputs of a.
+type collectNode struct {
+	inner node
+}
+
+func (n collectNode) eval(v any) ([]any, error) {
+	if n.inner == nil {
+		return []any{[]any{}}, nil
+	}
+	results, err := n.inner.eval(v)
+	if err != nil {
+		return nil, err
+	}
+	if results == nil {
+		results = []any{}
+	}
+	return []any{results}, nil
+}
+
+type objectEntry struct {
+	key, value node
+}
+
+// objectNode is {k: v, ...}; several outputs of a key or value yield one
+// object per combination, like in jq.
+type objectNode []objectEntry
+
+func (n objectNode) eval(v any) ([]any, error) {
+	objects := []map[string]any{{}}
+	for _, entry := range n {
+		keys, err := entry.key.eval(v)
+		if err != nil {
+			return nil, err
+		}
+		values, err := entry.value.eval(v)
+		if err != nil {
+			return nil, err
+		}
+		var next []map[string]any
+		for _, obj := range objects {
+			for _, key := range keys {
+				k, ok := key.(string)
+				if !ok {
+					return nil, fmt.Errorf("object keys must be strings, got %s", typeName(key))
+				}
+				for _, value := range values {
+					o := make(map[string]any, len(obj)+1)
+					for ok, ov := range obj {
+						o[ok] = ov
+					}
+					o[k] = value
+					next = append(next, o)
+				}
+			}
+		}
+		objects = next
+	}
+	out := make([]any, len(objects))
+	for i, obj := range objects {
+		out[i] = obj
+	}
+	return out, nil
+}
+
+type callNode struct {
+	name string
+	fn   func(v any, args []node) ([]any, error)
+	args []node
+}
+
+func (n callNode) eval(v any) ([]any, error) {
+	results, err := n.fn(v, n.args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	return results, nil
+}
+
+// cartesian evaluates left and right on v and applies f to each pair of
+// outputs.
+func cartesian(v any, left, right node, f func(l, r any) (any, error)) ([]any, error) {
+	lefts, err := left.eval(v)
+	if err != nil {
+		return nil, err
+	}
+	rights, err := right.eval(v)
+	if err != nil {
+		return nil, err
+	}
+	var out []any
+	for _, l := range lefts {
+		for _, r := range rights {
+			result, err := f(l, r)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, result)
+		}
+	}
+	return out, nil
+}
+
+func truthy(v any) bool {
+	return v != nil && v != false
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func number(n int) json.Number {
+	return json.Number(strconv.Itoa(n))
+}
+
+// typeName returns the jq type of v.
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	if _, ok := toFloat(v); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func describe(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return typeName(v)
+	}
+	return typeName(v) + " " + string(data)
+}
+
+// rank orders values of different types like jq: null, false, true,
+// numbers, strings, arrays, objects.
+func rank(v any) int {
+	switch t := v.(type) {
+	case nil:
+		return 0
+	case bool:
+		if t {
+			return 2
+		}
+		return 1
+	case string:
+		return 4
+	case []any:
+		return 5
+	case map[string]any:
+		return 6
+	}
+	return 3
+}
+
+// compare orders a and b like jq.
+func compare(a, b any) int {
+	if ra, rb := rank(a), rank(b); ra != rb {
+		return ra - rb
+	}
+	switch x := a.(type) {
+	case string:
+		return strings.Compare(x, b.(string))
+	case []any:
+		y := b.([]any)
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if c := compare(x[i], y[i]); c != 0 {
+				return c
+			}
+		}
+		return len(x) - len(y)
+	case map[string]any:
+		y := b.(map[string]any)
+		kx, ky := sortedKeys(x), sortedKeys(y)
+		if c := compare(toAny(kx), toAny(ky)); c != 0 {
+			return c
+		}
+		for _, k := range kx {
+			if c := compare(x[k], y[k]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+	fa, _ := toFloat(a)
+	fb, _ := toFloat(b)
+	switch {
+	case fa < fb:
+		return -1
+	case fa > fb:
+		return 1
+	}
+	return 0
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toAny(s []string) []any {
+	out := make([]any, len(s))
+	for i, v := range s {
+		out[i] = v
+	}
+	return out
+}
@@ -0,0 +1,169 @@
+package jq
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type function struct {
+	arity int
+	fn    func(v any, args []node) ([]any, error)
+}
+
+var functions = map[string]function{
+	"empty":    {0, func(any, []node) ([]any, error) { return nil, nil }},
+	"not":      {0, func(v any, _ []node) ([]any, error) { return []any{!truthy(v)}, nil }},
+	"type":     {0, func(v any, _ []node) ([]any, error) { return []any{typeName(v)}, nil }},
+	"length":   {0, single(length)},
+	"keys":     {0, single(keys)},
+	"tostring": {0, single(tostring)},
+	"tonumber": {0, single(tonumber)},
+	"first":    {0, single(func(v any) (any, error) { return index(v, 0) })},
+	"last":     {0, single(func(v any) (any, error) { return index(v, -1) })},
+	"sort":     {0, single(sortValues)},
+	"select":   {1, selectValues},
+	"map":      {1, mapValues},
+	"has":      {1, withArg(has)},
+	"join":     {1, withArg(join)},
+}
+
+// single adapts a function of the input alone.
+func single(f func(v any) (any, error)) func(any, []node) ([]any, error) {
+	return func(v any, _ []node) ([]any, error) {
+		result, err := f(v)
+		if err != nil {
+			return nil, err
+		}
+		return []any{result}, nil
+	}
+}
+
+// withArg adapts a function of the input and each output of its argument.
+func withArg(f func(v, arg any) (any, error)) func(any, []node) ([]any, error) {
+	return func(v any, args []node) ([]any, error) {
+		return cartesian(v, identityNode{}, args[0], f)
+	}
+}
+
+func length(v any) (any, error) {
+	switch t := v.(type) {
+	case nil:
+		return number(0), nil
+	case string:
+		return number(utf8.RuneCountInString(t)), nil
+	case []any:
+		return number(len(t)), nil
+	case map[string]any:
+		return number(len(t)), nil
+	}
+	if f, ok := toFloat(v); ok {
+		return json.Number(strconv.FormatFloat(max(f, -f), 'f', -1, 64)), nil
+	}
+	return nil, fmt.Errorf("%s has no length", typeName(v))
+}
+
+func keys(v any) (any, error) {
+	switch t := v.(type) {
+	case map[string]any:
+		return toAny(sortedKeys(t)), nil
+	case []any:
+		out := make([]any, len(t))
+		for i := range t {
+			out[i] = number(i)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("%s has no keys", typeName(v))
+}
+
+func tostring(v any) (any, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func tonumber(v any) (any, error) {
+	if _, ok := toFloat(v); ok {
+		return v, nil
+	}
+	if s, ok := v.(string); ok {
+		if _, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+			return json.Number(strings.TrimSpace(s)), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot parse %s as a number", describe(v))
+}
+
+func sortValues(v any) (any, error) {
+	arr, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("cannot sort %s", typeName(v))
+	}
+	sorted := slices.Clone(arr)
+	slices.SortStableFunc(sorted, compare)
+	return sorted, nil
+}
+
+func selectValues(v any, args []node) ([]any, error) {
+	conditions, err := args[0].eval(v)
+	if err != nil {
+		return nil, err
+	}
+	var out []any
+	for _, c := range conditions {
+		if truthy(c) {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+func mapValues(v any, args []node) ([]any, error) {
+	return collectNode{pipeNode{iterateNode{identityNode{}}, args[0]}}.eval(v)
+}
+
+func has(v, key any) (any, error) {
+	switch t := v.(type) {
+	case map[string]any:
+		if k, ok := key.(string); ok {
+			_, found := t[k]
+			return found, nil
+		}
+	case []any:
+		if f, ok := toFloat(key); ok {
+			return f >= 0 && int(f) < len(t), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot check whether %s has %s", typeName(v), describe(key))
+}
+
+func join(v, sep any) (any, error) {
+	arr, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("cannot join %s", typeName(v))
+	}
+	s, ok := sep.(string)
+	if !ok {
+		return nil, fmt.Errorf("separator must be a string, got %s", typeName(sep))
+	}
+	parts := make([]string, len(arr))
+	for i, item := range arr {
+		switch item.(type) {
+		case nil:
+		case string, bool, json.Number, float64:
+			parts[i] = fmt.Sprint(item)
+		default:
+			return nil, fmt.Errorf("cannot join %s", typeName(item))
+		}
+	}
+	return strings.Join(parts, s), nil
+}
@@ -0,0 +1,60 @@
+// Package jq implements a subset of the jq language for filtering JSON
+// responses, enough for picking fields out of Jira's replies without an
+// external jq:
+//
+//	.issues[] | select(.fields.status.name == "Done") | .key
+//	.values | map({id, name}) | length
+//	.fields.assignee.displayName // "unassigned"
+//
+// Supported are paths (.a.b, ."a b", .[0], .[], .[1:3], ? for optional),
+// pipes, commas, literals, array and object construction, the comparison
+// operators, and, or, // and the functions length, keys, not, type,
+// tostring, tonumber, select, map, has, join, first, last, sort and empty.
+//
+// Values are those of encoding/json, with numbers as json.Number when the
+// input was decoded with UseNumber. Unlike jq, objects are iterated in key
+// order and numbers keep the text they were written with.
+package jq
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Query is a compiled filter.
+type Query struct {
+	root node
+}
+
+// Compile parses a filter.
+func Compile(src string) (*Query, error) {
+	p := &parser{src: src}
+	if err := p.lex(); err != nil {
+		return nil, err
+	}
+	root, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorf(tok, "unexpected %s", tok)
+	}
+	return &Query{root: root}, nil
+}
+
+// Run applies the filter to v and returns its outputs.
+func (q *Query) Run(v any) ([]any, error) {
+	return q.root.eval(v)
+}
+
+// RunJSON decodes data and applies the filter to it.
+func (q *Query) RunJSON(data []byte) ([]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return q.Run(v)
+}
@@ -0,0 +1,304 @@
+package jq
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const input = `{
+  "total": 3,
+  "issues": [
+    {"key": "TEST-1", "fields": {"summary": "First", "status": {"name": "Done"}, "points": 5, "labels": ["a", "b"]}},
+    {"key": "TEST-2", "fields": {"summary": "Second", "status": {"name": "In Progress"}, "points": 2, "assignee": {"displayName": "Ann"}}},
+    {"key": "TEST-3", "fields": {"summary": "Third", "status": {"name": "Done"}, "points": 8}}
+  ],
+  "odd key": true
+}`
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   string
+	}{
+		{filter: ".", want: `{"issues":[{"fields":{"labels":["a","b"],"points":5,"status":{"name":"Done"},"summary":"First"},"key":"TEST-1"},{"fields":{"assignee":{"displayName":"Ann"},"points":2,"status":{"name":"In Progress"},"summary":"Second"},"key":"TEST-2"},{"fields":{"points":8,"status":{"name":"Done"},"summary":"Third"},"key":"TEST-3"}],"odd key":true,"total":3}`},
+		{filter: ".total", want: `3`},
+		{filter: `."odd key"`, want: `true`},
+		{filter: `.["total"]`, want: `3`},
+		{filter: ".issues[].key", want: `"TEST-1" "TEST-2" "TEST-3"`},
+		{filter: ".issues[0].fields.summary", want: `"First"`},
+		{filter: ".issues[-1].key", want: `"TEST-3"`},
+		{filter: ".issues[5]", want: `null`},
+		{filter: ".issues[1:] | length", want: `2`},
+		{filter: ".issues[0].key[:6]", want: `"TEST-1"`},
+		{filter: ".missing.deeper", want: `null`},
+		{filter: `.issues[] | select(.fields.status.name == "Done") | .key`, want: `"TEST-1" "TEST-3"`},
+		{filter: `.issues[] | select(.fields.points > 2 and .fields.points < 8) | .key`, want: `"TEST-1"`},
+		{filter: `.issues[] | select(.fields.points <= 2 or .key == "TEST-3") | .key`, want: `"TEST-2" "TEST-3"`},
+		{filter: `.issues[] | .fields.assignee.displayName // "unassigned"`, want: `"unassigned" "Ann" "unassigned"`},
+		{filter: `.issues | map(.key) | join(",")`, want: `"TEST-1,TEST-2,TEST-3"`},
+		{filter: `[.issues[] | .fields.points] | sort`, want: `[2,5,8]`},
+		{filter: `.issues[0] | {key, summary: .fields.summary, "n": 1}`, want: `{"key":"TEST-1","n":1,"summary":"First"}`},
+		{filter: `.issues[0] | {(.key): .fields.labels[]}`, want: `{"TEST-1":"a"} {"TEST-1":"b"}`},
+		{filter: `.issues[0].fields | keys`, want: `["labels","points","status","summary"]`},
+		{filter: `.issues[0].fields | has("labels"), has("assignee")`, want: `true false`},
+		{filter: `.total, .issues[0].key`, want: `3 "TEST-1"`},
+		{filter: `.issues | first.key, last.key`, want: `"TEST-1" "TEST-3"`},
+		{filter: `.total | tostring`, want: `"3"`},
+		{filter: `"42" | tonumber`, want: `42`},
+		{filter: `.issues[0].fields.labels | type`, want: `"array"`},
+		{filter: `.total == 3 | not`, want: `false`},
+		{filter: `[.issues[] | empty]`, want: `[]`},
+		{filter: `.total[]?`, want: ``},
+		{filter: `[.issues[].fields.points | select(. >= -1)] | length`, want: `3`},
+	}
+	for _, tt := range tests {
+		if got := run(t, tt.filter, input); got != tt.want {
+			t.Errorf("Run(%q) = %s, want %s", tt.filter, got, tt.want)
+		}
+	}
+}
+
+// TestOperators checks each supported operator and function on small
+// inputs. The expected outputs are those of jq 1.6 unless noted.
+func TestOperators(t *testing.T) {
+	tests := []struct {
+		input  string
+		filter string
+		want   string
+	}{
+		// Paths, iteration and slices.
+		{input: `null`, filter: `.`, want: `null`},
+		{input: `null`, filter: `.a`, want: `null`},
+		{input: `{"a":{"b":1}}`, filter: `.a.b`, want: `1`},
+		{input: `{"a":{"b":1}}`, filter: `.a?.b`, want: `1`},
+		{input: `{"a b":2}`, filter: `."a b"`, want: `2`},
+		{input: `{"a":1}`, filter: `.["a"]`, want: `1`},
+		{input: `[1,2,3]`, filter: `.[0]`, want: `1`},
+		{input: `[1,2,3]`, filter: `.[-1]`, want: `3`},
+		{input: `[1,2,3]`, filter: `.[-4]`, want: `null`},
+		{input: `[1,2,3]`, filter: `.[3]`, want: `null`},
+		{input: `[1,2,3,4]`, filter: `.[1:3]`, want: `[2,3]`},
+		{input: `[1,2,3,4]`, filter: `.[:2]`, want: `[1,2]`},
+		{input: `[1,2,3,4]`, filter: `.[-2:]`, want: `[3,4]`},
+		{input: `[1,2,3,4]`, filter: `.[3:1]`, want: `[]`},
+		{input: `"abcdef"`, filter: `.[2:4]`, want: `"cd"`},
+		{input: `null`, filter: `.[1:2]`, want: `null`},
+		{input: `[1,2,3]`, filter: `.[]`, want: `1 2 3`},
+		// Unlike jq, objects are iterated in key order, not insertion order.
+		{input: `{"b":2,"a":1}`, filter: `.[]`, want: `1 2`},
+		{input: `[]`, filter: `.[]`, want: ``},
+		{input: `1`, filter: `.[]?`, want: ``},
+		{input: `1`, filter: `.a?`, want: ``},
+		{input: `{"a":[1,2]}`, filter: `.a[]?`, want: `1 2`},
+		{input: `[[1,2],[3]]`, filter: `.[][]`, want: `1 2 3`},
+		{input: `{"a":1,"b":2}`, filter: `.a, .b`, want: `1 2`},
+		{input: `{"a":1,"b":2}`, filter: `.a | .`, want: `1`},
+		{input: `null`, filter: `1, 2 | .`, want: `1 2`},
+
+		// Literals, commas and construction.
+		{input: `null`, filter: `"x"`, want: `"x"`},
+		{input: `null`, filter: `-1`, want: `-1`},
+		{input: `null`, filter: `1.5`, want: `1.5`},
+		// Numbers keep the text they were written with.
+		{input: `null`, filter: `1e3`, want: `1e3`},
+		{input: `null`, filter: `true, false, null`, want: `true false null`},
+		{input: `null`, filter: `[]`, want: `[]`},
+		{input: `null`, filter: `[1, 2, 3]`, want: `[1,2,3]`},
+		{input: `null`, filter: `[1, (2, 3)]`, want: `[1,2,3]`},
+		{input: `{"a":[1,2]}`, filter: `[.a[] | . ]`, want: `[1,2]`},
+		{input: `null`, filter: `{}`, want: `{}`},
+		{input: `{"a":1}`, filter: `{a}`, want: `{"a":1}`},
+		{input: `{"a":1}`, filter: `{"b": .a}`, want: `{"b":1}`},
+		{input: `{"a":1}`, filter: `{(.a|tostring): 2}`, want: `{"1":2}`},
+		{input: `{"a":[1,2]}`, filter: `{x: .a[]}`, want: `{"x":1} {"x":2}`},
+		{input: `{"a":[1,2],"b":[3,4]}`, filter: `{x: .a[], y: .b[]}`, want: `{"x":1,"y":3} {"x":1,"y":4} {"x":2,"y":3} {"x":2,"y":4}`},
+		{input: `{"$x":1}`, filter: `{"$x"}`, want: `{"$x":1}`},
+
+		// Comparison follows jq's order: null < false < true < numbers < strings < arrays < objects.
+		{input: `null`, filter: `1 == 1`, want: `true`},
+		{input: `null`, filter: `1 == 1.0`, want: `true`},
+		{input: `null`, filter: `"a" == "a"`, want: `true`},
+		{input: `null`, filter: `[1] == [1]`, want: `true`},
+		{input: `null`, filter: `{"a":1} == {"a":1}`, want: `true`},
+		{input: `null`, filter: `null == false`, want: `false`},
+		{input: `null`, filter: `1 != 2`, want: `true`},
+		{input: `null`, filter: `1 < 2`, want: `true`},
+		{input: `null`, filter: `"a" < "b"`, want: `true`},
+		{input: `null`, filter: `null < false`, want: `true`},
+		{input: `null`, filter: `false < true`, want: `true`},
+		{input: `null`, filter: `true < 0`, want: `true`},
+		{input: `null`, filter: `0 < "a"`, want: `true`},
+		{input: `null`, filter: `"a" < []`, want: `true`},
+		{input: `null`, filter: `[] < {}`, want: `true`},
+		{input: `null`, filter: `[1,2] < [1,3]`, want: `true`},
+		{input: `null`, filter: `[1] < [1,0]`, want: `true`},
+		{input: `null`, filter: `{"a":1} < {"a":2}`, want: `true`},
+		{input: `null`, filter: `{"a":2} < {"b":1}`, want: `true`},
+		{input: `null`, filter: `2 <= 2`, want: `true`},
+		{input: `null`, filter: `3 > 2`, want: `true`},
+		{input: `null`, filter: `2 >= 3`, want: `false`},
+		{input: `null`, filter: `(1,2) == (1,2)`, want: `true false false true`},
+
+		// Boolean operators.
+		{input: `null`, filter: `true and false`, want: `false`},
+		{input: `null`, filter: `true and true`, want: `true`},
+		{input: `null`, filter: `null and true`, want: `false`},
+		{input: `null`, filter: `1 and "x"`, want: `true`},
+		{input: `null`, filter: `false or false`, want: `false`},
+		{input: `null`, filter: `false or 0`, want: `true`},
+		{input: `null`, filter: `null or null`, want: `false`},
+		{input: `null`, filter: `(true, false) and true`, want: `true false`},
+		{input: `null`, filter: `true and (true, false)`, want: `true false`},
+
+		// Alternatives.
+		{input: `null`, filter: `null // 1`, want: `1`},
+		{input: `null`, filter: `false // 1`, want: `1`},
+		{input: `null`, filter: `0 // 1`, want: `0`},
+		{input: `null`, filter: `(null, 2, false, 3) // 4`, want: `2 3`},
+		{input: `null`, filter: `empty // 5`, want: `5`},
+		{input: `null`, filter: `(null, false) // (6, 7)`, want: `6 7`},
+		{input: `{"a":null}`, filter: `.a // "d"`, want: `"d"`},
+
+		// Functions.
+		{input: `null`, filter: `1 | not`, want: `false`},
+		{input: `null`, filter: `null | not`, want: `true`},
+		{input: `null`, filter: `false | not`, want: `true`},
+		{input: `null`, filter: `[] | not`, want: `false`},
+		{input: `null`, filter: `null | length`, want: `0`},
+		{input: `null`, filter: `"héllo" | length`, want: `5`},
+		{input: `null`, filter: `[1,2] | length`, want: `2`},
+		{input: `null`, filter: `{"a":1} | length`, want: `1`},
+		{input: `null`, filter: `-5 | length`, want: `5`},
+		{input: `null`, filter: `2.5 | length`, want: `2.5`},
+		{input: `null`, filter: `{"b":1,"a":2} | keys`, want: `["a","b"]`},
+		{input: `null`, filter: `[4,5] | keys`, want: `[0,1]`},
+		{input: `null`, filter: `null | type`, want: `"null"`},
+		{input: `null`, filter: `true | type`, want: `"boolean"`},
+		{input: `null`, filter: `1 | type`, want: `"number"`},
+		{input: `null`, filter: `"s" | type`, want: `"string"`},
+		{input: `null`, filter: `[] | type`, want: `"array"`},
+		{input: `null`, filter: `{} | type`, want: `"object"`},
+		{input: `null`, filter: `1 | tostring`, want: `"1"`},
+		{input: `null`, filter: `"s" | tostring`, want: `"s"`},
+		{input: `null`, filter: `[1,"a"] | tostring`, want: `"[1,\"a\"]"`},
+		{input: `null`, filter: `{"a":null} | tostring`, want: `"{\"a\":null}"`},
+		{input: `null`, filter: `null | tostring`, want: `"null"`},
+		{input: `null`, filter: `" 12 " | tonumber`, want: `12`},
+		{input: `null`, filter: `"1.5e2" | tonumber`, want: `1.5e2`},
+		{input: `null`, filter: `3 | tonumber`, want: `3`},
+		{input: `null`, filter: `[1,2,3] | first`, want: `1`},
+		{input: `null`, filter: `[1,2,3] | last`, want: `3`},
+		{input: `null`, filter: `[] | first`, want: `null`},
+		{input: `null`, filter: `[] | last`, want: `null`},
+		{input: `null`, filter: `[3,1,2] | sort`, want: `[1,2,3]`},
+		{input: `null`, filter: `["b","a",null,true,false,1,[0],{}] | sort`, want: `[null,false,true,1,"a","b",[0],{}]`},
+		{input: `null`, filter: `[{"a":2},{"a":1}] | sort`, want: `[{"a":1},{"a":2}]`},
+		{input: `null`, filter: `[1,2,3] | map(select(. > 1))`, want: `[2,3]`},
+		{input: `null`, filter: `[1,2,3] | map(., .)`, want: `[1,1,2,2,3,3]`},
+		{input: `null`, filter: `{"a":1,"b":2} | map(. )`, want: `[1,2]`},
+		{input: `null`, filter: `[] | map(.x)`, want: `[]`},
+		{input: `null`, filter: `[1,2,3] | .[] | select(. != 2)`, want: `1 3`},
+		{input: `null`, filter: `1 | select(true, true)`, want: `1 1`},
+		{input: `null`, filter: `1 | select(empty)`, want: ``},
+		{input: `null`, filter: `1 | select(null)`, want: ``},
+		{input: `null`, filter: `1 | select(0)`, want: `1`},
+		{input: `null`, filter: `{"a":1} | has("a"), has("b")`, want: `true false`},
+		{input: `null`, filter: `[1,2] | has(0), has(2), has(-1)`, want: `true false false`},
+		{input: `null`, filter: `{"a":1} | has("a", "b")`, want: `true false`},
+		{input: `null`, filter: `["a",1,null,true] | join("-")`, want: `"a-1--true"`},
+		{input: `null`, filter: `[] | join(",")`, want: `""`},
+		{input: `null`, filter: `["x"] | join(", ")`, want: `"x"`},
+		{input: `null`, filter: `["a","b"] | join(",", ";")`, want: `"a,b" "a;b"`},
+		{input: `null`, filter: `[1,2] | [.[] | empty]`, want: `[]`},
+		{input: `null`, filter: `empty`, want: ``},
+		{input: `null`, filter: `[empty]`, want: `[]`},
+		{input: `null`, filter: `[.[]?]`, want: `[]`},
+		{input: `null`, filter: `[1,[2]] | .[1][0]`, want: `2`},
+		{input: `{"a":[{"b":1},{"b":2}]}`, filter: `.a[].b`, want: `1 2`},
+		{input: `{"a":[{"b":1},{"b":2}]}`, filter: `[.a[] | .b] | length`, want: `2`},
+		{input: `{"a":[{"b":1},{"b":2}]}`, filter: `.a | map(.b) | sort | last`, want: `2`},
+		{input: `null`, filter: `(1, 2) | tostring`, want: `"1" "2"`},
+		{input: `null`, filter: `"a" | (. , .) | length`, want: `1 1`},
+		{input: `null`, filter: `[.[]?] | length`, want: `0`},
+	}
+	for _, tt := range tests {
+		if got := run(t, tt.filter, tt.input); got != tt.want {
+			t.Errorf("Run(%q) on %s = %s, want %s", tt.filter, tt.input, got, tt.want)
+		}
+	}
+}
+
+// run applies filter to the JSON input and returns its outputs as compact
+// JSON separated by spaces.
+func run(t *testing.T, filter, input string) string {
+	t.Helper()
+	q, err := Compile(filter)
+	if err != nil {
+		t.Fatalf("Compile(%q) error = %v", filter, err)
+	}
+	results, err := q.RunJSON([]byte(input))
+	if err != nil {
+		t.Fatalf("Run(%q) error = %v", filter, err)
+	}
+	var got []string
+	for _, r := range results {
+		data, err := json.Marshal(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(data))
+	}
+	return strings.Join(got, " ")
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   string
+	}{
+		{filter: ".issues[", want: "position 9"},
+		{filter: `.a | nope`, want: "unknown function nope"},
+		{filter: `select`, want: "select takes 1 argument(s)"},
+		{filter: `"abc`, want: "unterminated string"},
+		{filter: `.a @`, want: `unexpected character '@'`},
+		{filter: `.a )`, want: `unexpected ")"`},
+		{filter: `{1: 2}`, want: "in object"},
+	}
+	for _, tt := range tests {
+		_, err := Compile(tt.filter)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Compile(%q) error = %v, want it to contain %q", tt.filter, err, tt.want)
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   string
+	}{
+		{filter: ".total.x", want: `cannot index number with string "x"`},
+		{filter: ".total[]", want: "cannot iterate over number"},
+		{filter: ".issues | join(1)", want: "join: separator must be a string"},
+		{filter: ".issues | tonumber", want: "tonumber: cannot parse array"},
+		{filter: `"a" | keys`, want: "keys: string has no keys"},
+		{filter: ".total | sort", want: "sort: cannot sort number"},
+		{filter: ".issues[0] | has(0)", want: "has: cannot check whether object has number 0"},
+		{filter: "true | length", want: "length: boolean has no length"},
+		{filter: `"x" | tonumber`, want: `tonumber: cannot parse string "x" as a number`},
+		{filter: `[.issues] | join(",")`, want: "join: cannot join array"},
+		{filter: ".issues[0] | .[0]", want: "cannot index object with number 0"},
+		{filter: ".total[1:2]", want: "cannot slice number"},
+		{filter: ".total | map(.)", want: "map: cannot iterate over number"},
+	}
+	for _, tt := range tests {
+		q, err := Compile(tt.filter)
+		if err != nil {
+			t.Fatalf("Compile(%q) error = %v", tt.filter, err)
+		}
+		if _, err := q.RunJSON([]byte(input)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Run(%q) error = %v, want it to contain %q", tt.filter, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,438 @@
+package jq
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokIdent
+	tokString
+	tokNumber
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of filter"
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// punctuation is ordered so that longer operators match first.
+var punctuation = []string{"==", "!=", "<=", ">=", "//", "|", ",", ".", "[", "]", "(", ")", "{", "}", ":", ";", "?", "<", ">"}
+
+type parser struct {
+	src    string
+	tokens []token
+	i      int
+}
+
+func (p *parser) errorf(tok token, format string, args ...any) error {
+	return fmt.Errorf("invalid filter at position %d: %s", tok.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) lex() error {
+	src := p.src
+	for i := 0; i < len(src); {
+		r := rune(src[i])
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"':
+			s, n, err := lexString(src[i:])
+			if err != nil {
+				return p.errorf(token{pos: i}, "%v", err)
+			}
+			p.tokens = append(p.tokens, token{kind: tokString, text: s, pos: i})
+			i += n
+		case r >= '0' && r <= '9' || r == '-' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9':
+			j := i + 1
+			for j < len(src) && (src[j] >= '0' && src[j] <= '9' || src[j] == '.' || src[j] == 'e' || src[j] == 'E') {
+				j++
+			}
+			if _, err := strconv.ParseFloat(src[i:j], 64); err != nil {
+				return p.errorf(token{pos: i}, "invalid number %q", src[i:j])
+			}
+			p.tokens = append(p.tokens, token{kind: tokNumber, text: src[i:j], pos: i})
+			i = j
+		case r == '_' || unicode.IsLetter(r):
+			j := i
+			for j < len(src) && (src[j] == '_' || unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j]))) {
+				j++
+			}
+			p.tokens = append(p.tokens, token{kind: tokIdent, text: src[i:j], pos: i})
+			i = j
+		default:
+			matched := false
+			for _, punct := range punctuation {
+				if strings.HasPrefix(src[i:], punct) {
+					p.tokens = append(p.tokens, token{kind: tokPunct, text: punct, pos: i})
+					i += len(punct)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return p.errorf(token{pos: i}, "unexpected character %q", r)
+			}
+		}
+	}
+	p.tokens = append(p.tokens, token{kind: tokEOF, pos: len(src)})
+	return nil
+}
+
+// lexString reads the JSON string at the start of s and returns it and its
+// length in s.
+func lexString(s string) (string, int, error) {
+	for j := 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '"':
+			var str string
+			if err := json.Unmarshal([]byte(s[:j+1]), &str); err != nil {
+				return "", 0, fmt.Errorf("invalid string %s", s[:j+1])
+			}
+			return str, j + 1, nil
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.i]
+	if tok.kind != tokEOF {
+		p.i++
+	}
+	return tok
+}
+
+// accept consumes the next token if it is the punctuation or keyword text.
+func (p *parser) accept(text string) bool {
+	tok := p.peek()
+	if (tok.kind == tokPunct || tok.kind == tokIdent) && tok.text == text {
+		p.i++
+		return true
+	}
+	return false
+}
+
+// at reports whether the next token is the punctuation text.
+func (p *parser) at(text string) bool {
+	tok := p.peek()
+	return tok.kind == tokPunct && tok.text == text
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		tok := p.peek()
+		return p.errorf(tok, "expected %q, got %s", text, tok)
+	}
+	return nil
+}
+
+// parsePipe parses a | b.
+func (p *parser) parsePipe() (node, error) {
+	left, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("|") {
+		right, err := p.parseComma()
+		if err != nil {
+			return nil, err
+		}
+		left = pipeNode{left, right}
+	}
+	return left, nil
+}
+
+// parseComma parses a, b.
+func (p *parser) parseComma() (node, error) {
+	first, err := p.parseAlternative()
+	if err != nil {
+		return nil, err
+	}
+	items := []node{first}
+	for p.accept(",") {
+		item, err := p.parseAlternative()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if len(items) == 1 {
+		return first, nil
+	}
+	return commaNode(items), nil
+}
+
+// parseAlternative parses a // b.
+func (p *parser) parseAlternative() (node, error) {
+	left, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("//") {
+		right, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		left = alternativeNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicNode{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("and") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = logicNode{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.accept(op) {
+			right, err := p.parsePostfix()
+			if err != nil {
+				return nil, err
+			}
+			return compareNode{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+// parsePostfix parses a term followed by paths: .a, ."a", [i], [], [i:j]
+// and ?.
+func (p *parser) parsePostfix() (node, error) {
+	n, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.at(".") && p.isFieldAfterDot():
+			p.next()
+			n = indexNode{target: n, index: literalNode{p.next().text}}
+		case p.accept("["):
+			if n, err = p.parseBracket(n); err != nil {
+				return nil, err
+			}
+		case p.accept("?"):
+			n = tryNode{n}
+		default:
+			return n, nil
+		}
+	}
+}
+
+// isFieldAfterDot reports whether the "." at the current position starts
+// a field name.
+func (p *parser) isFieldAfterDot() bool {
+	next := p.tokens[p.i+1]
+	return next.kind == tokIdent || next.kind == tokString
+}
+
+// parseBracket parses the rest of [], [i] or [i:j] applied to target.
+func (p *parser) parseBracket(target node) (node, error) {
+	if p.accept("]") {
+		return iterateNode{target}, nil
+	}
+	var from, to node
+	var err error
+	if !p.at(":") {
+		if from, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept(":") {
+		if !p.at("]") {
+			if to, err = p.parsePipe(); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return sliceNode{target: target, from: from, to: to}, nil
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	return indexNode{target: target, index: from}, nil
+}
+
+func (p *parser) parseTerm() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokString:
+		return literalNode{tok.text}, nil
+	case tokNumber:
+		return literalNode{json.Number(tok.text)}, nil
+	case tokIdent:
+		switch tok.text {
+		case "true":
+			return literalNode{true}, nil
+		case "false":
+			return literalNode{false}, nil
+		case "null":
+			return literalNode{nil}, nil
+		}
+		return p.parseCall(tok)
+	case tokPunct:
+		switch tok.text {
+		case ".":
+			if p.isFieldAfterPrevDot() {
+				return indexNode{target: identityNode{}, index: literalNode{p.next().text}}, nil
+			}
+			return identityNode{}, nil
+		case "(":
+			n, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		case "[":
+			if p.accept("]") {
+				return collectNode{}, nil
+			}
+			n, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			return collectNode{n}, p.expect("]")
+		case "{":
+			return p.parseObject()
+		}
+	}
+	return nil, p.errorf(tok, "unexpected %s", tok)
+}
+
+// isFieldAfterPrevDot reports whether the "." just consumed is followed by
+// a field name.
+func (p *parser) isFieldAfterPrevDot() bool {
+	next := p.peek()
+	return next.kind == tokIdent || next.kind == tokString
+}
+
+// parseObject parses the entries of {a, b: .c, "d": 1, (.e): .f}.
+func (p *parser) parseObject() (node, error) {
+	var obj objectNode
+	if p.accept("}") {
+		return obj, nil
+	}
+	for {
+		var entry objectEntry
+		tok := p.next()
+		switch {
+		case tok.kind == tokIdent || tok.kind == tokString:
+			entry.key = literalNode{tok.text}
+			entry.value = indexNode{target: identityNode{}, index: literalNode{tok.text}}
+		case tok.kind == tokPunct && tok.text == "(":
+			key, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			entry.key = key
+			if !p.at(":") {
+				return nil, p.errorf(p.peek(), "expected \":\" after computed key")
+			}
+		default:
+			return nil, p.errorf(tok, "unexpected %s in object", tok)
+		}
+		if p.accept(":") {
+			value, err := p.parseAlternative()
+			if err != nil {
+				return nil, err
+			}
+			entry.value = value
+		}
+		obj = append(obj, entry)
+
+		if p.accept("}") {
+			return obj, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseCall parses a function call with optional ;-separated arguments.
+func (p *parser) parseCall(name token) (node, error) {
+	var args []node
+	if p.accept("(") {
+		for {
+			arg, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.accept(")") {
+				break
+			}
+			if err := p.expect(";"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	f, ok := functions[name.text]
+	if !ok {
+		return nil, p.errorf(name, "unknown function %s", name.text)
+	}
+	if len(args) != f.arity {
+		return nil, p.errorf(name, "%s takes %d argument(s)", name.text, f.arity)
+	}
+	return callNode{name: name.text, fn: f.fn, args: args}, nil
+}
@@ -0,0 +1,103 @@
+// Package lockedfile writes files atomically under an advisory lock so that
+// concurrent jcli processes never observe or produce a partially written file.
+package lockedfile
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Lock acquires an exclusive advisory lock associated with path. The lock is
+// held on a sibling "<path>.lock" file so that it survives the rename done by
+// Write. The returned function releases the lock.
+func Lock(path string) (unlock func(), err error) {
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+
+	return func() {
+		_ = unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// Write replaces the contents of path with data. It writes to a temporary
+// file in the same directory and renames it over path while holding the lock,
+// so readers see either the old or the new contents.
+func Write(path string, data []byte, perm os.FileMode) error {
+	unlock, err := Lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return WriteAtomic(path, data, perm)
+}
+
+// Update reads path and replaces its contents with what fn returns, all
+// under the lock, so no other writer can slip in between. A missing file
+// reads as nil. When fn returns data unchanged nothing is written. Update
+// returns the final contents.
+func Update(path string, perm os.FileMode, fn func(data []byte) ([]byte, error)) ([]byte, error) {
+	unlock, err := Lock(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	updated, err := fn(data)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(updated, data) {
+		return data, nil
+	}
+	if err := WriteAtomic(path, updated, perm); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// WriteAtomic is Write for callers that already hold the lock of path, or
+// of the file path belongs to.
+func WriteAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	if err := os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+	return nil
+}
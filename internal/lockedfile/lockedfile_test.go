@@ -0,0 +1,174 @@
+package lockedfile
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.json")
+
+	if err := Write(path, []byte("first"), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := Write(path, []byte("second"), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "second" {
+		t.Errorf("expected %q, got %q", "second", data)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	for _, entry := range entries {
+		if entry.Name() != "file.json" && entry.Name() != "file.json.lock" {
+			t.Errorf("unexpected leftover file %s", entry.Name())
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.json")
+
+	got, err := Update(path, 0600, func(data []byte) ([]byte, error) {
+		if data != nil {
+			t.Errorf("expected nil data for a missing file, got %q", data)
+		}
+		return []byte("1"), nil
+	})
+	if err != nil || string(got) != "1" {
+		t.Fatalf("Update() = %q, %v", got, err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := Update(path, 0600, func(data []byte) ([]byte, error) {
+				n, err := strconv.Atoi(string(data))
+				return []byte(strconv.Itoa(n + 1)), err
+			})
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "21" {
+		t.Errorf("expected every increment to survive, got %q", data)
+	}
+
+	boom := errors.New("boom")
+	if _, err := Update(path, 0600, func([]byte) ([]byte, error) { return nil, boom }); !errors.Is(err, boom) {
+		t.Errorf("expected fn error, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "21" {
+		t.Errorf("failed update changed the file: %q", data)
+	}
+}
+
+func TestWriteIsAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	small := []byte("x")
+	large := bytes.Repeat([]byte("y"), 1<<20)
+
+	if err := Write(path, small, 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			data := small
+			if i%2 == 0 {
+				data = large
+			}
+			for j := 0; j < 20; j++ {
+				if err := Write(path, data, 0600); err != nil {
+					t.Errorf("write failed: %v", err)
+					return
+				}
+			}
+		}(i)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		if !bytes.Equal(data, small) && !bytes.Equal(data, large) {
+			t.Fatalf("observed partial write of %d bytes", len(data))
+		}
+	}
+}
+
+func TestLockIsExclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter")
+	if err := os.WriteFile(path, []byte("0"), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	const workers, increments = 8, 25
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < increments; j++ {
+				unlock, err := Lock(path)
+				if err != nil {
+					t.Errorf("lock failed: %v", err)
+					return
+				}
+				data, _ := os.ReadFile(path)
+				n, _ := strconv.Atoi(string(data))
+				err = WriteAtomic(path, []byte(strconv.Itoa(n+1)), 0600)
+				unlock()
+				if err != nil {
+					t.Errorf("write failed: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, _ := os.ReadFile(path)
+	if string(data) != strconv.Itoa(workers*increments) {
+		t.Errorf("expected counter %d, got %s", workers*increments, data)
+	}
+}
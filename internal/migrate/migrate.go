@@ -0,0 +1,109 @@
+// Package migrate upgrades versioned config and state documents one version
+// at a time. Steps work on the document type of the owning package: state
+// uses generic maps, config a YAML node tree that keeps comments and key
+// order.
+package migrate
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/tutunak/jcli/internal/lockedfile"
+)
+
+// VersionKey is the top-level field holding the schema version. Documents
+// without it are version 0.
+const VersionKey = "version"
+
+// Step upgrades a document of type D from version From to From+1.
+type Step[D any] struct {
+	From        int
+	Description string
+	Apply       func(doc D) error
+}
+
+// Latest returns the version produced by running all steps.
+func Latest[D any](steps []Step[D]) int {
+	return len(steps)
+}
+
+// Version returns the schema version of doc, which must be a non-negative
+// integer.
+func Version(doc map[string]any) (int, error) {
+	return ParseVersion(doc[VersionKey])
+}
+
+// ParseVersion converts a decoded version field, nil when it is missing.
+func ParseVersion(value any) (int, error) {
+	var version int
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case int:
+		version = v
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("invalid %s field: %v is not an integer", VersionKey, v)
+		}
+		version = int(v)
+	default:
+		return 0, fmt.Errorf("invalid %s field: %v", VersionKey, v)
+	}
+	if version < 0 {
+		return 0, fmt.Errorf("invalid %s field: %d is negative", VersionKey, version)
+	}
+	return version, nil
+}
+
+// Apply upgrades doc, which is at version from, to the latest version and
+// calls setVersion after each step. Steps must be ordered and start at
+// version 0.
+func Apply[D any](doc D, from int, steps []Step[D], setVersion func(doc D, version int)) error {
+	latest := Latest(steps)
+	if from > latest {
+		return fmt.Errorf("schema version %d is newer than supported version %d; upgrade jcli", from, latest)
+	}
+
+	for v := from; v < latest; v++ {
+		step := steps[v]
+		if step.From != v {
+			return fmt.Errorf("migration steps out of order: expected step from version %d, got %d", v, step.From)
+		}
+		if err := step.Apply(doc); err != nil {
+			return fmt.Errorf("migration from version %d (%s) failed: %w", v, step.Description, err)
+		}
+		setVersion(doc, v+1)
+	}
+	return nil
+}
+
+// Run upgrades the map doc in place to the latest version and returns the
+// version it started from.
+func Run(doc map[string]any, steps []Step[map[string]any]) (int, error) {
+	from, err := Version(doc)
+	if err != nil {
+		return 0, err
+	}
+	err = Apply(doc, from, steps, func(doc map[string]any, version int) {
+		doc[VersionKey] = version
+	})
+	return from, err
+}
+
+// Backup copies the original file contents next to path before an upgrade
+// rewrites it, and returns the backup path. Callers must hold the lock of
+// path.
+func Backup(path string, data []byte, version int) (string, error) {
+	backup := fmt.Sprintf("%s.v%d.bak", path, version)
+	if _, err := os.Stat(backup); err == nil {
+		backup = fmt.Sprintf("%s.v%d.%s.bak", path, version, time.Now().Format("20060102150405"))
+	}
+
+	// The caller holds the lock of path, which covers the backup as well.
+	if err := lockedfile.WriteAtomic(backup, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+	return backup, nil
+}
@@ -0,0 +1,135 @@
+package migrate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testSteps = []Step[map[string]any]{
+	{From: 0, Description: "add a", Apply: func(doc map[string]any) error {
+		doc["a"] = true
+		return nil
+	}},
+	{From: 1, Description: "rename a to b", Apply: func(doc map[string]any) error {
+		doc["b"] = doc["a"]
+		delete(doc, "a")
+		return nil
+	}},
+}
+
+func TestVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     map[string]any
+		want    int
+		wantErr bool
+	}{
+		{"missing", map[string]any{}, 0, false},
+		{"yaml int", map[string]any{"version": 2}, 2, false},
+		{"json number", map[string]any{"version": float64(3)}, 3, false},
+		{"invalid", map[string]any{"version": "two"}, 0, true},
+		{"negative", map[string]any{"version": -1}, 0, true},
+		{"negative json number", map[string]any{"version": float64(-2)}, 0, true},
+		{"fraction", map[string]any{"version": 1.5}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Version(tt.doc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Version() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Version() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Run("upgrades from version 0", func(t *testing.T) {
+		doc := map[string]any{}
+		from, err := Run(doc, testSteps)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if from != 0 {
+			t.Errorf("expected from version 0, got %d", from)
+		}
+		if doc["b"] != true || doc["a"] != nil {
+			t.Errorf("expected all steps applied, got %v", doc)
+		}
+		if doc["version"] != 2 {
+			t.Errorf("expected version 2, got %v", doc["version"])
+		}
+	})
+
+	t.Run("skips applied steps", func(t *testing.T) {
+		doc := map[string]any{"version": 1, "a": "kept"}
+		if _, err := Run(doc, testSteps); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if doc["b"] != "kept" {
+			t.Errorf("expected only the second step to run, got %v", doc)
+		}
+	})
+
+	t.Run("current version is untouched", func(t *testing.T) {
+		doc := map[string]any{"version": 2}
+		from, err := Run(doc, testSteps)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if from != 2 || len(doc) != 1 {
+			t.Errorf("expected no changes, got from=%d doc=%v", from, doc)
+		}
+	})
+
+	t.Run("rejects newer versions", func(t *testing.T) {
+		if _, err := Run(map[string]any{"version": 3}, testSteps); err == nil {
+			t.Error("expected error for newer version")
+		}
+	})
+
+	t.Run("reports failing step", func(t *testing.T) {
+		boom := errors.New("boom")
+		steps := []Step[map[string]any]{{From: 0, Description: "fail", Apply: func(map[string]any) error { return boom }}}
+		if _, err := Run(map[string]any{}, steps); !errors.Is(err, boom) {
+			t.Errorf("expected step error, got %v", err)
+		}
+	})
+
+	t.Run("rejects out of order steps", func(t *testing.T) {
+		steps := []Step[map[string]any]{testSteps[1]}
+		if _, err := Run(map[string]any{}, steps); err == nil {
+			t.Error("expected error for out of order steps")
+		}
+	})
+}
+
+func TestBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	first, err := Backup(path, []byte("v0"), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first != path+".v0.bak" {
+		t.Errorf("unexpected backup path %q", first)
+	}
+
+	second, err := Backup(path, []byte("v0 again"), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second == first {
+		t.Error("expected existing backup not to be overwritten")
+	}
+
+	data, _ := os.ReadFile(first)
+	if string(data) != "v0" {
+		t.Errorf("expected original backup contents, got %q", data)
+	}
+}
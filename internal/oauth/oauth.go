@@ -0,0 +1,340 @@
+// Package oauth implements the Atlassian OAuth 2.0 (3LO) authorization code
+// flow with PKCE. The authorization code is received by a loopback HTTP
+// server, and the resulting tokens are scoped to one Jira Cloud site, which
+// is addressed through its cloud ID.
+package oauth
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultAuthURL      = "https://auth.atlassian.com"
+	DefaultAPIURL       = "https://api.atlassian.com"
+	DefaultCallbackPort = 8976
+)
+
+// DefaultScopes grant read access to Jira; offline_access is required to
+// receive a refresh token.
+var DefaultScopes = []string{"read:jira-work", "read:jira-user", "offline_access"}
+
+// ErrNoRefreshToken is returned when an expired token cannot be renewed.
+var ErrNoRefreshToken = errors.New("no refresh token; run 'jcli auth login'")
+
+// ErrNotLoggedIn is returned by NewSource when no token is stored for the
+// site.
+var ErrNotLoggedIn = errors.New("not logged in")
+
+// Config describes the OAuth app. AuthURL and APIURL default to Atlassian's
+// endpoints and exist so the flow can run against another server. The
+// callback URL must match the one registered with the app, so jcli always
+// sets CallbackPort, to DefaultCallbackPort unless configured; a CallbackPort
+// of 0 picks a free port and is only useful against other servers, such as
+// in tests. Transport, if set, carries the requests to the token endpoint and
+// the API.
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	AuthURL      string
+	APIURL       string
+	Scopes       []string
+	CallbackPort int
+	Transport    http.RoundTripper
+}
+
+func (c Config) withDefaults() Config {
+	if c.AuthURL == "" {
+		c.AuthURL = DefaultAuthURL
+	}
+	if c.APIURL == "" {
+		c.APIURL = DefaultAPIURL
+	}
+	if len(c.Scopes) == 0 {
+		c.Scopes = DefaultScopes
+	}
+	c.AuthURL = strings.TrimSuffix(c.AuthURL, "/")
+	c.APIURL = strings.TrimSuffix(c.APIURL, "/")
+	return c
+}
+
+// Token is a token pair for one Jira site.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry"`
+	CloudID      string    `json:"cloud_id"`
+	SiteURL      string    `json:"site_url"`
+}
+
+// expired reports whether the access token expires within the next minute.
+func (t *Token) expired() bool {
+	return !t.Expiry.IsZero() && time.Now().Add(time.Minute).After(t.Expiry)
+}
+
+// APIBaseURL returns the base URL for Jira REST calls of the site with
+// cloudID, e.g. https://api.atlassian.com/ex/jira/<cloudID>.
+func APIBaseURL(apiURL, cloudID string) string {
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	return strings.TrimSuffix(apiURL, "/") + "/ex/jira/" + cloudID
+}
+
+// Login runs the authorization code flow for the Jira site at siteURL. open
+// is called with the authorization URL and should show it to the user,
+// typically by launching a browser. Login waits for the callback until ctx
+// is done.
+func Login(ctx context.Context, cfg Config, siteURL string, open func(authURL string) error) (*Token, error) {
+	cfg = cfg.withDefaults()
+	if cfg.ClientID == "" {
+		return nil, fmt.Errorf("OAuth client ID is not configured")
+	}
+
+	verifier, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	state, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", cfg.CallbackPort))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start callback server: %w", err)
+	}
+	redirectURI := fmt.Sprintf("http://127.0.0.1:%d/callback", listener.Addr().(*net.TCPAddr).Port)
+
+	codes := make(chan callbackResult, 1)
+	server := &http.Server{
+		Handler:           callbackHandler(state, codes),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go server.Serve(listener)
+	defer server.Close()
+
+	if err := open(authorizeURL(cfg, redirectURI, state, verifier)); err != nil {
+		return nil, err
+	}
+
+	var result callbackResult
+	select {
+	case result = <-codes:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out waiting for authorization: %w", ctx.Err())
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	token, err := exchange(ctx, cfg, map[string]string{
+		"grant_type":    "authorization_code",
+		"code":          result.code,
+		"redirect_uri":  redirectURI,
+		"code_verifier": verifier,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	site, err := findSite(ctx, cfg, token.AccessToken, siteURL)
+	if err != nil {
+		return nil, err
+	}
+	token.CloudID = site.ID
+	token.SiteURL = site.URL
+	return token, nil
+}
+
+// Refresh exchanges the refresh token of t for a new token pair. Atlassian
+// rotates refresh tokens, so the old one must not be used again.
+func Refresh(ctx context.Context, cfg Config, t *Token) (*Token, error) {
+	if t.RefreshToken == "" {
+		return nil, ErrNoRefreshToken
+	}
+
+	token, err := exchange(ctx, cfg.withDefaults(), map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": t.RefreshToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = t.RefreshToken
+	}
+	token.CloudID = t.CloudID
+	token.SiteURL = t.SiteURL
+	return token, nil
+}
+
+func authorizeURL(cfg Config, redirectURI, state, verifier string) string {
+	challenge := sha256.Sum256([]byte(verifier))
+
+	query := url.Values{}
+	query.Set("audience", "api.atlassian.com")
+	query.Set("client_id", cfg.ClientID)
+	query.Set("scope", strings.Join(cfg.Scopes, " "))
+	query.Set("redirect_uri", redirectURI)
+	query.Set("state", state)
+	query.Set("response_type", "code")
+	query.Set("prompt", "consent")
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	return cfg.AuthURL + "/authorize?" + query.Encode()
+}
+
+type callbackResult struct {
+	code string
+	err  error
+}
+
+func callbackHandler(state string, results chan<- callbackResult) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		var result callbackResult
+		switch {
+		case query.Get("state") != state:
+			result.err = fmt.Errorf("authorization failed: state mismatch")
+		case query.Get("error") != "":
+			result.err = fmt.Errorf("authorization failed: %s %s", query.Get("error"), query.Get("error_description"))
+		case query.Get("code") == "":
+			result.err = fmt.Errorf("authorization failed: no code in callback")
+		default:
+			result.code = query.Get("code")
+		}
+
+		if result.err != nil {
+			http.Error(w, result.err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "jcli is authorized. You can close this window.")
+		}
+
+		select {
+		case results <- result:
+		default:
+		}
+	})
+	return mux
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+func exchange(ctx context.Context, cfg Config, params map[string]string) (*Token, error) {
+	params["client_id"] = cfg.ClientID
+	if cfg.ClientSecret != "" {
+		params["client_secret"] = cfg.ClientSecret
+	}
+
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal token request: %w", err)
+	}
+
+	data, err := doJSON(ctx, cfg.Transport, http.MethodPost, cfg.AuthURL+"/oauth/token", "", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+
+	var resp tokenResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if resp.AccessToken == "" {
+		return nil, fmt.Errorf("token response contains no access token")
+	}
+
+	token := &Token{AccessToken: resp.AccessToken, RefreshToken: resp.RefreshToken}
+	if resp.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+type site struct {
+	ID   string `json:"id"`
+	URL  string `json:"url"`
+	Name string `json:"name"`
+}
+
+// findSite looks up the cloud ID of siteURL among the sites the token grants
+// access to. Without a siteURL the token must grant access to one site.
+func findSite(ctx context.Context, cfg Config, accessToken, siteURL string) (*site, error) {
+	data, err := doJSON(ctx, cfg.Transport, http.MethodGet, cfg.APIURL+"/oauth/token/accessible-resources", accessToken, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accessible sites: %w", err)
+	}
+
+	var sites []site
+	if err := json.Unmarshal(data, &sites); err != nil {
+		return nil, fmt.Errorf("failed to parse accessible sites: %w", err)
+	}
+
+	want := strings.TrimSuffix(siteURL, "/")
+	for i := range sites {
+		if want == "" && len(sites) == 1 || strings.EqualFold(strings.TrimSuffix(sites[i].URL, "/"), want) {
+			return &sites[i], nil
+		}
+	}
+	if want == "" {
+		return nil, fmt.Errorf("token grants access to %d sites; set jira.url to choose one", len(sites))
+	}
+	return nil, fmt.Errorf("authorization does not include %s; grant access to that site when consenting", siteURL)
+}
+
+func doJSON(ctx context.Context, transport http.RoundTripper, method, endpoint, bearer string, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+
+	client := &http.Client{Transport: transport, Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
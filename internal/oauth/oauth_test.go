@@ -0,0 +1,291 @@
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tutunak/jcli/internal/jira"
+)
+
+const testSite = "https://example.atlassian.net"
+
+// fakeAtlassian plays the authorization server, the accessible-resources API
+// and the cloud ID–scoped Jira API.
+type fakeAtlassian struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu        sync.Mutex
+	challenge string
+	access    string // the only access token the Jira API accepts
+	refresh   string
+	issued    int
+}
+
+func newFakeAtlassian(t *testing.T) *fakeAtlassian {
+	f := &fakeAtlassian{t: t}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/authorize", f.authorize)
+	mux.HandleFunc("/oauth/token", f.token)
+	mux.HandleFunc("/oauth/token/accessible-resources", f.resources)
+	mux.HandleFunc("/ex/jira/cloud-1/rest/api/3/issue/", f.issue)
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeAtlassian) config() Config {
+	return Config{ClientID: "client-1", ClientSecret: "secret", AuthURL: f.server.URL, APIURL: f.server.URL}
+}
+
+func (f *fakeAtlassian) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != "client-1" || query.Get("code_challenge_method") != "S256" ||
+		!strings.Contains(query.Get("scope"), "offline_access") {
+		f.t.Errorf("unexpected authorize request: %s", r.URL.RawQuery)
+	}
+
+	f.mu.Lock()
+	f.challenge = query.Get("code_challenge")
+	f.mu.Unlock()
+
+	redirect := query.Get("redirect_uri") + "?code=code-1&state=" + url.QueryEscape(query.Get("state"))
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+func (f *fakeAtlassian) token(w http.ResponseWriter, r *http.Request) {
+	var req map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		f.t.Errorf("failed to decode token request: %v", err)
+	}
+	if req["client_id"] != "client-1" || req["client_secret"] != "secret" {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch req["grant_type"] {
+	case "authorization_code":
+		sum := sha256.Sum256([]byte(req["code_verifier"]))
+		if req["code"] != "code-1" || base64.RawURLEncoding.EncodeToString(sum[:]) != f.challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusForbidden)
+			return
+		}
+	case "refresh_token":
+		if req["refresh_token"] != f.refresh {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusForbidden)
+			return
+		}
+	default:
+		http.Error(w, `{"error":"unsupported_grant_type"}`, http.StatusBadRequest)
+		return
+	}
+
+	f.issued++
+	f.access = "access-" + strconv.Itoa(f.issued)
+	f.refresh = "refresh-" + strconv.Itoa(f.issued)
+	json.NewEncoder(w).Encode(map[string]any{
+		"access_token":  f.access,
+		"refresh_token": f.refresh,
+		"expires_in":    3600,
+	})
+}
+
+func (f *fakeAtlassian) authorized(r *http.Request) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return r.Header.Get("Authorization") == "Bearer "+f.access
+}
+
+func (f *fakeAtlassian) resources(w http.ResponseWriter, r *http.Request) {
+	if !f.authorized(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	json.NewEncoder(w).Encode([]map[string]string{
+		{"id": "cloud-0", "url": "https://other.atlassian.net", "name": "other"},
+		{"id": "cloud-1", "url": testSite, "name": "example"},
+	})
+}
+
+func (f *fakeAtlassian) issue(w http.ResponseWriter, r *http.Request) {
+	if !f.authorized(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/ex/jira/cloud-1/rest/api/3/issue/")
+	json.NewEncoder(w).Encode(map[string]any{"key": key, "fields": map[string]string{"summary": "Summary of " + key}})
+}
+
+// revoke invalidates the current access token, as if it expired early.
+func (f *fakeAtlassian) revoke() {
+	f.mu.Lock()
+	f.access = "revoked"
+	f.mu.Unlock()
+}
+
+// browser follows the authorization URL, including the redirect back to
+// the loopback callback server.
+func browser(authURL string) error {
+	resp, err := http.Get(authURL)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func TestLogin_EndToEnd(t *testing.T) {
+	fake := newFakeAtlassian(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	token, err := Login(ctx, fake.config(), testSite+"/", browser)
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if token.AccessToken != "access-1" || token.RefreshToken != "refresh-1" || token.CloudID != "cloud-1" {
+		t.Fatalf("unexpected token: %+v", token)
+	}
+	if token.Expiry.Before(time.Now().Add(50 * time.Minute)) {
+		t.Errorf("unexpected expiry %v", token.Expiry)
+	}
+
+	path := filepath.Join(t.TempDir(), "oauth_tokens.json")
+	store, err := OpenStore(path)
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	store.Set(testSite, token)
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	source, err := NewSource(fake.config(), path, testSite)
+	if err != nil {
+		t.Fatalf("NewSource() error = %v", err)
+	}
+	if want := fake.server.URL + "/ex/jira/cloud-1"; source.BaseURL() != want {
+		t.Errorf("BaseURL() = %q, want %q", source.BaseURL(), want)
+	}
+	client := jira.NewOAuthClient(source.BaseURL(), source)
+
+	issue, err := client.GetIssue("PROJ-1")
+	if err != nil || issue.Key != "PROJ-1" {
+		t.Fatalf("GetIssue() = %+v, %v", issue, err)
+	}
+
+	// A rejected access token is refreshed once and the request retried.
+	fake.revoke()
+	issue, err = client.GetIssue("PROJ-2")
+	if err != nil || issue.Key != "PROJ-2" {
+		t.Fatalf("GetIssue() after revoke = %+v, %v", issue, err)
+	}
+
+	store, err = OpenStore(path)
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	stored, ok := store.Get(testSite)
+	if !ok || stored.AccessToken != "access-2" || stored.RefreshToken != "refresh-2" || stored.CloudID != "cloud-1" {
+		t.Errorf("refreshed token not persisted: %+v", stored)
+	}
+}
+
+func TestLogin_Errors(t *testing.T) {
+	fake := newFakeAtlassian(t)
+
+	tests := []struct {
+		name    string
+		site    string
+		open    func(authURL string) error
+		wantErr string
+	}{
+		{
+			name: "state mismatch",
+			site: testSite,
+			open: func(authURL string) error {
+				u, _ := url.Parse(authURL)
+				return browser(u.Query().Get("redirect_uri") + "?code=code-1&state=forged")
+			},
+			wantErr: "state mismatch",
+		},
+		{
+			name: "access denied",
+			site: testSite,
+			open: func(authURL string) error {
+				u, _ := url.Parse(authURL)
+				query := url.Values{"state": {u.Query().Get("state")}, "error": {"access_denied"}}
+				return browser(u.Query().Get("redirect_uri") + "?" + query.Encode())
+			},
+			wantErr: "access_denied",
+		},
+		{
+			name:    "site not granted",
+			site:    "https://missing.atlassian.net",
+			open:    browser,
+			wantErr: "does not include",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			_, err := Login(ctx, fake.config(), tt.site, tt.open)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Login() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSource_RefreshesExpiredToken(t *testing.T) {
+	fake := newFakeAtlassian(t)
+	fake.refresh = "refresh-0"
+
+	path := filepath.Join(t.TempDir(), "oauth_tokens.json")
+	store, _ := OpenStore(path)
+	store.Set(testSite, &Token{
+		AccessToken:  "stale",
+		RefreshToken: "refresh-0",
+		Expiry:       time.Now().Add(-time.Hour),
+		CloudID:      "cloud-1",
+	})
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	source, err := NewSource(fake.config(), path, testSite)
+	if err != nil {
+		t.Fatalf("NewSource() error = %v", err)
+	}
+	access, err := source.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if access != "access-1" {
+		t.Errorf("Token() = %q, want refreshed access-1", access)
+	}
+}
+
+func TestNewSource_NotLoggedIn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "oauth_tokens.json")
+	if _, err := NewSource(Config{}, path, testSite); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("expected ErrNotLoggedIn without stored token, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/tutunak/jcli/internal/lockedfile"
+)
+
+// Store is a file of OAuth tokens keyed by Jira site URL.
+type Store struct {
+	path   string
+	tokens map[string]*Token
+}
+
+// OpenStore reads the store at path. A missing file yields an empty store
+// that is created on Save.
+func OpenStore(path string) (*Store, error) {
+	s := &Store{path: path, tokens: make(map[string]*Token)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OAuth token file: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse OAuth token file: %w", err)
+	}
+	return s, nil
+}
+
+func (s *Store) Get(siteURL string) (*Token, bool) {
+	token, ok := s.tokens[siteKey(siteURL)]
+	return token, ok
+}
+
+func (s *Store) Set(siteURL string, token *Token) {
+	s.tokens[siteKey(siteURL)] = token
+}
+
+func (s *Store) Delete(siteURL string) {
+	delete(s.tokens, siteKey(siteURL))
+}
+
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(s.tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal OAuth tokens: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create OAuth token directory: %w", err)
+	}
+	if err := lockedfile.Write(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write OAuth token file: %w", err)
+	}
+	return nil
+}
+
+func siteKey(siteURL string) string {
+	return strings.ToLower(strings.TrimSuffix(siteURL, "/"))
+}
+
+// Source hands out the access token of one site, refreshing it when it has
+// expired or was rejected and persisting the new pair to the store file.
+type Source struct {
+	cfg  Config
+	path string
+	site string
+
+	mu    sync.Mutex
+	token *Token
+}
+
+// NewSource loads the token of siteURL from the store at path.
+func NewSource(cfg Config, path, siteURL string) (*Source, error) {
+	store, err := OpenStore(path)
+	if err != nil {
+		return nil, err
+	}
+	token, ok := store.Get(siteURL)
+	if !ok {
+		return nil, fmt.Errorf("%w to %s; run 'jcli auth login'", ErrNotLoggedIn, siteURL)
+	}
+	return &Source{cfg: cfg, path: path, site: siteURL, token: token}, nil
+}
+
+// BaseURL is the cloud ID–scoped base URL for Jira REST calls.
+func (s *Source) BaseURL() string {
+	return APIBaseURL(s.cfg.APIURL, s.token.CloudID)
+}
+
+// Token returns a valid access token, refreshing an expired one first.
+func (s *Source) Token() (string, error) {
+	s.mu.Lock()
+	expired := s.token.expired()
+	access := s.token.AccessToken
+	s.mu.Unlock()
+
+	if expired {
+		return s.Refresh()
+	}
+	return access, nil
+}
+
+// Refresh renews the token pair. The refresh runs under a file lock, and if
+// another jcli process already rotated the tokens, their result is reused
+// instead of spending the now invalid refresh token.
+func (s *Source) Refresh() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := lockedfile.Lock(s.path + ".refresh")
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	store, err := OpenStore(s.path)
+	if err != nil {
+		return "", err
+	}
+	if stored, ok := store.Get(s.site); ok && stored.AccessToken != s.token.AccessToken && !stored.expired() {
+		s.token = stored
+		return stored.AccessToken, nil
+	}
+
+	token, err := Refresh(context.Background(), s.cfg, s.token)
+	if err != nil {
+		return "", fmt.Errorf("failed to refresh OAuth token: %w", err)
+	}
+	store.Set(s.site, token)
+	if err := store.Save(); err != nil {
+		return "", err
+	}
+
+	s.token = token
+	return token.AccessToken, nil
+}
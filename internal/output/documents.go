@@ -0,0 +1,229 @@
+package output
+
+import (
+	"strconv"
+	"time"
+)
+
+// The documents below are the versioned schema of --output json and yaml;
+// the JSON field names are part of it.
+
+// Issue is a Jira issue selected in jcli (kind "issue"). Branch is the git
+// branch the issue was detected from, if any. A nil *Issue is printed as
+// null data and no rows.
+//
+// Description is set by "issue select <key>", as plain text for Cloud and
+// as wiki markup for Server. The fields from Type on are set by "issue
+// list"; Fields holds the requested custom fields as text.
+type Issue struct {
+	Key         string     `json:"key"`
+	Summary     string     `json:"summary"`
+	Branch      string     `json:"branch,omitempty"`
+	SelectedAt  *time.Time `json:"selected_at,omitempty"`
+	Description string     `json:"description,omitempty"`
+
+	Type     string            `json:"type,omitempty"`
+	Status   string            `json:"status,omitempty"`
+	Priority string            `json:"priority,omitempty"`
+	Assignee string            `json:"assignee,omitempty"`
+	Updated  *time.Time        `json:"updated,omitempty"`
+	URL      string            `json:"url,omitempty"`
+	Fields   map[string]string `json:"fields,omitempty"`
+}
+
+func (i *Issue) Kind() string { return "issue" }
+
+func (i *Issue) Header() []string {
+	return []string{"KEY", "SUMMARY", "BRANCH", "SELECTED"}
+}
+
+func (i *Issue) Rows() [][]string {
+	if i == nil {
+		return nil
+	}
+	return [][]string{i.row()}
+}
+
+func (i *Issue) row() []string {
+	return []string{i.Key, i.Summary, i.Branch, formatTime(i.SelectedAt)}
+}
+
+// IssueList is a list of issues (kind "issue_list").
+type IssueList []Issue
+
+func (l IssueList) Kind() string { return "issue_list" }
+
+func (l IssueList) Header() []string { return (*Issue)(nil).Header() }
+
+func (l IssueList) Rows() [][]string {
+	rows := make([][]string, len(l))
+	for i := range l {
+		rows[i] = l[i].row()
+	}
+	return rows
+}
+
+// Branch is the generated git branch name of an issue (kind "branch").
+type Branch struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+func (b *Branch) Kind() string     { return "branch" }
+func (b *Branch) Header() []string { return []string{"KEY", "BRANCH"} }
+func (b *Branch) Rows() [][]string { return [][]string{{b.Key, b.Name}} }
+
+// Setting is one configuration key (kind "setting"). Secrets are masked.
+// Origin is a file path, an environment variable, a flag or "default".
+type Setting struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Origin      string `json:"origin,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+func (s *Setting) Kind() string     { return "setting" }
+func (s *Setting) Header() []string { return []string{"KEY", "VALUE", "ORIGIN"} }
+func (s *Setting) Rows() [][]string { return [][]string{s.row()} }
+
+func (s *Setting) row() []string {
+	return []string{s.Key, s.Value, s.Origin}
+}
+
+// SettingList is a list of settings (kind "setting_list").
+type SettingList []Setting
+
+func (l SettingList) Kind() string     { return "setting_list" }
+func (l SettingList) Header() []string { return (*Setting)(nil).Header() }
+
+func (l SettingList) Rows() [][]string {
+	rows := make([][]string, len(l))
+	for i := range l {
+		rows[i] = l[i].row()
+	}
+	return rows
+}
+
+// Config is the effective configuration of the active profile (kind
+// "config").
+type Config struct {
+	ConfigFile string      `json:"config_file"`
+	Profile    string      `json:"profile"`
+	LocalFiles []string    `json:"local_files"`
+	Settings   SettingList `json:"settings"`
+}
+
+func (c *Config) Kind() string     { return "config" }
+func (c *Config) Header() []string { return c.Settings.Header() }
+func (c *Config) Rows() [][]string { return c.Settings.Rows() }
+
+// Profile is a configured Jira site (see ProfileList).
+type Profile struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Active bool   `json:"active"`
+}
+
+// ProfileList lists the profiles (kind "profile_list").
+type ProfileList []Profile
+
+func (l ProfileList) Kind() string     { return "profile_list" }
+func (l ProfileList) Header() []string { return []string{"NAME", "URL", "ACTIVE"} }
+
+func (l ProfileList) Rows() [][]string {
+	rows := make([][]string, len(l))
+	for i, p := range l {
+		rows[i] = []string{p.Name, p.URL, strconv.FormatBool(p.Active)}
+	}
+	return rows
+}
+
+// AuthStatus is the OAuth login of the active profile (kind "auth_status").
+// Active is false when a login exists but jira.auth is not "oauth".
+type AuthStatus struct {
+	URL       string     `json:"url"`
+	LoggedIn  bool       `json:"logged_in"`
+	Active    bool       `json:"active"`
+	CloudID   string     `json:"cloud_id,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+func (a *AuthStatus) Kind() string { return "auth_status" }
+
+func (a *AuthStatus) Header() []string {
+	return []string{"URL", "LOGGED_IN", "ACTIVE", "CLOUD_ID", "EXPIRES"}
+}
+
+func (a *AuthStatus) Rows() [][]string {
+	return [][]string{{a.URL, strconv.FormatBool(a.LoggedIn), strconv.FormatBool(a.Active), a.CloudID, formatTime(a.ExpiresAt)}}
+}
+
+// Check is the result of one doctor check. Status is PASS, WARN, FAIL or
+// SKIP.
+type Check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+// Diagnosis is the report of "jcli doctor" (kind "diagnosis").
+type Diagnosis struct {
+	Profile string  `json:"profile"`
+	URL     string  `json:"url"`
+	Checks  []Check `json:"checks"`
+	Failed  int     `json:"failed"`
+}
+
+func (d *Diagnosis) Kind() string     { return "diagnosis" }
+func (d *Diagnosis) Header() []string { return []string{"CHECK", "STATUS", "DETAIL", "FIX"} }
+
+func (d *Diagnosis) Rows() [][]string {
+	rows := make([][]string, len(d.Checks))
+	for i, c := range d.Checks {
+		rows[i] = []string{c.Name, c.Status, c.Detail, c.Fix}
+	}
+	return rows
+}
+
+// Plugin is an external command found on PATH (see PluginList). ShadowedBy
+// is set when a plugin of the same name earlier on PATH runs instead;
+// Builtin when a jcli command of the same name does.
+type Plugin struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	ShadowedBy string `json:"shadowed_by,omitempty"`
+	Builtin    bool   `json:"builtin,omitempty"`
+}
+
+// PluginList lists the plugins (kind "plugin_list").
+type PluginList []Plugin
+
+func (l PluginList) Kind() string     { return "plugin_list" }
+func (l PluginList) Header() []string { return []string{"NAME", "PATH", "SHADOWED_BY", "BUILTIN"} }
+
+func (l PluginList) Rows() [][]string {
+	rows := make([][]string, len(l))
+	for i, p := range l {
+		rows[i] = []string{p.Name, p.Path, p.ShadowedBy, strconv.FormatBool(p.Builtin)}
+	}
+	return rows
+}
+
+// Version is the jcli version (kind "version").
+type Version struct {
+	Version       string `json:"version"`
+	SchemaVersion int    `json:"schema_version"`
+}
+
+func (v *Version) Kind() string     { return "version" }
+func (v *Version) Header() []string { return []string{"VERSION", "SCHEMA_VERSION"} }
+func (v *Version) Rows() [][]string { return [][]string{{v.Version, strconv.Itoa(v.SchemaVersion)}} }
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,182 @@
+// Package output renders command results as JSON, YAML, aligned tables,
+// tab-separated values or Go templates. JSON and YAML wrap the result in an
+// Envelope that carries the schema version and the kind of document.
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaVersion is the version of the documents in this package. It is
+// increased when a field is removed, renamed or changes meaning; fields may
+// be added without a new version.
+const SchemaVersion = 1
+
+// Format is an output format selected with --output.
+type Format string
+
+const (
+	Text  Format = "text"
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+	Table Format = "table"
+	TSV   Format = "tsv"
+)
+
+// Formats lists the supported formats; Text is the default.
+var Formats = []Format{Text, JSON, YAML, Table, TSV}
+
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("unknown output format %q (supported: %s)", s, strings.Join(names, ", "))
+}
+
+// Document is the result of a read command.
+type Document interface {
+	// Kind names the document type in the Envelope.
+	Kind() string
+	// Header and Rows are the table and TSV representation.
+	Header() []string
+	Rows() [][]string
+}
+
+// Envelope is the top-level JSON and YAML object.
+type Envelope struct {
+	Version int    `json:"version" yaml:"version"`
+	Kind    string `json:"kind" yaml:"kind"`
+	Data    any    `json:"data" yaml:"data"`
+}
+
+// Printer writes documents in Format, or executes Template on the document
+// data when it is set.
+type Printer struct {
+	Format   Format
+	Template *template.Template
+	Out      io.Writer
+}
+
+// ParseTemplate parses a --template argument. Besides the built-in
+// functions, "json" renders a value as JSON and "join" joins a list.
+func ParseTemplate(text string) (*template.Template, error) {
+	t, err := template.New("output").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"join": func(sep string, items []any) string {
+			parts := make([]string, len(items))
+			for i, item := range items {
+				parts[i] = fmt.Sprint(item)
+			}
+			return strings.Join(parts, sep)
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return t, nil
+}
+
+// Structured reports whether documents are printed by the Printer rather
+// than as the human-readable text of a command.
+func (p *Printer) Structured() bool {
+	return p.Template != nil || p.Format != Text
+}
+
+func (p *Printer) Print(doc Document) error {
+	if p.Template != nil {
+		data, err := generic(doc)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := p.Template.Execute(&buf, data); err != nil {
+			return fmt.Errorf("failed to execute template: %w", err)
+		}
+		if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		_, err = p.Out.Write(buf.Bytes())
+		return err
+	}
+
+	switch p.Format {
+	case JSON:
+		enc := json.NewEncoder(p.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(Envelope{Version: SchemaVersion, Kind: doc.Kind(), Data: doc})
+	case YAML:
+		data, err := generic(doc)
+		if err != nil {
+			return err
+		}
+		enc := yaml.NewEncoder(p.Out)
+		enc.SetIndent(2)
+		if err := enc.Encode(Envelope{Version: SchemaVersion, Kind: doc.Kind(), Data: data}); err != nil {
+			return fmt.Errorf("failed to encode YAML: %w", err)
+		}
+		return enc.Close()
+	case Table:
+		w := tabwriter.NewWriter(p.Out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(doc.Header(), "\t"))
+		for _, row := range doc.Rows() {
+			for i := range row {
+				row[i] = cellReplacer.Replace(row[i])
+			}
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return w.Flush()
+	case TSV:
+		for _, row := range doc.Rows() {
+			for i := range row {
+				row[i] = tsvEscape(row[i])
+			}
+			if _, err := fmt.Fprintln(p.Out, strings.Join(row, "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("format %q has no document representation", p.Format)
+	}
+}
+
+// generic converts doc to maps and slices keyed by the JSON field names, so
+// that YAML and templates use the same names as JSON.
+func generic(doc Document) (any, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode output: %w", err)
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("failed to encode output: %w", err)
+	}
+	return v, nil
+}
+
+// cellReplacer keeps table cells on one line and in their column.
+var cellReplacer = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+
+var tsvReplacer = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+
+// tsvEscape escapes the characters that would break a TSV row.
+func tsvEscape(s string) string {
+	return tsvReplacer.Replace(s)
+}
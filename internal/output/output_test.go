@@ -0,0 +1,148 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func render(t *testing.T, p Printer, doc Document) string {
+	t.Helper()
+	var buf bytes.Buffer
+	p.Out = &buf
+	if err := p.Print(doc); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range Formats {
+		if got, err := ParseFormat(string(f)); err != nil || got != f {
+			t.Errorf("ParseFormat(%q) = %q, %v", f, got, err)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil || !strings.Contains(err.Error(), "json, yaml") {
+		t.Errorf("expected error listing formats, got %v", err)
+	}
+}
+
+func TestPrint_JSON(t *testing.T) {
+	selected := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	out := render(t, Printer{Format: JSON}, &Issue{Key: "PROJ-1", Summary: "Fix login", SelectedAt: &selected})
+
+	var env struct {
+		Version int
+		Kind    string
+		Data    map[string]any
+	}
+	if err := json.Unmarshal([]byte(out), &env); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if env.Version != SchemaVersion || env.Kind != "issue" {
+		t.Errorf("unexpected envelope: %+v", env)
+	}
+	want := map[string]any{"key": "PROJ-1", "summary": "Fix login", "selected_at": "2024-05-01T09:30:00Z"}
+	for k, v := range want {
+		if env.Data[k] != v {
+			t.Errorf("data[%q] = %v, want %v", k, env.Data[k], v)
+		}
+	}
+	if _, ok := env.Data["branch"]; ok {
+		t.Error("expected empty branch to be omitted")
+	}
+}
+
+func TestPrint_NilIssue(t *testing.T) {
+	var issue *Issue
+	out := render(t, Printer{Format: JSON}, issue)
+	if !strings.Contains(out, `"data": null`) {
+		t.Errorf("expected null data, got %s", out)
+	}
+	if out := render(t, Printer{Format: TSV}, issue); out != "" {
+		t.Errorf("expected no TSV rows, got %q", out)
+	}
+}
+
+func TestPrint_YAML(t *testing.T) {
+	out := render(t, Printer{Format: YAML}, ProfileList{{Name: "default", URL: "https://a.atlassian.net", Active: true}})
+
+	var env struct {
+		Version int
+		Kind    string
+		Data    []map[string]any
+	}
+	if err := yaml.Unmarshal([]byte(out), &env); err != nil {
+		t.Fatalf("invalid YAML: %v\n%s", err, out)
+	}
+	if env.Version != SchemaVersion || env.Kind != "profile_list" || len(env.Data) != 1 {
+		t.Fatalf("unexpected document: %+v", env)
+	}
+	if env.Data[0]["name"] != "default" || env.Data[0]["active"] != true {
+		t.Errorf("expected JSON field names in YAML, got %v", env.Data[0])
+	}
+}
+
+func TestPrint_TableAndTSV(t *testing.T) {
+	list := IssueList{
+		{Key: "PROJ-1", Summary: "Short"},
+		{Key: "PROJ-22", Summary: "Tab\tand\nnewline"},
+	}
+
+	table := render(t, Printer{Format: Table}, list)
+	lines := strings.Split(strings.TrimRight(table, "\n"), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "KEY      SUMMARY") {
+		t.Errorf("unexpected table:\n%s", table)
+	}
+
+	tsv := render(t, Printer{Format: TSV}, list)
+	want := "PROJ-1\tShort\t\t\nPROJ-22\tTab\\tand\\nnewline\t\t\n"
+	if tsv != want {
+		t.Errorf("TSV = %q, want %q", tsv, want)
+	}
+}
+
+func TestPrint_Template(t *testing.T) {
+	tmpl, err := ParseTemplate(`{{range .}}{{.key}}={{.summary}} {{end}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := render(t, Printer{Template: tmpl}, IssueList{{Key: "A-1", Summary: "one"}, {Key: "A-2", Summary: "two"}})
+	if out != "A-1=one A-2=two \n" {
+		t.Errorf("template output = %q", out)
+	}
+
+	tmpl, err = ParseTemplate(`{{.profile}} {{json .local_files}} {{join "," .local_files}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out = render(t, Printer{Template: tmpl}, &Config{Profile: "work", LocalFiles: []string{"a", "b"}})
+	if out != "work [\"a\",\"b\"] a,b\n" {
+		t.Errorf("template output = %q", out)
+	}
+
+	if _, err := ParseTemplate("{{.key"); err == nil {
+		t.Error("expected error for invalid template")
+	}
+}
+
+func TestPrinter_Structured(t *testing.T) {
+	tmpl, _ := ParseTemplate("{{.}}")
+	tests := []struct {
+		printer Printer
+		want    bool
+	}{
+		{Printer{Format: Text}, false},
+		{Printer{Format: JSON}, true},
+		{Printer{Format: Text, Template: tmpl}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.printer.Structured(); got != tt.want {
+			t.Errorf("Structured() for %+v = %v, want %v", tt.printer, got, tt.want)
+		}
+	}
+}
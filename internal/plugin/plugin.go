@@ -0,0 +1,100 @@
+// Package plugin finds external jcli commands: executables named
+// jcli-<name> on PATH, run as "jcli <name>".
+package plugin
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Prefix is the file name prefix of plugin executables.
+const Prefix = "jcli-"
+
+// Plugin is an executable found on PATH.
+type Plugin struct {
+	Name string
+	Path string
+	// ShadowedBy is the path of the plugin with the same name found earlier
+	// on PATH, which is the one that runs.
+	ShadowedBy string
+}
+
+// Find returns the path of the plugin called name.
+func Find(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", exec.ErrNotFound
+	}
+	return exec.LookPath(Prefix + name)
+}
+
+// List returns the plugins on PATH in PATH order. A plugin shadowed by an
+// earlier one of the same name is listed with ShadowedBy set.
+func List() []Plugin {
+	var plugins []Plugin
+	first := make(map[string]string)
+	seenDirs := make(map[string]bool)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" || seenDirs[dir] {
+			continue
+		}
+		seenDirs[dir] = true
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		var found []Plugin
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok || entry.IsDir() {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			found = append(found, Plugin{Name: name, Path: path})
+		}
+		sort.Slice(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+
+		for _, p := range found {
+			if path, ok := first[p.Name]; ok {
+				p.ShadowedBy = path
+			} else {
+				first[p.Name] = p.Path
+			}
+			plugins = append(plugins, p)
+		}
+	}
+	return plugins
+}
+
+func pluginName(file string) (string, bool) {
+	if !strings.HasPrefix(file, Prefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(file, Prefix)
+	if runtime.GOOS == "windows" {
+		ext := filepath.Ext(name)
+		if !strings.Contains(strings.ToLower(os.Getenv("PATHEXT")), strings.ToLower(ext)) || ext == "" {
+			return "", false
+		}
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name, name != ""
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return info.Mode()&0111 != 0
+}
@@ -0,0 +1,55 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func writePlugin(t *testing.T, dir, file string, mode os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(dir, file)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), mode); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestListAndFind(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses executable bits")
+	}
+	first, second := t.TempDir(), t.TempDir()
+	standup := writePlugin(t, first, "jcli-standup", 0755)
+	writePlugin(t, first, "jcli-notes.txt", 0644)
+	writePlugin(t, first, "other-tool", 0755)
+	shadowed := writePlugin(t, second, "jcli-standup", 0755)
+	deploy := writePlugin(t, second, "jcli-deploy-note", 0755)
+	t.Setenv("PATH", strings.Join([]string{first, "", second, first}, string(os.PathListSeparator)))
+
+	got := List()
+	want := []Plugin{
+		{Name: "standup", Path: standup},
+		{Name: "deploy-note", Path: deploy},
+		{Name: "standup", Path: shadowed, ShadowedBy: standup},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("List() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("List()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if path, err := Find("standup"); err != nil || path != standup {
+		t.Errorf("Find(standup) = %q, %v, want %q", path, err, standup)
+	}
+	for _, name := range []string{"notes.txt", "missing", "../standup", ""} {
+		if _, err := Find(name); err == nil {
+			t.Errorf("Find(%q) should fail", name)
+		}
+	}
+}
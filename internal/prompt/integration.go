@@ -0,0 +1,89 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// integrationMarker starts the block Install appends, so that it is only
+// added once.
+const integrationMarker = "# jcli shell integration"
+
+// completions enable jcli completion in each shell Integration supports.
+var completions = map[string]string{
+	"bash": "source <(jcli completion bash)",
+	"zsh":  "source <(jcli completion zsh)",
+	"fish": "jcli completion fish | source",
+}
+
+// Shells lists the shells Integration supports.
+func Shells() []string {
+	return []string{"bash", "zsh", "fish"}
+}
+
+// Integration returns the startup file of shell in home and the block that
+// enables jcli completion and the prompt segment there.
+func Integration(shell, home string) (file, block string, err error) {
+	completion, ok := completions[shell]
+	if !ok {
+		return "", "", fmt.Errorf("no shell integration for %q (available: %s)", shell, strings.Join(Shells(), ", "))
+	}
+	switch shell {
+	case "bash":
+		file = filepath.Join(home, ".bashrc")
+	case "zsh":
+		dir := os.Getenv("ZDOTDIR")
+		if dir == "" {
+			dir = home
+		}
+		file = filepath.Join(dir, ".zshrc")
+	case "fish":
+		file = filepath.Join(home, ".config", "fish", "config.fish")
+	}
+
+	snippet, err := Snippet(shell)
+	if err != nil {
+		return "", "", err
+	}
+	// The snippet starts with a comment naming the file it belongs in.
+	if first, rest, ok := strings.Cut(snippet, "\n"); ok && strings.HasPrefix(first, "# ") {
+		snippet = rest
+	}
+	return file, integrationMarker + "\n" + completion + "\n" + snippet, nil
+}
+
+// Install appends block to file unless an earlier Install added it, and
+// reports whether it did.
+func Install(file, block string) (bool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if strings.Contains(string(data), integrationMarker) {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return false, fmt.Errorf("failed to create %s: %w", filepath.Dir(file), err)
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return false, fmt.Errorf("failed to open %s: %w", file, err)
+	}
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		block = "\n" + block
+	}
+	if len(data) > 0 {
+		block = "\n" + block
+	}
+	if _, err := f.WriteString(block); err != nil {
+		f.Close()
+		return false, fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	if err := f.Close(); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,72 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIntegration(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("ZDOTDIR", "")
+
+	tests := []struct {
+		shell string
+		file  string
+		want  string
+	}{
+		{shell: "bash", file: ".bashrc", want: "source <(jcli completion bash)\n__jcli_prompt() {"},
+		{shell: "zsh", file: ".zshrc", want: "source <(jcli completion zsh)\nsetopt prompt_subst"},
+		{shell: "fish", file: filepath.Join(".config", "fish", "config.fish"), want: "jcli completion fish | source\nfunction fish_right_prompt"},
+	}
+	for _, tt := range tests {
+		file, block, err := Integration(tt.shell, home)
+		if err != nil {
+			t.Fatalf("Integration(%s) error = %v", tt.shell, err)
+		}
+		if file != filepath.Join(home, tt.file) {
+			t.Errorf("Integration(%s) file = %s", tt.shell, file)
+		}
+		if !strings.HasPrefix(block, integrationMarker+"\n") || !strings.Contains(block, tt.want) || strings.Contains(block, "# ~/") {
+			t.Errorf("Integration(%s) block = %q", tt.shell, block)
+		}
+	}
+
+	t.Setenv("ZDOTDIR", filepath.Join(home, "zdot"))
+	if file, _, _ := Integration("zsh", home); file != filepath.Join(home, "zdot", ".zshrc") {
+		t.Errorf("Integration(zsh) ignores ZDOTDIR: %s", file)
+	}
+	if _, _, err := Integration("tcsh", home); err == nil {
+		t.Error("expected an error for an unsupported shell")
+	}
+}
+
+func TestInstall(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "fish", "config.fish")
+	block := integrationMarker + "\necho hi\n"
+
+	added, err := Install(file, block)
+	if err != nil || !added {
+		t.Fatalf("Install() into a new file = %v, %v", added, err)
+	}
+	if added, err := Install(file, block); err != nil || added {
+		t.Errorf("second Install() = %v, %v, want no change", added, err)
+	}
+	data, _ := os.ReadFile(file)
+	if string(data) != block {
+		t.Errorf("file = %q", data)
+	}
+
+	rc := filepath.Join(t.TempDir(), ".bashrc")
+	if err := os.WriteFile(rc, []byte("alias ll='ls -l'"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Install(rc, block); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(rc)
+	if string(data) != "alias ll='ls -l'\n\n"+block {
+		t.Errorf("rc file = %q", data)
+	}
+}
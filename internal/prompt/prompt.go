@@ -0,0 +1,44 @@
+// Package prompt formats the current issue for shell prompts.
+package prompt
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultFormat shows only the issue key. Formats may use the {key},
+// {summary}, {status}, {type} and {profile} placeholders.
+const DefaultFormat = "{key}"
+
+// Info is what a prompt can show about the current issue. Status and Type
+// come from the cache and may be empty.
+type Info struct {
+	Key     string
+	Summary string
+	Status  string
+	Type    string
+	Profile string
+}
+
+// Render replaces the placeholders of format with info. Summaries longer
+// than maxSummary runes are shortened with "…"; zero keeps them whole.
+func Render(format string, info Info, maxSummary int) string {
+	if format == "" {
+		format = DefaultFormat
+	}
+	return strings.NewReplacer(
+		"{key}", info.Key,
+		"{summary}", truncate(info.Summary, maxSummary),
+		"{status}", info.Status,
+		"{type}", info.Type,
+		"{profile}", info.Profile,
+	).Replace(format)
+}
+
+func truncate(s string, max int) string {
+	if max <= 0 || utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimRight(string(runes[:max-1]), " ") + "…"
+}
@@ -0,0 +1,43 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	info := Info{Key: "PROJ-1", Summary: "Fix the login redirect loop", Status: "In Progress", Type: "Bug", Profile: "work"}
+
+	tests := []struct {
+		format     string
+		maxSummary int
+		want       string
+	}{
+		{format: "", want: "PROJ-1"},
+		{format: "{key} [{status}]", want: "PROJ-1 [In Progress]"},
+		{format: "{type}:{key} {summary}", maxSummary: 10, want: "Bug:PROJ-1 Fix the l…"},
+		{format: "{summary}", maxSummary: 8, want: "Fix the…"},
+		{format: "{summary}", maxSummary: 100, want: "Fix the login redirect loop"},
+		{format: "{profile}/{key} {unknown}", want: "work/PROJ-1 {unknown}"},
+	}
+	for _, tt := range tests {
+		if got := Render(tt.format, info, tt.maxSummary); got != tt.want {
+			t.Errorf("Render(%q, %d) = %q, want %q", tt.format, tt.maxSummary, got, tt.want)
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	for _, name := range SnippetNames() {
+		snippet, err := Snippet(name)
+		if err != nil || !strings.Contains(snippet, "jcli prompt") {
+			t.Errorf("Snippet(%q) = %q, %v", name, snippet, err)
+		}
+	}
+	if _, err := Snippet("Starship"); err != nil {
+		t.Errorf("expected case-insensitive lookup, got %v", err)
+	}
+	if _, err := Snippet("tcsh"); err == nil || !strings.Contains(err.Error(), "bash, fish") {
+		t.Errorf("expected error listing snippets, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package prompt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// snippets integrate "jcli prompt" into prompt frameworks and shells.
+var snippets = map[string]string{
+	"starship": `# ~/.config/starship.toml
+[custom.jira]
+command = "jcli prompt --format '{key}'"
+when = true
+symbol = " "
+style = "bold blue"
+format = "[$symbol$output]($style) "
+`,
+	"powerlevel10k": `# ~/.p10k.zsh: define the segment and add "jira" to
+# POWERLEVEL9K_LEFT_PROMPT_ELEMENTS or POWERLEVEL9K_RIGHT_PROMPT_ELEMENTS.
+function prompt_jira() {
+  local issue
+  issue=$(jcli prompt --format '{key}' 2>/dev/null) || return
+  [[ -n $issue ]] && p10k segment -f blue -t "$issue"
+}
+`,
+	"bash": `# ~/.bashrc
+__jcli_prompt() {
+  local issue
+  issue=$(jcli prompt --format '{key}' 2>/dev/null)
+  [ -n "$issue" ] && printf '[%s] ' "$issue"
+}
+PS1='$(__jcli_prompt)'"$PS1"
+`,
+	"zsh": `# ~/.zshrc
+setopt prompt_subst
+__jcli_prompt() {
+  local issue
+  issue=$(jcli prompt --format '{key}' 2>/dev/null)
+  [[ -n $issue ]] && print -n "[$issue] "
+}
+RPROMPT='$(__jcli_prompt)'"$RPROMPT"
+`,
+	"fish": `# ~/.config/fish/functions/fish_right_prompt.fish
+function fish_right_prompt
+    set -l issue (jcli prompt --format '{key}' 2>/dev/null)
+    test -n "$issue"; and printf '[%s] ' $issue
+end
+`,
+}
+
+// Snippet returns the configuration snippet for a prompt framework.
+func Snippet(name string) (string, error) {
+	snippet, ok := snippets[strings.ToLower(name)]
+	if !ok {
+		return "", fmt.Errorf("no snippet for %q (available: %s)", name, strings.Join(SnippetNames(), ", "))
+	}
+	return snippet, nil
+}
+
+// SnippetNames returns the frameworks with a snippet, sorted.
+func SnippetNames() []string {
+	names := make([]string, 0, len(snippets))
+	for name := range snippets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
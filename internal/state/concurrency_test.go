@@ -0,0 +1,160 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"testing"
+)
+
+// hammer saves and loads state repeatedly, failing on any error. Every Load
+// must parse: a torn write would surface as a JSON error.
+func hammer(id string, rounds int) error {
+	for i := 0; i < rounds; i++ {
+		s := &State{}
+		s.SetCurrentIssueFor("/repo/"+id, fmt.Sprintf("%s-%d", id, i), "Concurrent write")
+		if err := s.Save(); err != nil {
+			return fmt.Errorf("save: %w", err)
+		}
+		loaded, err := Load()
+		if err != nil {
+			return fmt.Errorf("load: %w", err)
+		}
+		if len(loaded.Scopes) == 0 {
+			return fmt.Errorf("loaded state without a selection")
+		}
+	}
+	return nil
+}
+
+func TestHammerHelperProcess(t *testing.T) {
+	id := os.Getenv("JCLI_HAMMER_ID")
+	if id == "" {
+		t.Skip("helper process for TestConcurrentSaveLoad")
+	}
+	if err := hammer(id, 50); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConcurrentSaveLoad(t *testing.T) {
+	stateDir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", stateDir)
+
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*2)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := hammer(fmt.Sprintf("G%d", i), 50); err != nil {
+				errs <- fmt.Errorf("goroutine %d: %w", i, err)
+			}
+		}(i)
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cmd := exec.Command(os.Args[0], "-test.run=^TestHammerHelperProcess$")
+			cmd.Env = append(os.Environ(),
+				"XDG_STATE_HOME="+stateDir,
+				fmt.Sprintf("JCLI_HAMMER_ID=P%d", i),
+			)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				errs <- fmt.Errorf("process %d: %v\n%s", i, err, out)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	if _, err := Load(); err != nil {
+		t.Errorf("final state is unreadable: %v", err)
+	}
+}
+
+// selectIssues selects one issue per round in its own scope through Update.
+// A lost update would drop the selections another writer made in between.
+func selectIssues(id string, rounds int) error {
+	for i := 0; i < rounds; i++ {
+		_, err := Update(func(s *State) error {
+			s.SetCurrentIssueFor("/repo/"+id, fmt.Sprintf("%s-%d", id, i), "Concurrent update")
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestUpdateHelperProcess(t *testing.T) {
+	id := os.Getenv("JCLI_UPDATE_ID")
+	if id == "" {
+		t.Skip("helper process for TestConcurrentUpdate")
+	}
+	if err := selectIssues(id, 10); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConcurrentUpdate(t *testing.T) {
+	stateDir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", stateDir)
+
+	const workers, rounds = 4, 10
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*2)
+
+	ids := make([]string, 0, workers*2)
+	for i := 0; i < workers; i++ {
+		ids = append(ids, fmt.Sprintf("G%d", i), fmt.Sprintf("P%d", i))
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			if err := selectIssues(fmt.Sprintf("G%d", i), rounds); err != nil {
+				errs <- fmt.Errorf("goroutine %d: %w", i, err)
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			cmd := exec.Command(os.Args[0], "-test.run=^TestUpdateHelperProcess$")
+			cmd.Env = append(os.Environ(),
+				"XDG_STATE_HOME="+stateDir,
+				fmt.Sprintf("JCLI_UPDATE_ID=P%d", i),
+			)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				errs <- fmt.Errorf("process %d: %v\n%s", i, err, out)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	s, err := Load()
+	if err != nil {
+		t.Fatalf("final state is unreadable: %v", err)
+	}
+	for _, id := range ids {
+		scope := "/repo/" + id
+		if got := s.CurrentIssueFor(scope); got == nil || got.Key != fmt.Sprintf("%s-%d", id, rounds-1) {
+			t.Errorf("expected the last selection of %s, got %+v", scope, got)
+		}
+		if got := len(s.HistoryFor(scope)); got != rounds {
+			t.Errorf("expected %d history entries for %s, got %d: updates were lost", rounds, scope, got)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/tutunak/jcli/internal/lockedfile"
+	"github.com/tutunak/jcli/internal/migrate"
+)
+
+// CurrentVersion is the state schema version written by Save.
+const CurrentVersion = 1
+
+// migrations upgrade state.json one schema version at a time. Append new
+// steps and bump CurrentVersion whenever fields are restructured.
+var migrations = []migrate.Step[map[string]any]{
+	{
+		// Version 1 is the layout of the first versioned release, including
+		// the per-scope selections and history; files without a version
+		// only get it stamped.
+		From:        0,
+		Description: "stamp the baseline schema version",
+		Apply:       func(doc map[string]any) error { return nil },
+	},
+}
+
+// upgrade migrates raw state file contents to CurrentVersion. When the file
+// was older, the original is backed up and the upgraded contents are written
+// back to path. Both happen under the lock Save uses, on the contents read
+// under it.
+func upgrade(path string, data []byte) ([]byte, error) {
+	if _, from, err := migrateJSON(data); err != nil || from == CurrentVersion {
+		return data, err
+	}
+
+	return lockedfile.Update(path, 0600, func(data []byte) ([]byte, error) {
+		// Another jcli may have upgraded the file in the meantime.
+		return upgradeLocked(path, data)
+	})
+}
+
+// upgradeLocked is upgrade for callers holding the lock of path: it backs up
+// data when it is older than CurrentVersion and returns it upgraded.
+func upgradeLocked(path string, data []byte) ([]byte, error) {
+	upgraded, from, err := migrateJSON(data)
+	if err != nil || from == CurrentVersion {
+		return data, err
+	}
+	if _, err := migrate.Backup(path, data, from); err != nil {
+		return nil, err
+	}
+	return upgraded, nil
+}
+
+// migrateJSON upgrades state file contents and returns them with the
+// version they started from. Contents already at CurrentVersion are
+// returned as they are.
+func migrateJSON(data []byte) ([]byte, int, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse state file: %w", err)
+	}
+	if doc == nil {
+		return data, CurrentVersion, nil
+	}
+
+	from, err := migrate.Run(doc, migrations)
+	if err != nil {
+		return nil, from, fmt.Errorf("failed to migrate state file: %w", err)
+	}
+	if from == CurrentVersion {
+		return data, from, nil
+	}
+
+	upgraded, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, from, fmt.Errorf("failed to marshal migrated state: %w", err)
+	}
+	return upgraded, from, nil
+}
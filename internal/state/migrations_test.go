@@ -0,0 +1,65 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tutunak/jcli/internal/migrate"
+)
+
+func TestMigrationsMatchCurrentVersion(t *testing.T) {
+	if got := migrate.Latest(migrations); got != CurrentVersion {
+		t.Errorf("migrations produce version %d, CurrentVersion is %d", got, CurrentVersion)
+	}
+}
+
+func TestLoadUpgradesUnversionedState(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", tmpDir)
+
+	path := filepath.Join(tmpDir, "jcli", "state.json")
+	original := `{"current_issue":{"key":"OLD-1","summary":"Old","selected_at":"2024-01-15T10:30:00Z"}}`
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Version != CurrentVersion {
+		t.Errorf("expected version %d, got %d", CurrentVersion, s.Version)
+	}
+	if !s.HasCurrentIssue() || s.CurrentIssue.Key != "OLD-1" {
+		t.Errorf("expected current issue to survive migration, got %+v", s.CurrentIssue)
+	}
+
+	backup, err := os.ReadFile(path + ".v0.bak")
+	if err != nil {
+		t.Fatalf("expected backup of original state: %v", err)
+	}
+	if string(backup) != original {
+		t.Errorf("backup differs from original: %q", backup)
+	}
+}
+
+func TestLoadRejectsNewerState(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", tmpDir)
+
+	path := filepath.Join(tmpDir, "jcli", "state.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"version":99}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(); err == nil {
+		t.Error("expected error for state written by a newer version")
+	}
+}
@@ -2,22 +2,50 @@ package state
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/tutunak/jcli/internal/git"
+	"github.com/tutunak/jcli/internal/lockedfile"
 )
 
+// CurrentIssue is a selected issue. Scope is only set on history entries
+// and names the scope the issue was selected in, "" for global selections.
 type CurrentIssue struct {
 	Key        string    `json:"key"`
 	Summary    string    `json:"summary"`
 	SelectedAt time.Time `json:"selected_at"`
+	Scope      string    `json:"scope,omitempty"`
 }
 
+// State holds the global current issue and, per scope, the issue selected
+// in a git repository (or working directory outside of one). Scopes fall
+// back to the global issue when they have no selection of their own.
 type State struct {
-	CurrentIssue *CurrentIssue `json:"current_issue,omitempty"`
+	Version      int                      `json:"version"`
+	CurrentIssue *CurrentIssue            `json:"current_issue,omitempty"`
+	Scopes       map[string]*CurrentIssue `json:"scopes,omitempty"`
+	History      []CurrentIssue           `json:"history,omitempty"`
 }
 
+// ErrNoIssue is returned by commands that need a selected issue when none
+// is selected.
+var ErrNoIssue = errors.New("no issue selected")
+
+// ErrNoPrevious is returned by 'issue switch -' when the history holds no
+// other issue.
+var ErrNoPrevious = errors.New("no previous issue to switch to")
+
+// MaxHistory bounds the number of recently selected issues kept per scope.
+const MaxHistory = 20
+
+// MaxScopes bounds the number of per-directory selections kept in state;
+// the least recently selected ones are dropped first.
+const MaxScopes = 50
+
 func StateDir() (string, error) {
 	if xdgState := os.Getenv("XDG_STATE_HOME"); xdgState != "" {
 		return filepath.Join(xdgState, "jcli"), nil
@@ -29,11 +57,73 @@ func StateDir() (string, error) {
 	return filepath.Join(home, ".local", "state", "jcli"), nil
 }
 
+// ScopeFor returns the scope key for dir: the root of the git repository
+// containing it, or the absolute directory itself outside of a repository.
+// An empty dir means the working directory.
+func ScopeFor(dir string) (string, error) {
+	abs, err := resolveDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if root, err := git.RepoRoot(abs); err == nil {
+		return filepath.Clean(root), nil
+	}
+	return abs, nil
+}
+
+// QuickScopeFor is ScopeFor without starting git, for callers that must
+// finish in a few milliseconds.
+func QuickScopeFor(dir string) (string, error) {
+	abs, err := resolveDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if root, err := git.FindRoot(abs); err == nil {
+		return root, nil
+	}
+	return abs, nil
+}
+
+// resolveDir returns dir, or the working directory when it is empty, as an
+// absolute path with symlinks resolved to match the roots git reports.
+func resolveDir(dir string) (string, error) {
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to get working directory: %w", err)
+		}
+		dir = wd
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve directory: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	return abs, nil
+}
+
+// profile selects the state file so that selections made against different
+// Jira sites don't mix. The default profile uses the top-level state.json.
+var profile string
+
+func SetProfile(name string) {
+	if name == "default" {
+		name = ""
+	}
+	profile = name
+}
+
 func StatePath() (string, error) {
 	dir, err := StateDir()
 	if err != nil {
 		return "", err
 	}
+	if profile != "" {
+		return filepath.Join(dir, "profiles", profile, "state.json"), nil
+	}
 	return filepath.Join(dir, "state.json"), nil
 }
 
@@ -45,12 +135,17 @@ func Load() (*State, error) {
 
 	data, err := os.ReadFile(path)
 	if os.IsNotExist(err) {
-		return &State{}, nil
+		return &State{Version: CurrentVersion}, nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read state file: %w", err)
 	}
 
+	data, err = upgrade(path, data)
+	if err != nil {
+		return nil, err
+	}
+
 	var state State
 	if err := json.Unmarshal(data, &state); err != nil {
 		return nil, fmt.Errorf("failed to parse state file: %w", err)
@@ -60,34 +155,143 @@ func Load() (*State, error) {
 }
 
 func (s *State) Save() error {
-	path, err := StatePath()
+	path, err := statePathDir()
+	if err != nil {
+		return err
+	}
+
+	data, err := s.marshal()
 	if err != nil {
 		return err
 	}
 
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0700); err != nil {
-		return fmt.Errorf("failed to create state directory: %w", err)
+	if err := lockedfile.Write(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	return nil
+}
+
+// Update loads the state, lets fn change it and saves the result, all under
+// the lock of the state file, so that concurrent selections are never lost.
+// Errors of fn are returned as they are.
+func Update(fn func(*State) error) (*State, error) {
+	path, err := statePathDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to save state: %w", err)
 	}
 
+	var state *State
+	var changed bool
+	_, err = lockedfile.Update(path, 0600, func(data []byte) ([]byte, error) {
+		s := &State{Version: CurrentVersion}
+		if data != nil {
+			upgraded, err := upgradeLocked(path, data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load state: %w", err)
+			}
+			if err := json.Unmarshal(upgraded, s); err != nil {
+				return nil, fmt.Errorf("failed to load state: failed to parse state file: %w", err)
+			}
+		}
+		if err := fn(s); err != nil {
+			return nil, err
+		}
+		state, changed = s, true
+		return s.marshal()
+	})
+	if err != nil {
+		if changed {
+			return nil, fmt.Errorf("failed to save state: %w", err)
+		}
+		return nil, err
+	}
+	return state, nil
+}
+
+// statePathDir returns StatePath after creating its directory.
+func statePathDir() (string, error) {
+	path, err := StatePath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return path, nil
+}
+
+func (s *State) marshal() ([]byte, error) {
+	s.Version = CurrentVersion
 	data, err := json.MarshalIndent(s, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal state: %w", err)
+		return nil, fmt.Errorf("failed to marshal state: %w", err)
 	}
+	return data, nil
+}
 
-	if err := os.WriteFile(path, data, 0600); err != nil {
-		return fmt.Errorf("failed to write state file: %w", err)
+func (s *State) SetCurrentIssue(key, summary string) {
+	s.SetCurrentIssueFor("", key, summary)
+}
+
+// recordHistory moves issue to the front of the most-recently-used list of
+// scope. Only the MaxScopes most recently used scopes keep their entries.
+func (s *State) recordHistory(scope string, issue CurrentIssue) {
+	issue.Scope = scope
+	history := make([]CurrentIssue, 0, len(s.History)+1)
+	history = append(history, issue)
+	counts := map[string]int{scope: 1}
+	for _, entry := range s.History {
+		if entry.Key == issue.Key && entry.Scope == scope {
+			continue
+		}
+		n, seen := counts[entry.Scope]
+		if n >= MaxHistory || !seen && len(counts) >= MaxScopes {
+			continue
+		}
+		counts[entry.Scope] = n + 1
+		history = append(history, entry)
 	}
+	s.History = history
+}
 
+// HistoryFor returns the recently selected issues of scope, most recent
+// first. The global scope sees the selections of every scope, each issue
+// once.
+func (s *State) HistoryFor(scope string) []CurrentIssue {
+	var history []CurrentIssue
+	seen := make(map[string]bool)
+	for _, entry := range s.History {
+		if scope != "" && entry.Scope != scope || seen[entry.Key] {
+			continue
+		}
+		seen[entry.Key] = true
+		history = append(history, entry)
+	}
+	return history
+}
+
+// RecentIssue returns the history entry for key, if any.
+func (s *State) RecentIssue(key string) *CurrentIssue {
+	for i := range s.History {
+		if s.History[i].Key == key {
+			return &s.History[i]
+		}
+	}
 	return nil
 }
 
-func (s *State) SetCurrentIssue(key, summary string) {
-	s.CurrentIssue = &CurrentIssue{
-		Key:        key,
-		Summary:    summary,
-		SelectedAt: time.Now(),
+// PreviousIssue returns the most recently selected issue of scope other
+// than its current issue, like "cd -" does for directories.
+func (s *State) PreviousIssue(scope string) *CurrentIssue {
+	current := s.CurrentIssueFor(scope)
+	history := s.HistoryFor(scope)
+	for i := range history {
+		if current == nil || history[i].Key != current.Key {
+			return &history[i]
+		}
 	}
+	return nil
 }
 
 func (s *State) ClearCurrentIssue() {
@@ -97,3 +301,51 @@ func (s *State) ClearCurrentIssue() {
 func (s *State) HasCurrentIssue() bool {
 	return s.CurrentIssue != nil
 }
+
+// CurrentIssueFor returns the issue selected in scope, falling back to the
+// global issue. An empty scope means the global issue.
+func (s *State) CurrentIssueFor(scope string) *CurrentIssue {
+	if issue, ok := s.Scopes[scope]; ok && scope != "" {
+		return issue
+	}
+	return s.CurrentIssue
+}
+
+// SetCurrentIssueFor selects the issue in scope, or the global issue when
+// scope is empty. The selections of other scopes are left alone.
+func (s *State) SetCurrentIssueFor(scope, key, summary string) {
+	issue := CurrentIssue{Key: key, Summary: summary, SelectedAt: time.Now()}
+	s.recordHistory(scope, issue)
+	if scope == "" {
+		s.CurrentIssue = &issue
+		return
+	}
+	if s.Scopes == nil {
+		s.Scopes = make(map[string]*CurrentIssue)
+	}
+	s.Scopes[scope] = &issue
+	s.pruneScopes()
+}
+
+// pruneScopes drops the least recently selected scopes beyond MaxScopes.
+func (s *State) pruneScopes() {
+	for len(s.Scopes) > MaxScopes {
+		var oldest string
+		for scope, issue := range s.Scopes {
+			if oldest == "" || issue.SelectedAt.Before(s.Scopes[oldest].SelectedAt) {
+				oldest = scope
+			}
+		}
+		delete(s.Scopes, oldest)
+	}
+}
+
+// ClearCurrentIssueFor removes the selection of scope, or the global
+// selection when scope is empty.
+func (s *State) ClearCurrentIssueFor(scope string) {
+	if scope == "" {
+		s.ClearCurrentIssue()
+		return
+	}
+	delete(s.Scopes, scope)
+}
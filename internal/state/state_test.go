@@ -1,9 +1,11 @@
 package state
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestStateDir(t *testing.T) {
@@ -115,3 +117,275 @@ func TestHasCurrentIssue(t *testing.T) {
 		t.Error("expected HasCurrentIssue to be true when issue is set")
 	}
 }
+
+func TestCurrentIssueFor(t *testing.T) {
+	s := &State{}
+
+	if s.CurrentIssueFor("/repo/a") != nil {
+		t.Error("expected no issue in empty state")
+	}
+
+	s.SetCurrentIssueFor("", "GLOBAL-1", "Global")
+	if got := s.CurrentIssueFor("/repo/a"); got == nil || got.Key != "GLOBAL-1" {
+		t.Errorf("expected fallback to global issue, got %+v", got)
+	}
+
+	s.SetCurrentIssueFor("/repo/a", "A-1", "Repo A")
+	s.SetCurrentIssueFor("/repo/b", "B-1", "Repo B")
+
+	if got := s.CurrentIssueFor("/repo/a"); got == nil || got.Key != "A-1" {
+		t.Errorf("expected A-1 for /repo/a, got %+v", got)
+	}
+	if got := s.CurrentIssueFor("/repo/b"); got == nil || got.Key != "B-1" {
+		t.Errorf("expected B-1 for /repo/b, got %+v", got)
+	}
+	if got := s.CurrentIssueFor("/repo/c"); got == nil || got.Key != "GLOBAL-1" {
+		t.Errorf("expected scoped selections to leave the global issue alone, got %+v", got)
+	}
+
+	s.ClearCurrentIssueFor("/repo/a")
+	if got := s.CurrentIssueFor("/repo/a"); got == nil || got.Key != "GLOBAL-1" {
+		t.Errorf("expected fallback to global after clearing scope, got %+v", got)
+	}
+
+	s.ClearCurrentIssueFor("")
+	if s.HasCurrentIssue() {
+		t.Error("expected global issue to be cleared")
+	}
+	if got := s.CurrentIssueFor("/repo/b"); got == nil || got.Key != "B-1" {
+		t.Errorf("expected scoped issue to survive clearing global, got %+v", got)
+	}
+}
+
+func TestClearCurrentIssueFor(t *testing.T) {
+	s := &State{}
+	s.SetCurrentIssueFor("/repo/a", "A-1", "Repo A")
+	s.SetCurrentIssueFor("/repo/b", "B-1", "Repo B")
+
+	s.ClearCurrentIssueFor("/repo/a")
+	if got := s.CurrentIssueFor("/repo/a"); got != nil {
+		t.Errorf("expected no issue after clearing /repo/a, got %+v", got)
+	}
+	if got := s.CurrentIssueFor("/repo/b"); got == nil || got.Key != "B-1" {
+		t.Errorf("expected /repo/b to keep B-1, got %+v", got)
+	}
+}
+
+func TestScopesPersist(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	s := &State{}
+	s.SetCurrentIssueFor("/repo/a", "A-1", "Repo A")
+	if err := s.Save(); err != nil {
+		t.Fatalf("unexpected error saving state: %v", err)
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error loading state: %v", err)
+	}
+	if got := loaded.CurrentIssueFor("/repo/a"); got == nil || got.Key != "A-1" {
+		t.Errorf("expected scoped issue to persist, got %+v", got)
+	}
+}
+
+func TestScopeFor(t *testing.T) {
+	dir, _ := filepath.EvalSymlinks(t.TempDir())
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir))
+
+	scope, err := ScopeFor(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if scope != dir {
+		t.Errorf("expected working directory %q outside a repository, got %q", dir, scope)
+	}
+
+	link := filepath.Join(dir, "link")
+	target := filepath.Join(dir, "target")
+	if err := os.Mkdir(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+	scope, err = ScopeFor(link)
+	if err != nil || scope != target {
+		t.Errorf("ScopeFor() = %q, %v, want the resolved directory %q", scope, err, target)
+	}
+	if quick, err := QuickScopeFor(link); err != nil || quick != scope {
+		t.Errorf("QuickScopeFor() = %q, %v, want %q like ScopeFor", quick, err, scope)
+	}
+}
+
+func TestQuickScopeFor(t *testing.T) {
+	dir, _ := filepath.EvalSymlinks(t.TempDir())
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir))
+
+	scope, err := QuickScopeFor(dir)
+	if err != nil || scope != dir {
+		t.Errorf("QuickScopeFor() = %q, %v, want %q outside a repository", scope, err, dir)
+	}
+
+	sub := filepath.Join(dir, "repo", "sub")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "repo", ".git"), []byte("gitdir: elsewhere\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if scope, err := QuickScopeFor(sub); err != nil || scope != filepath.Join(dir, "repo") {
+		t.Errorf("QuickScopeFor() = %q, %v, want the repository root", scope, err)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	s := &State{}
+	s.SetCurrentIssue("A-1", "First")
+	s.SetCurrentIssue("B-1", "Second")
+	s.SetCurrentIssue("A-1", "First again")
+
+	if len(s.History) != 2 {
+		t.Fatalf("expected 2 history entries, got %d", len(s.History))
+	}
+	if s.History[0].Key != "A-1" || s.History[0].Summary != "First again" {
+		t.Errorf("expected reselected issue at the front, got %+v", s.History[0])
+	}
+	if s.History[1].Key != "B-1" {
+		t.Errorf("expected B-1 second, got %+v", s.History[1])
+	}
+
+	if got := s.RecentIssue("B-1"); got == nil || got.Summary != "Second" {
+		t.Errorf("expected RecentIssue to find B-1, got %+v", got)
+	}
+	if got := s.RecentIssue("C-1"); got != nil {
+		t.Errorf("expected no entry for C-1, got %+v", got)
+	}
+}
+
+func TestHistoryIsBounded(t *testing.T) {
+	s := &State{}
+	for i := 0; i < MaxHistory+5; i++ {
+		s.SetCurrentIssue(fmt.Sprintf("T-%d", i), "")
+	}
+
+	if len(s.History) != MaxHistory {
+		t.Fatalf("expected %d history entries, got %d", MaxHistory, len(s.History))
+	}
+	if s.History[0].Key != fmt.Sprintf("T-%d", MaxHistory+4) {
+		t.Errorf("expected newest entry first, got %s", s.History[0].Key)
+	}
+}
+
+func TestScopesAreBounded(t *testing.T) {
+	s := &State{}
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < MaxScopes+5; i++ {
+		scope := fmt.Sprintf("/work/dir-%d", i)
+		s.SetCurrentIssueFor(scope, fmt.Sprintf("T-%d", i), "")
+		// Distinct times keep the order independent of the clock resolution.
+		s.Scopes[scope].SelectedAt = base.Add(time.Duration(i) * time.Second)
+	}
+
+	if len(s.Scopes) != MaxScopes {
+		t.Fatalf("expected %d scopes, got %d", MaxScopes, len(s.Scopes))
+	}
+	for i := 0; i < 5; i++ {
+		if _, ok := s.Scopes[fmt.Sprintf("/work/dir-%d", i)]; ok {
+			t.Errorf("expected the oldest scope dir-%d to be dropped", i)
+		}
+	}
+	if got := s.CurrentIssueFor(fmt.Sprintf("/work/dir-%d", MaxScopes+4)); got == nil || got.Key != fmt.Sprintf("T-%d", MaxScopes+4) {
+		t.Errorf("expected the newest scope to be kept, got %+v", got)
+	}
+}
+
+func TestPreviousIssue(t *testing.T) {
+	s := &State{}
+	if s.PreviousIssue("") != nil {
+		t.Error("expected no previous issue in empty state")
+	}
+
+	s.SetCurrentIssueFor("/repo/a", "A-1", "First")
+	if s.PreviousIssue("/repo/a") != nil {
+		t.Error("expected no previous issue after a single selection")
+	}
+
+	s.SetCurrentIssueFor("/repo/a", "A-2", "Second")
+	if got := s.PreviousIssue("/repo/a"); got == nil || got.Key != "A-1" {
+		t.Errorf("expected previous issue A-1, got %+v", got)
+	}
+
+	// Switching back makes the other issue the previous one again.
+	s.SetCurrentIssueFor("/repo/a", "A-1", "First")
+	if got := s.PreviousIssue("/repo/a"); got == nil || got.Key != "A-2" {
+		t.Errorf("expected previous issue A-2, got %+v", got)
+	}
+}
+
+func TestHistoryIsScoped(t *testing.T) {
+	s := &State{}
+	s.SetCurrentIssueFor("/repo/a", "A-1", "First in A")
+	s.SetCurrentIssueFor("/repo/b", "B-1", "First in B")
+	s.SetCurrentIssueFor("/repo/a", "A-2", "Second in A")
+	s.SetCurrentIssueFor("/repo/b", "A-2", "Also in B")
+
+	if got := s.PreviousIssue("/repo/a"); got == nil || got.Key != "A-1" {
+		t.Errorf("expected previous issue A-1 in /repo/a, got %+v", got)
+	}
+	if got := s.PreviousIssue("/repo/b"); got == nil || got.Key != "B-1" {
+		t.Errorf("expected previous issue B-1 in /repo/b, got %+v", got)
+	}
+
+	tests := []struct {
+		scope string
+		want  []string
+	}{
+		{"/repo/a", []string{"A-2", "A-1"}},
+		{"/repo/b", []string{"A-2", "B-1"}},
+		{"/repo/c", nil},
+		{"", []string{"A-2", "B-1", "A-1"}},
+	}
+	for _, tt := range tests {
+		var keys []string
+		for _, issue := range s.HistoryFor(tt.scope) {
+			keys = append(keys, issue.Key)
+		}
+		if fmt.Sprint(keys) != fmt.Sprint(tt.want) {
+			t.Errorf("HistoryFor(%q) = %v, want %v", tt.scope, keys, tt.want)
+		}
+	}
+}
+
+func TestProfilesUseSeparateState(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Cleanup(func() { SetProfile("") })
+
+	s := &State{}
+	s.SetCurrentIssue("DEF-1", "Default site")
+	if err := s.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	SetProfile("client")
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded.HasCurrentIssue() {
+		t.Errorf("expected empty state for client profile, got %+v", loaded.CurrentIssue)
+	}
+	loaded.SetCurrentIssue("CLI-1", "Client site")
+	if err := loaded.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	SetProfile("default")
+	loaded, err = Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !loaded.HasCurrentIssue() || loaded.CurrentIssue.Key != "DEF-1" {
+		t.Errorf("expected default profile state to be untouched, got %+v", loaded.CurrentIssue)
+	}
+}
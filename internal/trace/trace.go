@@ -0,0 +1,202 @@
+// Package trace logs HTTP requests and responses for debugging. Credentials
+// are redacted from headers, URLs and JSON bodies before anything is written.
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Redacted replaces secret values in the log.
+const Redacted = "[REDACTED]"
+
+// maxBody limits how much of each body is logged.
+const maxBody = 16 * 1024
+
+// sensitiveHeaders are logged with their value redacted. For Authorization
+// headers the scheme is kept.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// sensitiveKeys are query parameters and JSON fields whose values are
+// redacted.
+var sensitiveKeys = []string{
+	"access_token", "refresh_token", "id_token", "client_secret", "code", "code_verifier",
+	"password", "token", "api_token", "apitoken", "secret",
+}
+
+// Transport wraps Base and writes a trace of each round trip to Out.
+type Transport struct {
+	Base   http.RoundTripper
+	Out    io.Writer
+	Bodies bool
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+func New(base http.RoundTripper, out io.Writer, bodies bool) *Transport {
+	return &Transport{Base: base, Out: out, Bodies: bodies, now: time.Now}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if t.Bodies && req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if reqBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	start := t.now()
+	resp, err := t.base().RoundTrip(req)
+	elapsed := t.now().Sub(start).Round(time.Millisecond)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--> %s %s\n", req.Method, RedactURL(req.URL))
+	writeHeaders(&b, req.Header)
+	if t.Bodies {
+		writeBody(&b, reqBody)
+	}
+
+	if err != nil {
+		fmt.Fprintf(&b, "<-- error after %s: %v\n\n", elapsed, err)
+		t.write(b.String())
+		return nil, err
+	}
+
+	fmt.Fprintf(&b, "<-- %s (%s)\n", resp.Status, elapsed)
+	writeHeaders(&b, resp.Header)
+	if t.Bodies && resp.Body != nil {
+		respBody, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+		if readErr != nil {
+			t.write(b.String() + "\n")
+			return nil, readErr
+		}
+		writeBody(&b, respBody)
+	}
+	b.WriteString("\n")
+	t.write(b.String())
+	return resp, nil
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *Transport) write(s string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	io.WriteString(t.Out, s)
+}
+
+func writeHeaders(b *strings.Builder, header http.Header) {
+	for _, name := range slices.Sorted(maps.Keys(header)) {
+		for _, value := range header[name] {
+			fmt.Fprintf(b, "    %s: %s\n", name, redactHeader(name, value))
+		}
+	}
+}
+
+func redactHeader(name, value string) string {
+	if !slices.ContainsFunc(sensitiveHeaders, func(h string) bool { return strings.EqualFold(h, name) }) {
+		return value
+	}
+	if scheme, _, ok := strings.Cut(value, " "); ok && strings.HasSuffix(name, "Authorization") {
+		return scheme + " " + Redacted
+	}
+	return Redacted
+}
+
+func writeBody(b *strings.Builder, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	text := RedactBody(body)
+	if len(text) > maxBody {
+		text = text[:maxBody] + fmt.Sprintf("... (%d bytes truncated)", len(text)-maxBody)
+	}
+	b.WriteString("    " + strings.ReplaceAll(strings.TrimRight(text, "\n"), "\n", "\n    ") + "\n")
+}
+
+// RedactURL hides the password in the user info and sensitive query
+// parameters.
+func RedactURL(u *url.URL) string {
+	redacted := *u
+	query := redacted.Query()
+	changed := false
+	for key := range query {
+		if isSensitive(key) {
+			query[key] = []string{Redacted}
+			changed = true
+		}
+	}
+	if changed {
+		redacted.RawQuery = query.Encode()
+	}
+	return redacted.Redacted()
+}
+
+// RedactBody redacts sensitive fields of JSON and form-encoded bodies. Other
+// bodies are returned unchanged.
+func RedactBody(body []byte) string {
+	var doc any
+	if err := json.Unmarshal(body, &doc); err == nil {
+		redacted, err := json.Marshal(redactJSON(doc))
+		if err == nil {
+			return string(redacted)
+		}
+	}
+
+	if form, err := url.ParseQuery(string(body)); err == nil && strings.Contains(string(body), "=") {
+		changed := false
+		for key := range form {
+			if isSensitive(key) {
+				form[key] = []string{Redacted}
+				changed = true
+			}
+		}
+		if changed {
+			return form.Encode()
+		}
+	}
+	return string(body)
+}
+
+func redactJSON(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if isSensitive(key) {
+				v[key] = Redacted
+			} else {
+				v[key] = redactJSON(field)
+			}
+		}
+	case []any:
+		for i := range v {
+			v[i] = redactJSON(v[i])
+		}
+	}
+	return value
+}
+
+func isSensitive(key string) bool {
+	return slices.Contains(sensitiveKeys, strings.ToLower(key))
+}
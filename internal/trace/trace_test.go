@@ -0,0 +1,119 @@
+package trace
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransport_RedactsSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "refresh-secret") {
+			t.Errorf("request body was altered: %s", body)
+		}
+		w.Header().Set("Set-Cookie", "session=cookie-secret")
+		w.Write([]byte(`{"access_token":"access-secret","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	var out strings.Builder
+	client := &http.Client{Transport: New(nil, &out, true)}
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/oauth/token?token=query-secret&jql=project%3DTEST",
+		strings.NewReader(`{"grant_type":"refresh_token","refresh_token":"refresh-secret"}`))
+	req.SetBasicAuth("me@example.com", "basic-secret")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "access-secret") {
+		t.Errorf("response body was altered: %s", body)
+	}
+
+	log := out.String()
+	for _, secret := range []string{"basic-secret", "bWVA", "query-secret", "refresh-secret", "access-secret", "cookie-secret"} {
+		if strings.Contains(log, secret) {
+			t.Errorf("trace contains secret %q:\n%s", secret, log)
+		}
+	}
+	for _, want := range []string{
+		"--> POST " + server.URL + "/oauth/token?",
+		"jql=project%3DTEST",
+		"Authorization: Basic [REDACTED]",
+		"Set-Cookie: [REDACTED]",
+		`"grant_type":"refresh_token"`,
+		"<-- 200 OK (",
+		`"expires_in":3600`,
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("trace is missing %q:\n%s", want, log)
+		}
+	}
+}
+
+func TestTransport_WithoutBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"key":"TEST-1"}`))
+	}))
+	defer server.Close()
+
+	var out strings.Builder
+	tr := New(nil, &out, false)
+	ticks := []time.Time{time.Unix(0, 0), time.Unix(0, int64(42*time.Millisecond))}
+	tr.now = func() time.Time {
+		now := ticks[0]
+		ticks = ticks[1:]
+		return now
+	}
+
+	resp, err := (&http.Client{Transport: tr}).Get(server.URL + "/rest/api/3/issue/TEST-1")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	log := out.String()
+	if !strings.Contains(log, "<-- 200 OK (42ms)") {
+		t.Errorf("expected status and timing, got:\n%s", log)
+	}
+	if strings.Contains(log, "TEST-1\"}") {
+		t.Errorf("body must not be logged without Bodies:\n%s", log)
+	}
+}
+
+func TestTransport_Error(t *testing.T) {
+	var out strings.Builder
+	client := &http.Client{Transport: New(nil, &out, false)}
+	if _, err := client.Get("http://127.0.0.1:1/unreachable"); err == nil {
+		t.Fatal("expected connection error")
+	}
+	if !strings.Contains(out.String(), "<-- error after") {
+		t.Errorf("expected error line, got:\n%s", out.String())
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "nested json", body: `{"a":[{"password":"x"}]}`, want: `{"a":[{"password":"[REDACTED]"}]}`},
+		{name: "form", body: "grant_type=refresh_token&client_secret=x", want: "client_secret=%5BREDACTED%5D&grant_type=refresh_token"},
+		{name: "plain text", body: "hello", want: "hello"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactBody([]byte(tt.body)); got != tt.want {
+				t.Errorf("RedactBody() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
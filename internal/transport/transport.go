@@ -0,0 +1,108 @@
+// Package transport builds the HTTP transport used for every request to
+// Jira and the OAuth servers, applying proxy and TLS settings.
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TLSVersions maps the accepted min_version values to TLS versions.
+var TLSVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Options configure the transport. Without a Proxy the HTTP_PROXY,
+// HTTPS_PROXY and NO_PROXY environment variables apply. CAFiles are PEM
+// bundles trusted in addition to the system roots; ClientCert and ClientKey
+// are a PEM key pair presented to servers that request a certificate.
+type Options struct {
+	Proxy      string
+	CAFiles    []string
+	ClientCert string
+	ClientKey  string
+	MinVersion string
+}
+
+// New returns a transport with the default timeouts and connection pooling
+// of http.DefaultTransport and the given proxy and TLS settings.
+func New(opts Options) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+
+	if opts.Proxy != "" {
+		proxyURL, err := url.Parse(opts.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", opts.Proxy)
+		}
+		t.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig, err := opts.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	t.TLSClientConfig = tlsConfig
+	return t, nil
+}
+
+// TLSConfig returns the client TLS configuration for the options.
+func (o Options) TLSConfig() (*tls.Config, error) {
+	conf := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if o.MinVersion != "" {
+		version, ok := TLSVersions[o.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported TLS min_version %q (supported: 1.0, 1.1, 1.2, 1.3)", o.MinVersion)
+		}
+		conf.MinVersion = version
+	}
+
+	if len(o.CAFiles) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		for _, file := range o.CAFiles {
+			pem, err := os.ReadFile(expandHome(file))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in CA bundle %s", file)
+			}
+		}
+		conf.RootCAs = pool
+	}
+
+	switch {
+	case o.ClientCert != "" && o.ClientKey != "":
+		cert, err := tls.LoadX509KeyPair(expandHome(o.ClientCert), expandHome(o.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	case o.ClientCert != "" || o.ClientKey != "":
+		return nil, fmt.Errorf("client certificate and key must be configured together")
+	}
+
+	return conf, nil
+}
+
+// expandHome replaces a leading "~/" with the home directory.
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
@@ -0,0 +1,181 @@
+package transport
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newClientCert creates a self-signed client certificate and returns the
+// certificate and PEM files of the pair.
+func newClientCert(t *testing.T) (*x509.Certificate, string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "jcli test client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, writePEM(t, "client.crt", "CERTIFICATE", der), writePEM(t, "client.key", "EC PRIVATE KEY", keyDER)
+}
+
+func get(t *testing.T, opts Options, url string) error {
+	t.Helper()
+	tr, err := New(opts)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	resp, err := (&http.Client{Transport: tr, Timeout: 5 * time.Second}).Get(url)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func TestNew_CABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	if err := get(t, Options{}, server.URL); err == nil {
+		t.Error("expected the test server certificate to be untrusted by default")
+	}
+
+	bundle := writePEM(t, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+	if err := get(t, Options{CAFiles: []string{bundle}}, server.URL); err != nil {
+		t.Errorf("request with CA bundle failed: %v", err)
+	}
+}
+
+func TestNew_ClientCertificate(t *testing.T) {
+	clientCert, certFile, keyFile := newClientCert(t)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 || r.TLS.PeerCertificates[0].Subject.CommonName != "jcli test client" {
+			http.Error(w, "no client certificate", http.StatusForbidden)
+		}
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	bundle := writePEM(t, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+	if err := get(t, Options{CAFiles: []string{bundle}}, server.URL); err == nil {
+		t.Error("expected the handshake to fail without a client certificate")
+	}
+	opts := Options{CAFiles: []string{bundle}, ClientCert: certFile, ClientKey: keyFile}
+	if err := get(t, opts, server.URL); err != nil {
+		t.Errorf("request with client certificate failed: %v", err)
+	}
+}
+
+func TestNew_MinVersion(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	bundle := writePEM(t, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+	if err := get(t, Options{CAFiles: []string{bundle}, MinVersion: "1.2"}, server.URL); err != nil {
+		t.Errorf("TLS 1.2 request failed: %v", err)
+	}
+	if err := get(t, Options{CAFiles: []string{bundle}, MinVersion: "1.3"}, server.URL); err == nil {
+		t.Error("expected handshake to fail when the server only supports TLS 1.2")
+	}
+}
+
+func TestNew_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := parseProxyAuth(r.Header.Get("Proxy-Authorization"))
+		if !ok || user != "alice" || pass != "s3cret" {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	proxyURL := strings.Replace(proxy.URL, "http://", "http://alice:s3cret@", 1)
+	if err := get(t, Options{Proxy: proxyURL}, "http://jira.internal/rest/api/3/myself"); err != nil {
+		t.Fatalf("request through proxy failed: %v", err)
+	}
+	if proxied != "http://jira.internal/rest/api/3/myself" {
+		t.Errorf("proxy received %q", proxied)
+	}
+}
+
+func parseProxyAuth(header string) (string, string, bool) {
+	r := &http.Request{Header: http.Header{"Authorization": {header}}}
+	return r.BasicAuth()
+}
+
+func TestOptions_TLSConfigErrors(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{name: "unknown min version", opts: Options{MinVersion: "2.0"}},
+		{name: "missing CA file", opts: Options{CAFiles: []string{"/nonexistent/ca.pem"}}},
+		{name: "CA file without certificates", opts: Options{CAFiles: []string{empty}}},
+		{name: "cert without key", opts: Options{ClientCert: "client.crt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.opts.TLSConfig(); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	if _, err := New(Options{Proxy: "://bad"}); err == nil {
+		t.Error("expected error for invalid proxy URL")
+	}
+}
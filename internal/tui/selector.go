@@ -1,12 +1,26 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/charmbracelet/huh"
 	"github.com/tutunak/jcli/internal/jira"
+	"github.com/tutunak/jcli/internal/state"
 )
 
+// ErrCancelled is returned when the user aborts a prompt with Esc or Ctrl-C.
+var ErrCancelled = errors.New("cancelled")
+
+// formError describes the failure of a form, wrapping ErrCancelled when the
+// user aborted it.
+func formError(what string, err error) error {
+	if errors.Is(err, huh.ErrUserAborted) {
+		return fmt.Errorf("%s %w", what, ErrCancelled)
+	}
+	return fmt.Errorf("%s failed: %w", what, err)
+}
+
 type Selector struct{}
 
 func NewSelector() *Selector {
@@ -42,7 +56,7 @@ func (s *Selector) SelectIssue(issues []jira.Issue) (*jira.Issue, error) {
 	)
 
 	if err := form.Run(); err != nil {
-		return nil, fmt.Errorf("selection cancelled: %w", err)
+		return nil, formError("selection", err)
 	}
 
 	selected, ok := issueMap[selectedKey]
@@ -53,6 +67,39 @@ func (s *Selector) SelectIssue(issues []jira.Issue) (*jira.Issue, error) {
 	return selected, nil
 }
 
+func (s *Selector) SelectRecent(issues []state.CurrentIssue) (*state.CurrentIssue, error) {
+	if len(issues) == 0 {
+		return nil, fmt.Errorf("no recent issues available to select")
+	}
+
+	options := make([]huh.Option[int], len(issues))
+	for i, issue := range issues {
+		label := fmt.Sprintf("%s: %s", issue.Key, issue.Summary)
+		if len(label) > 60 {
+			label = label[:57] + "..."
+		}
+		label += "  (" + issue.SelectedAt.Format("2006-01-02 15:04") + ")"
+		options[i] = huh.NewOption(label, i)
+	}
+
+	var selected int
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[int]().
+				Title("Switch to a recent issue").
+				Options(options...).
+				Value(&selected),
+		),
+	)
+
+	if err := form.Run(); err != nil {
+		return nil, formError("selection", err)
+	}
+
+	return &issues[selected], nil
+}
+
 func (s *Selector) PromptCredentials() (url, email, token string, err error) {
 	form := huh.NewForm(
 		huh.NewGroup(
@@ -91,8 +138,217 @@ func (s *Selector) PromptCredentials() (url, email, token string, err error) {
 	)
 
 	if err := form.Run(); err != nil {
-		return "", "", "", fmt.Errorf("credentials input cancelled: %w", err)
+		return "", "", "", formError("credentials input", err)
 	}
 
 	return url, email, token, nil
 }
+
+// PromptServerCredentials asks for the URL and Personal Access Token of a
+// Jira Server or Data Center instance.
+func (s *Selector) PromptServerCredentials() (url, token string, err error) {
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Jira URL").
+				Description("e.g., https://jira.company.com").
+				Value(&url).
+				Validate(func(str string) error {
+					if str == "" {
+						return fmt.Errorf("URL is required")
+					}
+					return nil
+				}),
+			huh.NewInput().
+				Title("Personal Access Token").
+				Description("Create under Profile > Personal Access Tokens in Jira").
+				EchoMode(huh.EchoModePassword).
+				Value(&token).
+				Validate(func(str string) error {
+					if str == "" {
+						return fmt.Errorf("personal access token is required")
+					}
+					return nil
+				}),
+		),
+	)
+
+	if err := form.Run(); err != nil {
+		return "", "", formError("credentials input", err)
+	}
+
+	return url, token, nil
+}
+
+// PromptTokenStorage asks whether the API token should be encrypted with a
+// passphrase instead of being stored in plaintext in config.yaml.
+func (s *Selector) PromptTokenStorage() (encrypt bool, err error) {
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[bool]().
+				Title("Where should the API token be stored?").
+				Options(
+					huh.NewOption("Encrypt with passphrase (credentials.enc)", true),
+					huh.NewOption("Plaintext in config.yaml", false),
+				).
+				Value(&encrypt),
+		),
+	)
+
+	if err := form.Run(); err != nil {
+		return false, formError("storage selection", err)
+	}
+
+	return encrypt, nil
+}
+
+// PromptPassphrase asks for the passphrase of the encrypted token store;
+// confirm asks for it twice when a new passphrase is being chosen.
+func (s *Selector) PromptPassphrase(confirm bool) (string, error) {
+	var passphrase, repeated string
+
+	fields := []huh.Field{
+		huh.NewInput().
+			Title("Passphrase").
+			Description("Unlocks the encrypted API token store").
+			EchoMode(huh.EchoModePassword).
+			Value(&passphrase).
+			Validate(func(str string) error {
+				if str == "" {
+					return fmt.Errorf("passphrase is required")
+				}
+				return nil
+			}),
+	}
+	if confirm {
+		fields = append(fields, huh.NewInput().
+			Title("Repeat passphrase").
+			EchoMode(huh.EchoModePassword).
+			Value(&repeated).
+			Validate(func(str string) error {
+				if str != passphrase {
+					return fmt.Errorf("passphrases do not match")
+				}
+				return nil
+			}))
+	}
+
+	if err := huh.NewForm(huh.NewGroup(fields...)).Run(); err != nil {
+		return "", formError("passphrase input", err)
+	}
+
+	return passphrase, nil
+}
+
+// SelectProject asks for the default project; current is preselected.
+func (s *Selector) SelectProject(projects []jira.Project, current string) (string, error) {
+	if len(projects) == 0 {
+		return "", fmt.Errorf("no projects available to select")
+	}
+
+	key := current
+	options := make([]huh.Option[string], len(projects))
+	for i, project := range projects {
+		options[i] = huh.NewOption(fmt.Sprintf("%s: %s", project.Key, project.Name), project.Key)
+	}
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Default project").
+				Description("Type / to filter").
+				Options(options...).
+				Value(&key),
+		),
+	)
+	if err := form.Run(); err != nil {
+		return "", formError("project selection", err)
+	}
+	return key, nil
+}
+
+// SelectStatus asks for the status that filters the issues offered by
+// 'issue select'; current is preselected.
+func (s *Selector) SelectStatus(statuses []jira.Status, current string) (string, error) {
+	if len(statuses) == 0 {
+		return "", fmt.Errorf("no statuses available to select")
+	}
+
+	name := current
+	options := make([]huh.Option[string], len(statuses))
+	for i, status := range statuses {
+		options[i] = huh.NewOption(status.Name, status.Name)
+	}
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Default status filter").
+				Description("'jcli issue select' offers the issues in this status").
+				Options(options...).
+				Value(&name),
+		),
+	)
+	if err := form.Run(); err != nil {
+		return "", formError("status selection", err)
+	}
+	return name, nil
+}
+
+// PromptBranchTemplate asks for the branch name template; an empty answer
+// keeps the default.
+func (s *Selector) PromptBranchTemplate(current string) (string, error) {
+	template := current
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Branch name template").
+				Description("Placeholders {key}, {summary} and {random}; leave empty for {key}-{summary}-{random}").
+				Value(&template),
+		),
+	)
+	if err := form.Run(); err != nil {
+		return "", formError("template input", err)
+	}
+	return template, nil
+}
+
+// SelectShell asks which shell to set up completion and the prompt segment
+// for, returning "" to skip.
+func (s *Selector) SelectShell(shells []string) (string, error) {
+	var shell string
+	options := []huh.Option[string]{huh.NewOption("Skip", "")}
+	for _, name := range shells {
+		options = append(options, huh.NewOption(name, name))
+	}
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Set up shell completion and the prompt segment?").
+				Options(options...).
+				Value(&shell),
+		),
+	)
+	if err := form.Run(); err != nil {
+		return "", formError("shell selection", err)
+	}
+	return shell, nil
+}
+
+// Confirm asks a yes or no question.
+func (s *Selector) Confirm(title, description string) (bool, error) {
+	var ok bool
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title(title).
+				Description(description).
+				Value(&ok),
+		),
+	)
+	if err := form.Run(); err != nil {
+		return false, formError("confirmation", err)
+	}
+	return ok, nil
+}
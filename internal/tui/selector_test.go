@@ -1,8 +1,10 @@
 package tui
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/charmbracelet/huh"
 	"github.com/tutunak/jcli/internal/jira"
 )
 
@@ -21,6 +23,36 @@ func TestSelectIssue_EmptyList(t *testing.T) {
 	}
 }
 
+func TestSelectRecent_EmptyList(t *testing.T) {
+	s := NewSelector()
+	_, err := s.SelectRecent(nil)
+	if err == nil {
+		t.Error("expected error for empty history")
+	}
+}
+
 // Note: Interactive tests for SelectIssue and PromptCredentials
 // would require mocking the terminal, which is complex.
 // These are better tested through integration tests or manual testing.
+
+func TestFormError(t *testing.T) {
+	err := formError("selection", huh.ErrUserAborted)
+	if !errors.Is(err, ErrCancelled) || err.Error() != "selection cancelled" {
+		t.Errorf("aborted form: got %v", err)
+	}
+
+	err = formError("selection", errors.New("no TTY"))
+	if errors.Is(err, ErrCancelled) || err.Error() != "selection failed: no TTY" {
+		t.Errorf("failed form: got %v", err)
+	}
+}
+
+func TestSelectProjectAndStatus_EmptyList(t *testing.T) {
+	s := NewSelector()
+	if _, err := s.SelectProject(nil, ""); err == nil {
+		t.Error("expected error for empty project list")
+	}
+	if _, err := s.SelectStatus(nil, ""); err == nil {
+		t.Error("expected error for empty status list")
+	}
+}
@@ -3,14 +3,23 @@
 package integration
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -26,9 +35,19 @@ func TestCLIIntegration(t *testing.T) {
 	// Setup test directories
 	configDir := t.TempDir()
 	stateDir := t.TempDir()
+	cacheDir := t.TempDir()
 
 	// Create mock Jira server
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	jiraHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// API v2 is the Server/Data Center API, which takes PAT bearer auth.
+		if strings.HasPrefix(r.URL.Path, "/rest/api/2/") {
+			if r.Header.Get("Authorization") != "Bearer server-pat" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			r.URL.Path = "/rest/api/3/" + strings.TrimPrefix(r.URL.Path, "/rest/api/2/")
+		}
+
 		switch {
 		case strings.HasPrefix(r.URL.Path, "/rest/api/3/search"):
 			json.NewEncoder(w).Encode(map[string]interface{}{
@@ -37,8 +56,10 @@ func TestCLIIntegration(t *testing.T) {
 					{
 						"key": "TEST-1",
 						"fields": map[string]interface{}{
-							"summary": "First test issue",
-							"status":  map[string]string{"name": "In Progress"},
+							"summary":           "First test issue",
+							"status":            map[string]string{"name": "In Progress"},
+							"issuetype":         map[string]string{"name": "Bug"},
+							"customfield_10016": 5,
 						},
 					},
 					{
@@ -50,6 +71,27 @@ func TestCLIIntegration(t *testing.T) {
 					},
 				},
 			})
+		case r.URL.Path == "/rest/api/3/myself":
+			json.NewEncoder(w).Encode(map[string]string{"displayName": "Test User"})
+		case r.URL.Path == "/rest/api/3/project/TEST":
+			json.NewEncoder(w).Encode(map[string]string{"key": "TEST", "name": "Test"})
+		case r.URL.Path == "/rest/api/3/project/TEST/statuses":
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"name": "Task", "statuses": []map[string]string{{"name": "To Do"}, {"name": "In Progress"}}},
+			})
+		case r.URL.Path == "/rest/api/3/project/search":
+			startAt, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"startAt":    startAt,
+				"maxResults": 1,
+				"isLast":     startAt == 1,
+				"values":     []map[string]string{{"key": fmt.Sprintf("P%d", startAt)}},
+			})
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/comment"):
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": "100", "received": body})
 		case strings.HasPrefix(r.URL.Path, "/rest/api/3/issue/"):
 			key := strings.TrimPrefix(r.URL.Path, "/rest/api/3/issue/")
 			json.NewEncoder(w).Encode(map[string]interface{}{
@@ -57,24 +99,53 @@ func TestCLIIntegration(t *testing.T) {
 				"fields": map[string]interface{}{
 					"summary": "Test issue " + key,
 					"status":  map[string]string{"name": "In Progress"},
+					"description": map[string]interface{}{
+						"type": "doc",
+						"content": []map[string]interface{}{
+							{"type": "paragraph", "content": []map[string]string{{"type": "text", "text": "Steps to reproduce"}}},
+						},
+					},
 				},
 			})
 		default:
 			http.NotFound(w, r)
 		}
-	}))
+	})
+	server := httptest.NewServer(jiraHandler)
 	defer server.Close()
 
-	// Helper to run CLI
-	runCLI := func(args ...string) (string, error) {
+	// Helpers to run CLI
+	runCLIEnv := func(dir string, env []string, args ...string) (string, error) {
 		cmd := exec.Command(tmpBin, args...)
+		cmd.Dir = dir
 		cmd.Env = append(os.Environ(),
 			"XDG_CONFIG_HOME="+configDir,
 			"XDG_STATE_HOME="+stateDir,
+			"XDG_CACHE_HOME="+cacheDir,
 		)
+		cmd.Env = append(cmd.Env, env...)
 		output, err := cmd.CombinedOutput()
 		return string(output), err
 	}
+	runCLIIn := func(dir string, args ...string) (string, error) {
+		return runCLIEnv(dir, nil, args...)
+	}
+	runCLI := func(args ...string) (string, error) {
+		return runCLIIn("", args...)
+	}
+	initRepo := func(t *testing.T, branch string) string {
+		t.Helper()
+		repo := t.TempDir()
+		for _, args := range [][]string{
+			{"init", "-q", "-b", "main"},
+			{"checkout", "-q", "-b", branch},
+		} {
+			if out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+				t.Fatalf("git %v failed: %v\n%s", args, err, out)
+			}
+		}
+		return repo
+	}
 
 	// Test version
 	t.Run("version", func(t *testing.T) {
@@ -151,6 +222,38 @@ func TestCLIIntegration(t *testing.T) {
 		}
 	})
 
+	// Test generic config get/set/unset/list
+	t.Run("config get set unset list", func(t *testing.T) {
+		if output, err := runCLI("config", "set", "queries.mine", "assignee = currentUser()"); err != nil {
+			t.Fatalf("config set failed: %v\n%s", err, output)
+		}
+		output, err := runCLI("config", "get", "queries.mine")
+		if err != nil || strings.TrimSpace(output) != "assignee = currentUser()" {
+			t.Errorf("config get returned %q, %v", output, err)
+		}
+
+		output, err = runCLI("config", "list")
+		if err != nil || !strings.Contains(output, "defaults.project = TEST") || !strings.Contains(output, "queries.mine = ") {
+			t.Errorf("unexpected config list: %v\n%s", err, output)
+		}
+
+		if output, err := runCLI("config", "unset", "queries.mine"); err != nil {
+			t.Fatalf("config unset failed: %v\n%s", err, output)
+		}
+		if output, err := runCLI("config", "get", "queries.mine"); err == nil {
+			t.Errorf("expected unset key to be gone, got: %s", output)
+		}
+
+		output, err = runCLI("config", "set", "jira.deployment", "onprem")
+		if err == nil || !strings.Contains(output, "allowed: cloud, server, datacenter") {
+			t.Errorf("expected enum validation error, got: %v\n%s", err, output)
+		}
+		output, err = runCLI("config", "set", "jira.url", "not-a-url")
+		if err == nil || !strings.Contains(output, "not an absolute http(s) URL") {
+			t.Errorf("expected URL validation error, got: %v\n%s", err, output)
+		}
+	})
+
 	// Setup full config for issue commands
 	configFile := filepath.Join(configDir, "jcli", "config.yaml")
 	cfg := map[string]interface{}{
@@ -201,14 +304,887 @@ func TestCLIIntegration(t *testing.T) {
 		}
 	})
 
+	t.Run("global flags", func(t *testing.T) {
+		output, err := runCLI("--project", "FLAG", "--status", "Done", "config", "show", "--origin")
+		if err != nil {
+			t.Fatalf("config show failed: %v\n%s", err, output)
+		}
+		if !strings.Contains(output, "Project: FLAG  [flag --project]") || !strings.Contains(output, "Status: Done  [flag --status]") {
+			t.Errorf("expected flag overrides in config show, got: %s", output)
+		}
+		if output, _ := runCLI("config", "get", "defaults.project"); strings.TrimSpace(output) != "TEST" {
+			t.Errorf("flag override was persisted, got project %q", output)
+		}
+
+		output, err = runCLI("-q", "issue", "select", "TEST-123")
+		if err != nil || strings.TrimSpace(output) != "" {
+			t.Errorf("expected no output with --quiet, got: %v\n%s", err, output)
+		}
+
+		output, err = runCLI("issue", "select", "TEST-123", "-o", "json")
+		if err != nil || !strings.Contains(output, `"description": "Steps to reproduce"`) {
+			t.Errorf("expected the description in issue select --output json, got: %v\n%s", err, output)
+		}
+
+		output, err = runCLI("issue", "current", "--output", "json")
+		if err != nil {
+			t.Fatalf("issue current --output json failed: %v\n%s", err, output)
+		}
+		var current struct {
+			Version int
+			Kind    string
+			Data    struct{ Key, Summary string }
+		}
+		if err := json.Unmarshal([]byte(output), &current); err != nil || current.Kind != "issue" || current.Data.Key != "TEST-123" {
+			t.Errorf("unexpected JSON output: %v\n%s", err, output)
+		}
+		if output, err := runCLI("-o", "xml", "issue", "current"); err == nil || !strings.Contains(output, "unknown output format") {
+			t.Errorf("expected error for unknown output format, got: %v\n%s", err, output)
+		}
+
+		altConfig := filepath.Join(t.TempDir(), "alt.yaml")
+		if output, err := runCLI("--config", altConfig, "config", "project", "ALT"); err != nil {
+			t.Fatalf("config project with --config failed: %v\n%s", err, output)
+		}
+		if output, _ := runCLIEnv("", []string{"JCLI_CONFIG=" + altConfig}, "config", "get", "defaults.project"); strings.TrimSpace(output) != "ALT" {
+			t.Errorf("expected project from %s, got %q", altConfig, output)
+		}
+		if output, _ := runCLI("config", "get", "defaults.project"); strings.TrimSpace(output) != "TEST" {
+			t.Errorf("--config changed the default config file, got project %q", output)
+		}
+	})
+
+	t.Run("output formats", func(t *testing.T) {
+		output, err := runCLI("issue", "current", "-o", "yaml")
+		if err != nil {
+			t.Fatalf("issue current -o yaml failed: %v\n%s", err, output)
+		}
+		var doc struct {
+			Version int
+			Kind    string
+			Data    map[string]any
+		}
+		if err := yaml.Unmarshal([]byte(output), &doc); err != nil || doc.Version != 1 || doc.Data["key"] != "TEST-123" {
+			t.Errorf("unexpected YAML output: %v\n%s", err, output)
+		}
+
+		output, err = runCLI("issue", "current", "--template", "{{.key}}: {{.summary}}")
+		if err != nil || strings.TrimSpace(output) != "TEST-123: Test issue TEST-123" {
+			t.Errorf("unexpected template output: %v\n%q", err, output)
+		}
+
+		output, err = runCLI("issue", "select", "TEST-123", "-o", "tsv")
+		if err != nil || !strings.HasPrefix(output, "TEST-123\tTest issue TEST-123\t\t") {
+			t.Errorf("unexpected TSV output: %v\n%q", err, output)
+		}
+
+		output, err = runCLI("issue", "branch", "-o", "json")
+		if err != nil || !strings.Contains(output, `"kind": "branch"`) || !strings.Contains(output, `"name": "TEST-123-`) {
+			t.Errorf("unexpected branch JSON: %v\n%s", err, output)
+		}
+
+		output, err = runCLI("config", "show", "-o", "table")
+		if err != nil {
+			t.Fatalf("config show -o table failed: %v\n%s", err, output)
+		}
+		if !strings.HasPrefix(output, "KEY") || !strings.Contains(output, "defaults.project") || strings.Contains(output, "test-token") {
+			t.Errorf("unexpected config table: %s", output)
+		}
+
+		output, err = runCLI("config", "get", "jira.api_token", "-o", "json")
+		if err != nil || !strings.Contains(output, `"value": "test****"`) {
+			t.Errorf("expected masked token in JSON, got: %v\n%s", err, output)
+		}
+
+		if output, err := runCLI("issue", "current", "--template", "{{.key"); err == nil || !strings.Contains(output, "invalid template") {
+			t.Errorf("expected template parse error, got: %v\n%s", err, output)
+		}
+	})
+
+	// Test issue list
+	t.Run("issue list", func(t *testing.T) {
+		output, err := runCLIEnv("", []string{"COLUMNS=80", "FORCE_HYPERLINK=0"}, "issue", "list", "--columns", "key,type,status,summary")
+		if err != nil {
+			t.Fatalf("issue list failed: %v\n%s", err, output)
+		}
+		lines := strings.Split(strings.TrimSpace(output), "\n")
+		if len(lines) != 3 || !strings.HasPrefix(lines[0], "KEY     TYPE  STATUS       SUMMARY") || !strings.HasPrefix(lines[1], "TEST-1  Bug   In Progress  First test issue") {
+			t.Errorf("unexpected issue list:\n%s", output)
+		}
+
+		output, err = runCLIEnv("", []string{"FORCE_HYPERLINK=1"}, "issue", "list", "--columns", "key")
+		if err != nil || !strings.Contains(output, "\x1b]8;;"+server.URL+"/browse/TEST-2\x1b\\TEST-2") {
+			t.Errorf("expected hyperlinked keys: %v\n%q", err, output)
+		}
+
+		output, err = runCLI("issue", "list", "--columns", "key,customfield_10016", "-o", "tsv")
+		if err != nil || output != "TEST-1\t5\nTEST-2\t\n" {
+			t.Errorf("unexpected TSV output: %v\n%q", err, output)
+		}
+
+		output, err = runCLI("issue", "list", "--columns", "key,customfield_10016", "-o", "json")
+		if err != nil || !strings.Contains(output, `"kind": "issue_list"`) || !strings.Contains(output, `"customfield_10016": "5"`) || !strings.Contains(output, `"url": "`+server.URL+`/browse/TEST-1"`) {
+			t.Errorf("unexpected JSON output: %v\n%s", err, output)
+		}
+
+		if output, err := runCLI("issue", "list", "--sort", "labels"); err == nil || !strings.Contains(output, `unknown column "labels"`) {
+			t.Errorf("expected invalid sort error, got: %v\n%s", err, output)
+		}
+	})
+
+	// Test shell completion
+	t.Run("completion", func(t *testing.T) {
+		for _, shell := range []string{"bash", "zsh", "fish"} {
+			output, err := runCLI("completion", shell)
+			if err != nil || !strings.Contains(output, "jcli") {
+				t.Errorf("completion %s failed: %v\n%s", shell, err, output)
+			}
+		}
+
+		// issue list above cached the search results
+		output, err := runCLI("__complete", "issue", "select", "test-")
+		if err != nil || !strings.Contains(output, "TEST-1\tFirst test issue") || !strings.Contains(output, "TEST-2\tSecond test issue") {
+			t.Errorf("expected cached issue keys, got: %v\n%s", err, output)
+		}
+
+		output, err = runCLI("__complete", "issue", "switch", "")
+		if err != nil || !strings.Contains(output, "-\tprevious issue") || strings.Contains(output, "TEST-2") {
+			t.Errorf("expected history completions, got: %v\n%s", err, output)
+		}
+
+		output, err = runCLI("__complete", "config", "status", "In")
+		if err != nil || !strings.Contains(output, "In Progress") || strings.Contains(output, "To Do") {
+			t.Errorf("expected project statuses, got: %v\n%s", err, output)
+		}
+
+		output, err = runCLI("__complete", "issue", "list", "--sort", "key,-up")
+		if err != nil || !strings.Contains(output, "key,-updated") {
+			t.Errorf("expected sort fields, got: %v\n%s", err, output)
+		}
+	})
+
+	// Test the prompt segment
+	t.Run("prompt", func(t *testing.T) {
+		repo := initRepo(t, "main")
+		if output, err := runCLIIn(repo, "issue", "select", "TEST-55"); err != nil {
+			t.Fatalf("issue select failed: %v\n%s", err, output)
+		}
+		sub := filepath.Join(repo, "sub")
+		if err := os.Mkdir(sub, 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		output, err := runCLIIn(sub, "prompt", "--format", "{key} {status} {summary}", "--max-summary", "8")
+		if err != nil || output != "TEST-55 In Progress Test is…\n" {
+			t.Errorf("unexpected prompt: %v\n%q", err, output)
+		}
+
+		output, err = runCLI("prompt", "snippet", "starship")
+		if err != nil || !strings.Contains(output, "[custom.jira]") {
+			t.Errorf("unexpected snippet: %v\n%s", err, output)
+		}
+	})
+
+	// Test external plugins
+	t.Run("plugins", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("plugin script needs a POSIX shell")
+		}
+		pluginDir := t.TempDir()
+		script := "#!/bin/sh\necho \"args=$*\"\necho \"issue=$JCLI_ISSUE_KEY url=$JCLI_JIRA_URL profile=$JCLI_PROFILE scheme=$JCLI_AUTH_SCHEME\"\n$JCLI_TOKEN_COMMAND\nexit 3\n"
+		if err := os.WriteFile(filepath.Join(pluginDir, "jcli-standup"), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+		path := "PATH=" + pluginDir + string(os.PathListSeparator) + os.Getenv("PATH")
+
+		if output, err := runCLI("issue", "select", "TEST-123"); err != nil {
+			t.Fatalf("issue select failed: %v\n%s", err, output)
+		}
+		output, err := runCLIEnv("", []string{path}, "standup", "--since", "monday")
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+			t.Errorf("expected the plugin's exit code 3, got %v", err)
+		}
+		want := "args=--since monday\nissue=TEST-123 url=" + server.URL + " profile=default scheme=basic\ntest-token\n"
+		if output != want {
+			t.Errorf("plugin output = %q, want %q", output, want)
+		}
+
+		output, err = runCLIEnv("", []string{path}, "--profile", "default", "-q", "standup")
+		if !errors.As(err, &exitErr) || !strings.Contains(output, "args=\nissue=TEST-123") {
+			t.Errorf("plugin after global flags failed: %v\n%s", err, output)
+		}
+
+		output, err = runCLIEnv("", []string{path}, "plugin", "list", "-o", "json")
+		if err != nil || !strings.Contains(output, `"kind": "plugin_list"`) || !strings.Contains(output, `"name": "standup"`) {
+			t.Errorf("unexpected plugin list: %v\n%s", err, output)
+		}
+
+		if output, err := runCLIEnv("", []string{path}, "nonexistent"); err == nil || !strings.Contains(output, `unknown command "nonexistent"`) {
+			t.Errorf("expected unknown command error, got: %v\n%s", err, output)
+		}
+	})
+
+	// Test aliases with argument substitution, recursion and shell aliases
+	t.Run("aliases", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("shell aliases need a POSIX shell")
+		}
+		aliases := map[string]string{
+			"co":      "issue select $1",
+			"cur":     "issue current",
+			"loop":    "again",
+			"again":   "loop",
+			"hello":   "!echo \"hello $1 $JCLI_ISSUE_KEY\"; exit 4",
+			"version": "issue list",
+		}
+		for name, definition := range aliases {
+			if output, err := runCLI("config", "set", "aliases."+name, definition); err != nil {
+				t.Fatalf("config set aliases.%s failed: %v\n%s", name, err, output)
+			}
+			defer runCLI("config", "unset", "aliases."+name)
+		}
+
+		if output, err := runCLI("co", "TEST-9"); err != nil || !strings.Contains(output, "TEST-9") {
+			t.Errorf("co alias failed: %v\n%s", err, output)
+		}
+		if output, err := runCLI("cur"); err != nil || !strings.Contains(output, "Current issue: TEST-9") {
+			t.Errorf("cur alias failed: %v\n%s", err, output)
+		}
+		if output, err := runCLI("co"); err == nil || !strings.Contains(output, `alias "co": needs at least 1 argument(s), got 0`) {
+			t.Errorf("expected missing argument error, got: %v\n%s", err, output)
+		}
+		if output, err := runCLI("loop"); err == nil || !strings.Contains(output, "alias loop: loop -> again -> loop") {
+			t.Errorf("expected alias loop error, got: %v\n%s", err, output)
+		}
+
+		output, err := runCLI("hello", "world")
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 4 {
+			t.Errorf("expected the shell alias's exit code 4, got %v", err)
+		}
+		if output != "hello world TEST-9\n" {
+			t.Errorf("shell alias output = %q", output)
+		}
+
+		// Global flags may come first; --config picks the file the aliases
+		// are read from.
+		if output, err := runCLI("-p", "OTHER", "--no-color", "cur"); err != nil || !strings.Contains(output, "Current issue: TEST-9") {
+			t.Errorf("alias after global flags failed: %v\n%s", err, output)
+		}
+		otherConfig := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(otherConfig, []byte("aliases:\n  v: version\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if output, err := runCLI("--config", otherConfig, "v"); err != nil || !strings.Contains(output, "jcli version") {
+			t.Errorf("alias from --config file failed: %v\n%s", err, output)
+		}
+		if output, err := runCLI("--config="+otherConfig, "cur"); err == nil || !strings.Contains(output, `unknown command "cur"`) {
+			t.Errorf("expected aliases of the default config to be ignored with --config, got: %v\n%s", err, output)
+		}
+
+		if output, err := runCLI("config", "show", "--origin"); err != nil || !strings.Contains(output, "  cur: issue current  ["+configFile+"]") {
+			t.Errorf("expected the cur alias to come from %s: %v\n%s", configFile, err, output)
+		}
+
+		// Built-in commands can't be overridden.
+		if output, err := runCLI("version"); err != nil || !strings.Contains(output, "jcli version") {
+			t.Errorf("version should not be aliased: %v\n%s", err, output)
+		}
+	})
+
+	// Test raw API requests with fields, pagination and filters
+	t.Run("api", func(t *testing.T) {
+		output, err := runCLI("api", "get", "myself")
+		if err != nil || output != "{\n  \"displayName\": \"Test User\"\n}\n" {
+			t.Errorf("api GET myself: %v\n%q", err, output)
+		}
+
+		output, err = runCLI("api", "GET", "project/search", "--paginate", "--filter", ".values[].key")
+		if err != nil || output != "P0\nP1\n" {
+			t.Errorf("api --paginate: %v\n%q", err, output)
+		}
+
+		output, err = runCLI("api", "POST", "issue/TEST-1/comment", "-f", "body[text]=Hello", "-F", "public=true", "--filter", ".received")
+		if err != nil || !strings.Contains(output, `"text": "Hello"`) || !strings.Contains(output, `"public": true`) {
+			t.Errorf("api POST with fields: %v\n%s", err, output)
+		}
+
+		cmd := exec.Command(tmpBin, "api", "POST", "issue/TEST-1/comment", "--input", "-", "--filter", ".received.n")
+		cmd.Env = append(os.Environ(), "XDG_CONFIG_HOME="+configDir, "XDG_STATE_HOME="+stateDir, "XDG_CACHE_HOME="+cacheDir)
+		cmd.Stdin = strings.NewReader(`{"n": 42}`)
+		if out, err := cmd.CombinedOutput(); err != nil || string(out) != "42\n" {
+			t.Errorf("api --input -: %v\n%q", err, out)
+		}
+
+		output, err = runCLI("api", "GET", "/rest/nowhere")
+		if err == nil || !strings.Contains(output, "status 404") {
+			t.Errorf("expected a 404 error, got: %v\n%s", err, output)
+		}
+		if output, err := runCLI("api", "POST", "myself", "--paginate"); err == nil || !strings.Contains(output, "--paginate only works with GET") {
+			t.Errorf("expected a --paginate error, got: %v\n%s", err, output)
+		}
+	})
+
+	// Test the exit codes of error classes
+	t.Run("exit codes", func(t *testing.T) {
+		badConfig := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(badConfig, []byte("jira: [broken"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		emptyState := "XDG_STATE_HOME=" + t.TempDir()
+
+		// Profiles whose client can't be built: OAuth without a login, and
+		// proxy and TLS settings that don't work.
+		otherDir := t.TempDir()
+		writeConfig := func(name, jira string) []string {
+			path := filepath.Join(otherDir, name+".yaml")
+			content := "jira:\n  url: https://example.atlassian.net\n" + jira
+			if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			return []string{"XDG_CONFIG_HOME=" + otherDir, "JCLI_CONFIG=" + path}
+		}
+		basicAuth := "  email: test@example.com\n  api_token: token\n"
+		oauthEnv := writeConfig("oauth", "  auth: oauth\n  oauth:\n    client_id: client\n")
+		proxyEnv := writeConfig("proxy", basicAuth+"  proxy: \"http://[bad\"\n")
+		caEnv := writeConfig("ca", basicAuth+"  tls:\n    ca_files: ["+filepath.Join(otherDir, "missing.pem")+"]\n")
+		certEnv := writeConfig("cert", basicAuth+"  tls:\n    client_cert: "+filepath.Join(otherDir, "missing.pem")+
+			"\n    client_key: "+filepath.Join(otherDir, "missing.key")+"\n")
+
+		for _, tc := range []struct {
+			name string
+			env  []string
+			args []string
+			code int
+			want string
+		}{
+			{name: "usage", args: []string{"issue", "list", "--bogus"}, code: 2, want: "Run 'jcli issue list --help' for usage."},
+			{name: "extra argument", args: []string{"init", "extra"}, code: 2, want: "Run 'jcli init --help' for usage."},
+			{name: "unknown subcommand", args: []string{"issue", "bogus"}, code: 2, want: "unknown issue command: bogus"},
+			{name: "config", env: []string{"JCLI_CONFIG=" + badConfig}, args: []string{"issue", "list"}, code: 3, want: "failed to parse config file"},
+			{name: "unknown key", args: []string{"config", "set", "nope", "x"}, code: 3, want: "unknown config key"},
+			{name: "invalid value", args: []string{"config", "set", "jira.url", "notaurl"}, code: 3, want: "invalid value for jira.url"},
+			{name: "invalid proxy", env: proxyEnv, args: []string{"api", "GET", "myself"}, code: 3, want: `invalid proxy URL "http://[bad"`},
+			{name: "unreadable CA bundle", env: caEnv, args: []string{"api", "GET", "myself"}, code: 3, want: "failed to read CA bundle"},
+			{name: "unreadable client certificate", env: certEnv, args: []string{"api", "GET", "myself"}, code: 3, want: "failed to load client certificate"},
+			{name: "not logged in", env: oauthEnv, args: []string{"api", "GET", "myself"}, code: 4, want: "not logged in to https://example.atlassian.net"},
+			{name: "auth token not logged in", env: oauthEnv, args: []string{"auth", "token"}, code: 4, want: "not logged in"},
+			{name: "not found", args: []string{"api", "GET", "/rest/nowhere"}, code: 5, want: "status 404"},
+			{name: "no issue", env: []string{emptyState}, args: []string{"issue", "current", "-o", "json"}, code: 5, want: "no issue selected"},
+			{name: "no previous issue", env: []string{emptyState}, args: []string{"issue", "switch", "-"}, code: 5, want: "no previous issue"},
+			{name: "network", env: []string{"JIRA_URL=http://127.0.0.1:1"}, args: []string{"api", "GET", "myself"}, code: 6, want: "Check the connection to Jira"},
+		} {
+			output, err := runCLIEnv("", tc.env, tc.args...)
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) || exitErr.ExitCode() != tc.code {
+				t.Errorf("%s: expected exit code %d, got %v\n%s", tc.name, tc.code, err, output)
+			}
+			if !strings.Contains(output, "Error: ") || !strings.Contains(output, tc.want) {
+				t.Errorf("%s: unexpected output:\n%s", tc.name, output)
+			}
+		}
+	})
+
+	// Test issue current detected from the git branch
+	t.Run("issue current from branch", func(t *testing.T) {
+		repo := initRepo(t, "feature/TEST-77-detected")
+
+		output, err := runCLIIn(repo, "issue", "current", "--from-branch")
+		if err != nil {
+			t.Fatalf("issue current --from-branch failed: %v\n%s", err, output)
+		}
+		if !strings.Contains(output, "Current issue: TEST-77") {
+			t.Errorf("unexpected output: %s", output)
+		}
+	})
+
+	// Test per-repository selections with a global fallback
+	t.Run("issue current per repository", func(t *testing.T) {
+		repoA := initRepo(t, "main-a")
+		repoB := initRepo(t, "main-b")
+		repoC := initRepo(t, "main-c")
+
+		if output, err := runCLIIn(repoA, "issue", "select", "TEST-1"); err != nil {
+			t.Fatalf("issue select in repo A failed: %v\n%s", err, output)
+		}
+		if output, err := runCLIIn(repoB, "issue", "select", "TEST-2"); err != nil {
+			t.Fatalf("issue select in repo B failed: %v\n%s", err, output)
+		}
+		if output, err := runCLIIn(repoB, "issue", "select", "TEST-3", "--global"); err != nil {
+			t.Fatalf("issue select --global failed: %v\n%s", err, output)
+		}
+
+		for _, tc := range []struct {
+			dir  string
+			args []string
+			want string
+		}{
+			{repoA, []string{"issue", "current"}, "Current issue: TEST-1"},
+			{repoB, []string{"issue", "current"}, "Current issue: TEST-2"},
+			{repoC, []string{"issue", "current"}, "Current issue: TEST-3"},
+			{repoA, []string{"issue", "current", "--global"}, "Current issue: TEST-3"},
+		} {
+			output, err := runCLIIn(tc.dir, tc.args...)
+			if err != nil {
+				t.Fatalf("%v failed: %v\n%s", tc.args, err, output)
+			}
+			if !strings.Contains(output, tc.want) {
+				t.Errorf("%v in %s: expected %q, got: %s", tc.args, tc.dir, tc.want, output)
+			}
+		}
+	})
+
+	// Test switching back to the previous issue from history
+	t.Run("issue switch and recent", func(t *testing.T) {
+		repo := initRepo(t, "main")
+
+		for _, key := range []string{"TEST-5", "TEST-6"} {
+			if output, err := runCLIIn(repo, "issue", "select", key); err != nil {
+				t.Fatalf("issue select %s failed: %v\n%s", key, err, output)
+			}
+		}
+
+		for _, want := range []string{"Switched to: TEST-5", "Switched to: TEST-6"} {
+			output, err := runCLIIn(repo, "issue", "switch", "-")
+			if err != nil {
+				t.Fatalf("issue switch - failed: %v\n%s", err, output)
+			}
+			if !strings.Contains(output, want) {
+				t.Errorf("expected %q, got: %s", want, output)
+			}
+		}
+
+		output, err := runCLIIn(repo, "issue", "recent", "--list")
+		if err != nil {
+			t.Fatalf("issue recent --list failed: %v\n%s", err, output)
+		}
+		lines := strings.Split(strings.TrimSpace(output), "\n")
+		if len(lines) < 2 || !strings.HasPrefix(lines[0], "TEST-6\t") || !strings.HasPrefix(lines[1], "TEST-5\t") {
+			t.Errorf("unexpected recent issues: %s", output)
+		}
+	})
+
+	// Test repository-local overrides
+	t.Run("local config", func(t *testing.T) {
+		repo := initRepo(t, "main")
+		localFile := filepath.Join(repo, ".jcli.yaml")
+		local := "defaults:\n  project: LOCAL\ngit:\n  branch_template: \"feature/{key}-{summary}\"\n"
+		if err := os.WriteFile(localFile, []byte(local), 0600); err != nil {
+			t.Fatalf("failed to write local config: %v", err)
+		}
+
+		output, err := runCLIIn(repo, "config", "show", "--origin")
+		if err != nil {
+			t.Fatalf("config show --origin failed: %v\n%s", err, output)
+		}
+		if !strings.Contains(output, "Project: LOCAL  ["+localFile+"]") {
+			t.Errorf("expected project from local file, got: %s", output)
+		}
+		if !strings.Contains(output, "URL: "+server.URL+"  ["+configFile+"]") {
+			t.Errorf("expected URL from user config, got: %s", output)
+		}
+
+		if output, err := runCLIIn(repo, "issue", "select", "TEST-3"); err != nil {
+			t.Fatalf("issue select failed: %v\n%s", err, output)
+		}
+		output, err = runCLIIn(repo, "issue", "branch")
+		if err != nil {
+			t.Fatalf("issue branch failed: %v\n%s", err, output)
+		}
+		if strings.TrimSpace(output) != "feature/TEST-3-test-issue-test-3" {
+			t.Errorf("expected branch from local template, got: %s", output)
+		}
+
+		if err := os.WriteFile(localFile, []byte("jira:\n  api_token: leaked\n"), 0600); err != nil {
+			t.Fatalf("failed to write local config: %v", err)
+		}
+		if output, err := runCLIIn(repo, "config", "show"); err == nil {
+			t.Errorf("expected secrets in .jcli.yaml to be rejected, got: %s", output)
+		}
+	})
+
+	// Test token lookup through a credential helper
+	t.Run("credential helper", func(t *testing.T) {
+		helperDir := t.TempDir()
+		helperLog := filepath.Join(helperDir, "helper.log")
+		helper := filepath.Join(helperDir, "helper.sh")
+		script := "#!/bin/sh\n{ echo \"action=$1\"; cat; } >> " + helperLog + "\necho password=helper-token\n"
+		if err := os.WriteFile(helper, []byte(script), 0700); err != nil {
+			t.Fatalf("failed to write helper: %v", err)
+		}
+
+		helperCfg := map[string]interface{}{
+			"jira": map[string]string{
+				"url":               server.URL,
+				"email":             "test@example.com",
+				"credential_helper": helper,
+			},
+			"defaults": map[string]string{"project": "TEST"},
+		}
+		data, _ := yaml.Marshal(helperCfg)
+		if err := os.WriteFile(configFile, data, 0600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		defer func() {
+			data, _ := yaml.Marshal(cfg)
+			os.WriteFile(configFile, data, 0600)
+		}()
+
+		output, err := runCLI("issue", "select", "TEST-4")
+		if err != nil {
+			t.Fatalf("issue select with credential helper failed: %v\n%s", err, output)
+		}
+
+		logged, _ := os.ReadFile(helperLog)
+		if !strings.Contains(string(logged), "action=get\nprotocol=http\n") ||
+			!strings.Contains(string(logged), "username=test@example.com\n") {
+			t.Errorf("unexpected helper requests:\n%s", logged)
+		}
+	})
+
+	// Test a Jira site with a private CA
+	t.Run("custom CA bundle", func(t *testing.T) {
+		tlsServer := httptest.NewTLSServer(jiraHandler)
+		defer tlsServer.Close()
+
+		bundle := filepath.Join(t.TempDir(), "ca.pem")
+		block := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw})
+		if err := os.WriteFile(bundle, block, 0600); err != nil {
+			t.Fatalf("failed to write CA bundle: %v", err)
+		}
+
+		tlsCfg := map[string]interface{}{
+			"jira": map[string]interface{}{
+				"url":       tlsServer.URL,
+				"email":     "test@example.com",
+				"api_token": "test-token",
+			},
+			"defaults": map[string]string{"project": "TEST"},
+		}
+		data, _ := yaml.Marshal(tlsCfg)
+		if err := os.WriteFile(configFile, data, 0600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		defer func() {
+			data, _ := yaml.Marshal(cfg)
+			os.WriteFile(configFile, data, 0600)
+		}()
+
+		if output, err := runCLI("issue", "select", "TEST-3"); err == nil {
+			t.Fatalf("expected untrusted certificate to fail, got: %s", output)
+		}
+
+		if output, err := runCLI("config", "set", "jira.tls.ca_files", bundle); err != nil {
+			t.Fatalf("config set failed: %v\n%s", err, output)
+		}
+		output, err := runCLI("issue", "select", "TEST-3")
+		if err != nil {
+			t.Fatalf("issue select with CA bundle failed: %v\n%s", err, output)
+		}
+
+		output, _ = runCLI("doctor")
+		if !strings.Contains(output, "[PASS] TLS:") {
+			t.Errorf("expected TLS check to pass with CA bundle, got: %s", output)
+		}
+	})
+
+	// Test diagnostics
+	t.Run("doctor", func(t *testing.T) {
+		output, err := runCLI("doctor")
+		if err != nil {
+			t.Fatalf("doctor failed: %v\n%s", err, output)
+		}
+		for _, want := range []string{"[PASS] Credentials: authenticated as Test User", "[PASS] Status:", "[WARN] URL:"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("expected %q in output, got: %s", want, output)
+			}
+		}
+
+		output, err = runCLIEnv("", []string{"JIRA_PROJECT=NOPE"}, "doctor")
+		if err == nil {
+			t.Fatalf("expected doctor to fail for unknown project, got: %s", output)
+		}
+		if !strings.Contains(output, "[FAIL] Project") || !strings.Contains(output, "fix:") {
+			t.Errorf("expected project failure with fix, got: %s", output)
+		}
+
+		broken := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(broken, []byte("jira: [unclosed\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		for _, args := range [][]string{
+			{"--config", broken, "doctor"},
+			{"--profile", "missing", "doctor"},
+		} {
+			output, err := runCLI(args...)
+			if err == nil {
+				t.Fatalf("%v: expected doctor to fail, got: %s", args, output)
+			}
+			if !strings.Contains(output, "[FAIL] Config:") || !strings.Contains(output, "fix:") || !strings.Contains(output, "[PASS] State directory") {
+				t.Errorf("%v: expected a failed config check with fix, got: %s", args, output)
+			}
+		}
+	})
+
+	t.Run("debug trace", func(t *testing.T) {
+		output, err := runCLIEnv("", []string{"JCLI_DEBUG=body"}, "issue", "select", "TEST-1")
+		if err != nil {
+			t.Fatalf("issue select failed: %v\n%s", err, output)
+		}
+		for _, want := range []string{"--> GET " + server.URL + "/rest/api/3/issue/TEST-1", "Authorization: Basic [REDACTED]", "<-- 200 OK"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("expected %q in trace, got: %s", want, output)
+			}
+		}
+		if strings.Contains(output, "test-token") || strings.Contains(output, base64.StdEncoding.EncodeToString([]byte("test@example.com:test-token"))) {
+			t.Errorf("trace leaks the API token: %s", output)
+		}
+
+		logFile := filepath.Join(t.TempDir(), "trace.log")
+		output, err = runCLI("--debug", "--debug-file", logFile, "issue", "select", "TEST-1")
+		if err != nil {
+			t.Fatalf("issue select failed: %v\n%s", err, output)
+		}
+		if strings.Contains(output, "-->") {
+			t.Errorf("expected trace only in the log file, got: %s", output)
+		}
+		data, err := os.ReadFile(logFile)
+		if err != nil || !strings.Contains(string(data), "--> GET") {
+			t.Errorf("expected trace in log file, got %q (%v)", data, err)
+		}
+	})
+
+	// Test Jira Server / Data Center with a Personal Access Token
+	t.Run("server deployment", func(t *testing.T) {
+		serverCfg := map[string]interface{}{
+			"jira": map[string]string{
+				"url":        server.URL,
+				"deployment": "server",
+				"api_token":  "server-pat",
+			},
+			"defaults": map[string]string{"project": "TEST"},
+		}
+		data, _ := yaml.Marshal(serverCfg)
+		if err := os.WriteFile(configFile, data, 0600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		defer func() {
+			data, _ := yaml.Marshal(cfg)
+			os.WriteFile(configFile, data, 0600)
+		}()
+
+		output, err := runCLI("issue", "select", "TEST-8")
+		if err != nil {
+			t.Fatalf("issue select on server deployment failed: %v\n%s", err, output)
+		}
+		if !strings.Contains(output, "TEST-8") {
+			t.Errorf("expected TEST-8 in output, got: %s", output)
+		}
+	})
+
+	// Test OAuth login against a fake authorization server
+	t.Run("oauth login", func(t *testing.T) {
+		auth := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/authorize":
+				q := r.URL.Query()
+				http.Redirect(w, r, q.Get("redirect_uri")+"?code=c1&state="+url.QueryEscape(q.Get("state")), http.StatusFound)
+			case "/oauth/token":
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"access_token": "oauth-access", "refresh_token": "oauth-refresh", "expires_in": 3600,
+				})
+			case "/oauth/token/accessible-resources":
+				json.NewEncoder(w).Encode([]map[string]string{{"id": "c1", "url": "https://oauth.atlassian.net"}})
+			case "/ex/jira/c1/rest/api/3/issue/TEST-9":
+				if r.Header.Get("Authorization") != "Bearer oauth-access" {
+					http.Error(w, "unauthorized", http.StatusUnauthorized)
+					return
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"key": "TEST-9", "fields": map[string]string{"summary": "OAuth issue"},
+				})
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		defer auth.Close()
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to find a free port: %v", err)
+		}
+		port := listener.Addr().(*net.TCPAddr).Port
+		listener.Close()
+
+		oauthCfg := map[string]interface{}{
+			"jira": map[string]interface{}{
+				"url": "https://oauth.atlassian.net",
+				"oauth": map[string]interface{}{
+					"client_id":     "client",
+					"callback_port": port,
+					"auth_url":      auth.URL,
+					"api_url":       auth.URL,
+				},
+			},
+			"defaults": map[string]string{"project": "TEST"},
+		}
+		data, _ := yaml.Marshal(oauthCfg)
+		if err := os.WriteFile(configFile, data, 0600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		defer func() {
+			data, _ := yaml.Marshal(cfg)
+			os.WriteFile(configFile, data, 0600)
+		}()
+
+		// The "browser" records the URL; the test follows it like a user would.
+		urlFile := filepath.Join(t.TempDir(), "url")
+		browserScript := filepath.Join(t.TempDir(), "browser.sh")
+		script := "#!/bin/sh\necho \"$1\" > " + urlFile + ".tmp && mv " + urlFile + ".tmp " + urlFile + "\n"
+		if err := os.WriteFile(browserScript, []byte(script), 0700); err != nil {
+			t.Fatalf("failed to write browser script: %v", err)
+		}
+		go func() {
+			for i := 0; i < 100; i++ {
+				if data, err := os.ReadFile(urlFile); err == nil {
+					if resp, err := http.Get(strings.TrimSpace(string(data))); err == nil {
+						resp.Body.Close()
+					}
+					return
+				}
+				time.Sleep(50 * time.Millisecond)
+			}
+		}()
+
+		output, err := runCLIEnv("", []string{"BROWSER=" + browserScript}, "auth", "login")
+		if err != nil {
+			t.Fatalf("auth login failed: %v\n%s", err, output)
+		}
+		if !strings.Contains(output, "Logged in to https://oauth.atlassian.net") {
+			t.Errorf("unexpected login output: %s", output)
+		}
+
+		output, err = runCLI("issue", "select", "TEST-9")
+		if err != nil {
+			t.Fatalf("issue select with OAuth failed: %v\n%s", err, output)
+		}
+		if !strings.Contains(output, "OAuth issue") {
+			t.Errorf("expected issue fetched with OAuth, got: %s", output)
+		}
+
+		output, err = runCLI("auth", "logout")
+		if err != nil || !strings.Contains(output, "Removed OAuth tokens") {
+			t.Errorf("auth logout failed: %v\n%s", err, output)
+		}
+	})
+
+	// Test moving plaintext tokens into the encrypted store
+	t.Run("encrypt credentials", func(t *testing.T) {
+		defer func() {
+			data, _ := yaml.Marshal(cfg)
+			os.WriteFile(configFile, data, 0600)
+		}()
+		passphrase := []string{"JCLI_PASSPHRASE=integration secret"}
+
+		output, err := runCLIEnv("", passphrase, "config", "encrypt-credentials")
+		if err != nil {
+			t.Fatalf("config encrypt-credentials failed: %v\n%s", err, output)
+		}
+		if !strings.Contains(output, "Encrypted API tokens of profiles: default") {
+			t.Errorf("unexpected output: %s", output)
+		}
+
+		data, _ := os.ReadFile(configFile)
+		if strings.Contains(string(data), "test-token") || !strings.Contains(string(data), "token_store: encrypted") {
+			t.Errorf("expected token to leave config.yaml:\n%s", data)
+		}
+		encrypted, err := os.ReadFile(filepath.Join(configDir, "jcli", "credentials.enc"))
+		if err != nil || strings.Contains(string(encrypted), "test-token") {
+			t.Errorf("expected encrypted credentials file: %v\n%s", err, encrypted)
+		}
+
+		if output, err := runCLIEnv("", passphrase, "issue", "select", "TEST-8"); err != nil {
+			t.Fatalf("issue select with encrypted token failed: %v\n%s", err, output)
+		}
+		wrong := []string{"JCLI_PASSPHRASE=wrong"}
+		if output, err := runCLIEnv("", wrong, "issue", "select", "TEST-8"); err == nil {
+			t.Errorf("expected wrong passphrase to fail, got: %s", output)
+		}
+	})
+
+	// Test profiles with separate state
+	t.Run("profiles", func(t *testing.T) {
+		cfg["profiles"] = map[string]interface{}{
+			"client": map[string]interface{}{
+				"jira": map[string]string{
+					"url":       server.URL,
+					"email":     "client@example.com",
+					"api_token": "client-token",
+				},
+				"defaults": map[string]string{"project": "CLI"},
+			},
+		}
+		data, _ := yaml.Marshal(cfg)
+		if err := os.WriteFile(configFile, data, 0600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		repo := initRepo(t, "main")
+
+		if output, err := runCLIIn(repo, "--profile", "client", "issue", "select", "TEST-9"); err != nil {
+			t.Fatalf("issue select with profile failed: %v\n%s", err, output)
+		}
+
+		output, err := runCLIIn(repo, "issue", "current", "--profile=client")
+		if err != nil || !strings.Contains(output, "Current issue: TEST-9") {
+			t.Errorf("expected TEST-9 in client profile, got: %v\n%s", err, output)
+		}
+
+		output, _ = runCLIIn(repo, "issue", "current")
+		if strings.Contains(output, "TEST-9") {
+			t.Errorf("expected default profile state to be separate, got: %s", output)
+		}
+
+		output, err = runCLI("config", "profile", "list")
+		if err != nil || !strings.Contains(output, "* default") || !strings.Contains(output, "  client") {
+			t.Errorf("unexpected profile list: %v\n%s", err, output)
+		}
+
+		if output, err := runCLI("config", "profile", "use", "client"); err != nil {
+			t.Fatalf("config profile use failed: %v\n%s", err, output)
+		}
+		output, err = runCLIIn(repo, "issue", "current")
+		if err != nil || !strings.Contains(output, "Current issue: TEST-9") {
+			t.Errorf("expected persisted client profile, got: %v\n%s", err, output)
+		}
+
+		if output, err := runCLI("config", "profile", "use", "default"); err != nil {
+			t.Fatalf("config profile use default failed: %v\n%s", err, output)
+		}
+	})
+
 	// Test issue help
 	t.Run("issue help", func(t *testing.T) {
 		output, err := runCLI("issue", "help")
 		if err != nil {
 			t.Fatalf("issue help failed: %v", err)
 		}
-		if !strings.Contains(output, "jcli issue - Manage Jira issues") {
-			t.Errorf("unexpected output: %s", output)
+		for _, want := range []string{"jcli issue [command]", "select", "Global Flags:", "--project"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("expected %q in issue help, got: %s", want, output)
+			}
+		}
+
+		output, err = runCLI("issue", "select", "--help")
+		if err != nil {
+			t.Fatalf("issue select --help failed: %v", err)
+		}
+		if !strings.Contains(output, "--query string") {
+			t.Errorf("expected --query in select help, got: %s", output)
+		}
+
+		if output, err := runCLI("issue", "bogus"); err == nil || !strings.Contains(output, "unknown issue command: bogus") {
+			t.Errorf("expected unknown command error, got: %v\n%s", err, output)
 		}
 	})
 }